@@ -0,0 +1,74 @@
+package cachebusting
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+//CreatedFile is a cache busting copy, or vendor file, created by Create(). See AfterCreate.
+type CreatedFile struct {
+	//LocalPath is the path to the original file.
+	LocalPath string
+
+	//URLPath is the URL path of the original file.
+	URLPath string
+
+	//CacheBustLocalPath is the path to the cache busting copy on disk. This is blank if the
+	//copy is stored in memory.
+	CacheBustLocalPath string
+
+	//CacheBustURLPath is the URL path the cache busting copy is served on.
+	CacheBustURLPath string
+
+	//Hash is the full hash of the copy's contents.
+	Hash string
+
+	//Size is the size of the copy in bytes.
+	Size int64
+
+	//InMemory is true if the copy is stored in memory rather than on disk.
+	InMemory bool
+
+	//Vendor is true if the file is a vendor file, served as-is. See StaticFile.Vendor.
+	Vendor bool
+}
+
+//createdFiles returns the details of each file created by Create().
+//
+//fileData is the data of each static file, in the same order as StaticFiles.
+func (c *Config) createdFiles(fileData [][]byte) (files []CreatedFile) {
+	files = make([]CreatedFile, 0, len(c.StaticFiles))
+	for k, s := range c.StaticFiles {
+		f := CreatedFile{
+			LocalPath:        s.LocalPath,
+			URLPath:          s.URLPath,
+			CacheBustURLPath: s.cacheBustURLPath,
+			Hash:             s.hash,
+			Size:             int64(len(fileData[k])),
+			InMemory:         c.inMemory(s),
+			Vendor:           s.Vendor,
+		}
+		if !f.InMemory {
+			f.CacheBustLocalPath = s.cacheBustLocalPath
+		}
+
+		//streamed files weren't read into memory, nor were files whose existing copy was
+		//reused, see MappingFile.
+		if s.streamed {
+			info, err := fs.Stat(c.sourceFS(), filepath.ToSlash(s.LocalPath))
+			if err == nil {
+				f.Size = info.Size()
+			}
+		} else if fileData[k] == nil && f.CacheBustLocalPath != "" {
+			info, err := os.Stat(f.CacheBustLocalPath)
+			if err == nil {
+				f.Size = info.Size()
+			}
+		}
+
+		files = append(files, f)
+	}
+
+	return
+}
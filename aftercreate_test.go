@@ -0,0 +1,66 @@
+package cachebusting
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAfterCreate(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//AfterCreate is called with the details of each file created.
+	var created []CreatedFile
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.AfterCreate = func(files []CreatedFile) error {
+		created = files
+		return nil
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	s := c.StaticFiles[0]
+	if len(created) != 1 {
+		t.Fatal("AfterCreate not called as expected", len(created))
+		return
+	}
+	f := created[0]
+	if f.LocalPath != p || f.CacheBustLocalPath != s.cacheBustLocalPath || f.CacheBustURLPath != s.cacheBustURLPath || f.Hash != s.hash || f.Size != 15 || f.InMemory {
+		t.Fatal("Created file not as expected", f)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An error from AfterCreate fails Create().
+	errHook := errors.New("hook failed")
+	err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	c.AfterCreate = func(files []CreatedFile) error {
+		return errHook
+	}
+	err = c.Create()
+	if !errors.Is(err, errHook) {
+		t.Fatal("AfterCreate error should have been returned", err)
+		return
+	}
+	if c.StaticFiles[0].cacheBustURLPath != s.cacheBustURLPath {
+		t.Fatal("Static files should have been restored")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,38 @@
+package cachebusting
+
+import (
+	"path"
+)
+
+//aliasCacheBustURLPaths returns the URL path the cache busting copy of a static file is
+//served on for each of the file's aliases, see Aliases. The copy is served with the same
+//name, in the alias's directory. Files that aren't cache busted, i.e. vendor files, are
+//served on each alias as-is. Nothing is returned if Create() hasn't been called.
+func aliasCacheBustURLPaths(s StaticFile) (paths []string) {
+	if s.cacheBustURLPath == "" || len(s.Aliases) == 0 {
+		return
+	}
+
+	paths = make([]string, 0, len(s.Aliases))
+	for _, a := range s.Aliases {
+		if s.cacheBustURLPath == s.URLPath {
+			paths = append(paths, a)
+			continue
+		}
+
+		paths = append(paths, path.Join(path.Dir(a), path.Base(s.cacheBustURLPath)))
+	}
+
+	return
+}
+
+//aliasURLs returns the URL of the cache busting copy of a static file for each of the
+//file's aliases, keyed by alias. See urlFor.
+func (c *Config) aliasURLs(s StaticFile) map[string]string {
+	urls := make(map[string]string, len(s.Aliases))
+	for i, p := range aliasCacheBustURLPaths(s) {
+		urls[s.Aliases[i]] = c.assetHost(s) + c.publicURLPath(p) + c.versionQuery(s)
+	}
+
+	return urls
+}
@@ -0,0 +1,74 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAliases(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/css/app.min.css":  {Data: []byte("body{}")},
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The copy is served on the alias from the same data in memory.
+	aliases := []string{"assets/app.min.css"}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k := range c.StaticFiles {
+		if c.StaticFiles[k].URLPath == "/static/css/app.min.css" {
+			c.StaticFiles[k].Aliases = aliases
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if aliases[0] != "assets/app.min.css" {
+		t.Fatal("Caller's aliases should not have been modified", aliases)
+		return
+	}
+
+	s, _ := c.findByOriginalName("app.min.css")
+	u := "/assets/" + path.Base(s.cacheBustURLPath)
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "body{}" || rec.Header().Get("X-Static-Served-From") != "memory" {
+		t.Fatal("File not served on alias", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The manifest includes the alias.
+	rec = httptest.NewRecorder()
+	c.ManifestHandler("").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/manifest.json", nil))
+	var pairs map[string]string
+	err = json.Unmarshal(rec.Body.Bytes(), &pairs)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if pairs["/assets/app.min.css"] != u || pairs["/static/css/app.min.css"] != s.cacheBustURLPath {
+		t.Fatal("Manifest not as expected", pairs)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An alias cannot be the URL path of a different file.
+	c = NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[0].Aliases = []string{c.StaticFiles[1].URLPath}
+	err = c.Create()
+	if !errors.Is(err, ErrDuplicateURLPath) {
+		t.Fatal("ErrDuplicateURLPath should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,128 @@
+package cachebusting
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//Finding is a reference in a template to a static file that is not in the list of static
+//files. See AuditTemplates().
+type Finding struct {
+	//File is the path to the template the reference was found in.
+	File string
+
+	//Line is the line number, starting at 1, the reference was found on.
+	Line int
+
+	//Reference is the URL path, i.e.: /static/js/script.min.js, or the original file's
+	//name passed to a template func, i.e.: script.min.js, as written in the template.
+	Reference string
+}
+
+//auditFuncRegex matches the template funcs that are given an original file's name, and
+//the quoted name passed to the func. See FuncMap().
+var auditFuncRegex = regexp.MustCompile(`\b(?:cacheBustURL|assetURL|scriptTag|styleTag|pictureTag|srcset|spriteURL|modulePreloadTags)\s+(?:"([^"]*)"|` + "`([^`]*)`)")
+
+//AuditTemplates finds references in the templates in dir to static files that are not in
+//the list of static files. This is meant to be run in CI, or a test, to catch a static
+//file that was added to a template but not added to the config, which would otherwise
+//be served without cache busting or not at all.
+//
+//Two kinds of references are checked:
+// - URL paths starting with the URL path static files are served under, i.e.: /static/,
+//   such as in src or href attributes. See TemplateData.URLPrefix.
+// - Original file names passed to template funcs, i.e.: {{assetURL "script.min.js"}}.
+//
+//As with ScanTemplates(), references built dynamically in a template cannot be found.
+//Findings are sorted by file and line. A nil slice is returned if there are none.
+func (c *Config) AuditTemplates(dir string) (findings []Finding, err error) {
+	known := make(map[string]bool, len(c.StaticFiles))
+	names := make(map[string]bool, len(c.StaticFiles))
+	for _, s := range c.StaticFiles {
+		known[decodeURLPath(s.URLPath)] = true
+		for _, a := range s.Aliases {
+			known[decodeURLPath(a)] = true
+		}
+		if s.cacheBustURLPath != "" {
+			known[s.cacheBustURLPath] = true
+		}
+
+		names[filepath.Base(s.LocalPath)] = true
+	}
+
+	//with a prefix of "/", only references with an extension are treated as static files
+	//so that links to pages, i.e.: /about, aren't flagged.
+	var prefix string
+	var refRegex *regexp.Regexp
+	if c.urlPrefix() != "" {
+		prefix = templateRefPrefix(c.urlPrefix())
+		refRegex = templateRefRegex(prefix)
+	}
+
+	err = walkTemplates(dir, func(p, content string) error {
+		line := func(offset int) int {
+			return strings.Count(content[:offset], "\n") + 1
+		}
+
+		if refRegex != nil {
+			for _, loc := range refRegex.FindAllStringIndex(content, -1) {
+				ref := content[loc[0]:loc[1]]
+
+				//skip directories, references built dynamically, and, when static files
+				//are served at the root, pages.
+				if strings.HasSuffix(ref, "/") || (prefix == "/" && path.Ext(ref) == "") {
+					continue
+				}
+				if known[decodeURLPath(path.Clean(ref))] {
+					continue
+				}
+
+				findings = append(findings, Finding{File: p, Line: line(loc[0]), Reference: ref})
+			}
+		}
+
+		for _, m := range auditFuncRegex.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[2], m[3]
+			if start < 0 {
+				start, end = m[4], m[5]
+			}
+			ref := content[start:end]
+
+			//spriteURL is given the name with the icon's fragment.
+			name := ref
+			if i := strings.Index(ref, "#"); i >= 0 {
+				name = ref[:i]
+			}
+			if names[name] {
+				continue
+			}
+
+			findings = append(findings, Finding{File: p, Line: line(m[0]), Reference: ref})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	return
+}
+
+//AuditTemplates finds references to unknown static files for the package level config.
+func AuditTemplates(dir string) ([]Finding, error) {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return config.AuditTemplates(dir)
+}
@@ -0,0 +1,92 @@
+package cachebusting
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAuditTemplates(t *testing.T) {
+	dir := t.TempDir()
+	templateDir := filepath.Join(dir, "templates")
+	err := os.MkdirAll(filepath.Join(templateDir, "partials"), 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	index := `<link rel="stylesheet" href="/static/css/styles.min.css">
+<script src="/static/js/script.min.js?v=1"></script>
+<img src="/static/img/missing.png">
+<img src="/static/img/{{.Name}}">
+<a href="/about">About</a>`
+	partial := `{{assetURL "script.min.js"}}
+{{scriptTag "forgotten.js"}}
+{{spriteURL "icons.svg#home"}}`
+	err = os.WriteFile(filepath.Join(templateDir, "index.html"), []byte(index), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(templateDir, "partials", "footer.tmpl"), []byte(partial), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(templateDir, "notes.txt"), []byte("/static/img/ignored.png"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join("_testdata", "static", "css", "styles.min.css"), "/static/css/styles.min.css")
+	js := NewStaticFile(filepath.Join("_testdata", "static", "js", "script.min.js"), "/static/js/script.min.js")
+	c := NewOnDiskConfig(css, js)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//References to unknown files are found, in order, with their line numbers.
+	findings, err := c.AuditTemplates(templateDir)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	expected := []Finding{
+		{File: filepath.Join(templateDir, "index.html"), Line: 3, Reference: "/static/img/missing.png"},
+		{File: filepath.Join(templateDir, "partials", "footer.tmpl"), Line: 2, Reference: "forgotten.js"},
+		{File: filepath.Join(templateDir, "partials", "footer.tmpl"), Line: 3, Reference: "icons.svg#home"},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Fatal("Findings not as expected", findings)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nothing is found when each file is known.
+	png := NewStaticFile(filepath.Join("_testdata", "static", "img", "missing.png"), "/static/img/missing.png")
+	forgotten := NewStaticFile(filepath.Join("_testdata", "static", "js", "forgotten.js"), "/static/js/forgotten.js")
+	icons := NewStaticFile(filepath.Join("_testdata", "static", "img", "icons.svg"), "/static/img/icons.svg")
+	c = NewOnDiskConfig(css, js, png, forgotten, icons)
+
+	findings, err = c.AuditTemplates(templateDir)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if findings != nil {
+		t.Fatal("No findings expected", findings)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A missing directory returns an error.
+	_, err = c.AuditTemplates(filepath.Join(dir, "missing"))
+	if err == nil {
+		t.Fatal("Error about missing directory should have occured")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,51 @@
+package cachebusting
+
+import (
+	"context"
+	"path"
+)
+
+//AzureBlobClient is the subset of an Azure Blob Storage client used by AzureBlobStore.
+//This allows you to use the Azure SDK without this package depending on it. For example,
+//with github.com/Azure/azure-sdk-for-go/sdk/storage/azblob:
+//
+//	type azureClient struct{ c *azblob.Client }
+//
+//	func (a azureClient) UploadBlob(ctx context.Context, container, name string, data []byte, contentType, cacheControl string) error {
+//		_, err := a.c.UploadBuffer(ctx, container, name, data, &azblob.UploadBufferOptions{
+//			HTTPHeaders: &blob.HTTPHeaders{
+//				BlobContentType:  &contentType,
+//				BlobCacheControl: &cacheControl,
+//			},
+//		})
+//		return err
+//	}
+type AzureBlobClient interface {
+	//UploadBlob saves data as a block blob in the container, setting the blob's
+	//Content-Type and Cache-Control properties.
+	UploadBlob(ctx context.Context, container, name string, data []byte, contentType, cacheControl string) error
+}
+
+//AzureBlobStore is an AssetStore that publishes cache busting copies to an Azure Blob
+//Storage container, for example one used as the origin of Azure CDN.
+type AzureBlobStore struct {
+	//Client is the Azure Blob Storage client used to upload each blob.
+	Client AzureBlobClient
+
+	//Container is the name of the container blobs are uploaded to.
+	Container string
+
+	//Prefix is an optional path prepended to the name of each blob, i.e. "assets" results
+	//in blobs named assets/static/js/A1B2C3D4.script.min.js.
+	Prefix string
+}
+
+//Put uploads an asset to the container.
+func (a AzureBlobStore) Put(ctx context.Context, asset Asset) error {
+	name := asset.Key
+	if a.Prefix != "" {
+		name = path.Join(a.Prefix, name)
+	}
+
+	return a.Client.UploadBlob(ctx, a.Container, name, asset.Data, asset.ContentType, asset.CacheControl)
+}
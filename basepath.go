@@ -0,0 +1,56 @@
+package cachebusting
+
+import (
+	"path"
+	"strings"
+)
+
+//basePath returns the cleaned BasePath, i.e.: /myapp, or a blank string if the app is
+//served at the root of the host.
+func (c *Config) basePath() string {
+	if c.BasePath == "" {
+		return ""
+	}
+
+	p := path.Clean(path.Join("/", c.BasePath))
+	if p == "/" {
+		return ""
+	}
+
+	return p
+}
+
+//publicURLPath returns the URL path used in URLs given to the browser for a static file's
+//URL path, percent-encoded and with the BasePath added.
+func (c *Config) publicURLPath(p string) string {
+	return c.basePath() + escapeURLPath(p)
+}
+
+//trimBasePath removes the BasePath from a requested URL path so that the path matches the
+//URL paths of your static files. Paths without the BasePath are returned as-is, i.e. when
+//a router has already removed it.
+func (c *Config) trimBasePath(p string) string {
+	base := c.basePath()
+	if base == "" {
+		return p
+	}
+
+	if p == base {
+		return "/"
+	} else if strings.HasPrefix(p, base+"/") {
+		return p[len(base):]
+	}
+
+	return p
+}
+
+//templateURLPrefix returns the URL path static files are served under, including the
+//BasePath, for use in templates. See TemplateData.URLPrefix.
+func (c *Config) templateURLPrefix() string {
+	prefix := c.urlPrefix()
+	if prefix == "" || c.basePath() == "" {
+		return prefix
+	}
+
+	return path.Join(c.basePath(), prefix)
+}
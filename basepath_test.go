@@ -0,0 +1,79 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBasePath(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/css/styles.min.css": {Data: []byte(`body{background:url("/myapp/static/img/bg.png")}`)},
+		"static/img/bg.png":         {Data: []byte("png")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.BasePath = "/myapp/"
+	c.RewriteCSSURLs = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	css, _ := c.findByOriginalName("styles.min.css")
+	bg, _ := c.findByOriginalName("bg.png")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//URLs include the base path, the URL paths of files do not.
+	if c.AssetURL("bg.png") != "/myapp"+bg.cacheBustURLPath {
+		t.Fatal("Base path not added to URL", c.AssetURL("bg.png"))
+		return
+	}
+	if strings.HasPrefix(bg.cacheBustURLPath, "/myapp") {
+		t.Fatal("Base path should not be added to cache busting URL path", bg.cacheBustURLPath)
+		return
+	}
+	if c.TemplateData().URLPrefix != "/myapp/static" {
+		t.Fatal("Base path not added to URL prefix", c.TemplateData().URLPrefix)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//URLs in CSS files written with the base path are rewritten.
+	if !strings.Contains(string(css.fileData), `url("/myapp`+bg.cacheBustURLPath+`")`) {
+		t.Fatal("CSS URL not rewritten", string(css.fileData))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files are served with or without the base path in the request.
+	counters := &ServeCounters{}
+	h := c.Handler(HandlerOptions{CacheDays: 1, Counters: counters})
+	for _, p := range []string{"/myapp" + bg.cacheBustURLPath, bg.cacheBustURLPath} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "png" {
+			t.Fatal("File not served", p, rec.Code)
+			return
+		}
+	}
+	if atomic.LoadInt64(&counters.Hashed) != 2 {
+		t.Fatal("Requests not counted as hashed", atomic.LoadInt64(&counters.Hashed))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A base path of "/" is the same as no base path.
+	c.BasePath = "/"
+	if c.AssetURL("bg.png") != bg.cacheBustURLPath {
+		t.Fatal("URL should not have a base path", c.AssetURL("bg.png"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,63 @@
+package cachebusting
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//defaultBuildInfoExtensions is the list of extensions BuildInfo is appended to if
+//BuildInfoExtensions is not provided.
+var defaultBuildInfoExtensions = []string{".js", ".mjs", ".css"}
+
+//commentSyntax maps file extensions to the start and end of a comment for the file type.
+var commentSyntax = map[string][2]string{
+	".js":   {"/* ", " */"},
+	".mjs":  {"/* ", " */"},
+	".cjs":  {"/* ", " */"},
+	".css":  {"/* ", " */"},
+	".html": {"<!-- ", " -->"},
+	".htm":  {"<!-- ", " -->"},
+	".svg":  {"<!-- ", " -->"},
+}
+
+//appendBuildInfo returns the file's data with the BuildInfo comment appended, if the
+//file's extension is one BuildInfo should be appended to. Vendor files are never modified
+//since they are served as-is.
+func (c *Config) appendBuildInfo(s StaticFile, data []byte) []byte {
+	if c.BuildInfo == "" || s.Vendor {
+		return data
+	}
+
+	ext := strings.ToLower(path.Ext(filepath.ToSlash(s.LocalPath)))
+	extensions := c.BuildInfoExtensions
+	if len(extensions) == 0 {
+		extensions = defaultBuildInfoExtensions
+	}
+	if !containsString(extensions, ext) {
+		return data
+	}
+
+	syntax, ok := commentSyntax[ext]
+	if !ok {
+		return data
+	}
+
+	//make sure the text can't end the comment early. Removing the end of the comment can
+	//form a new one, i.e. "**//", so this is repeated until none are left.
+	text := path.Base(filepath.ToSlash(s.LocalPath)) + " " + c.BuildInfo
+	text = strings.ReplaceAll(text, "\n", " ")
+	end := strings.TrimSpace(syntax[1])
+	for strings.Contains(text, end) {
+		text = strings.ReplaceAll(text, end, "")
+	}
+
+	out := make([]byte, 0, len(data)+len(text)+len(syntax[0])+len(syntax[1])+2)
+	out = append(out, data...)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	out = append(out, syntax[0]+text+syntax[1]+"\n"...)
+
+	return out
+}
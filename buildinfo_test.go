@@ -0,0 +1,71 @@
+package cachebusting
+
+import (
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuildInfo(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/app.js":    {Data: []byte("console.log(1);")},
+		"static/css/app.css":  {Data: []byte("body{}\n")},
+		"static/img/logo.png": {Data: []byte("png")},
+		"static/js/vendor.js": {Data: []byte("vendor")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.BuildInfo = "build 2024-01-01 commit abc123 */ injected"
+	for k, s := range c.StaticFiles {
+		if s.URLPath == "/static/js/vendor.js" {
+			c.StaticFiles[k].Vendor = true
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Comment is appended to JS and CSS files only and can't be ended early.
+	expected := map[string]string{
+		"/static/js/app.js":    "console.log(1);\n/* app.js build 2024-01-01 commit abc123  injected */\n",
+		"/static/css/app.css":  "body{}\n/* app.css build 2024-01-01 commit abc123  injected */\n",
+		"/static/img/logo.png": "png",
+		"/static/js/vendor.js": "vendor",
+	}
+	for _, s := range c.StaticFiles {
+		if string(s.fileData) != expected[s.URLPath] {
+			t.Fatal("Build info not appended as expected", s.URLPath, string(s.fileData))
+			return
+		}
+
+		err = c.checkCopy(s)
+		if err != nil {
+			t.Fatal("Hash doesn't match contents", s.URLPath, err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Removing the end of the comment can't form a new end of the comment.
+	for name, buildInfo := range map[string]string{
+		"app.js":    "**//",
+		"app.css":   "*/*/**///",
+		"page.html": "--->>",
+		"icon.svg":  "-\n->",
+	} {
+		c.BuildInfo = buildInfo
+		c.BuildInfoExtensions = []string{".js", ".css", ".html", ".svg"}
+		end := strings.TrimSpace(commentSyntax[path.Ext(name)][1])
+
+		out := string(c.appendBuildInfo(StaticFile{LocalPath: name}, nil))
+		if strings.Count(out, end) != 1 || !strings.HasSuffix(out, end+"\n") {
+			t.Fatal("Comment can be ended early", name, out)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -34,7 +34,10 @@ For example:
   </head>
 </html>
 
-The expected local directory format for your static files is as follows:
+Cache busting files are served by looking up each file's location, in memory or on disk,
+so your static files may be stored in any directory structure. However, StaticFileHandler
+serves any other file (i.e. vendor files that are not cache busted) from a directory using the
+request's URL path, so the expected local directory format for your static files is as follows:
 website/
 ├─ static/
 │  ├─ css
@@ -45,26 +48,38 @@ website/
 The expected paths for each file as served from a browser is noted as follows:
 - example.com/static/css/{hash-prefix}.styles.min.css
 - example.com/static/js/{hash-prefix}.script.min.jss
+
+If your files can't be stored in this structure, use Handler() and map each URL path prefix
+to the directory files are stored in with HandlerOptions.Roots.
+
+If you use NewFSConfig(), the directory structure is not fixed. Every file in the directory
+you provide, no matter how deeply nested, is cache busted and served at the same nested path
+under your URL prefix (i.e.: static/js/vendor/chart/chart.min.js is served at
+example.com/static/js/vendor/chart/{hash-prefix}.chart.min.js).
 */
 package cachebusting
 
 import (
+	"context"
 	"crypto/sha256"
 	"embed"
-	"encoding/hex"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
-	"mime"
-	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"text/tabwriter"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 //StaticFile contains the local path to the on disk or embedded original static file
@@ -104,6 +119,89 @@ type StaticFile struct {
 	//Ex.: /static/js/script.min.js
 	URLPath string
 
+	//Aliases are additional URL paths the file is served on, i.e. a legacy path of
+	//"/assets/app.min.css" for a file at /static/css/app.min.css. The one cache busting
+	//copy is served on each alias, using the same cache busting file name in the alias's
+	//directory, without storing another copy in memory or on disk. Template funcs output
+	//the URL for URLPath; ManifestHandler() includes each alias.
+	Aliases []string
+
+	//Inline marks the file as being included inline in your HTML (i.e.: the contents of
+	//the file are placed within a <script> or <style> element) rather than referenced by
+	//URL. The hashes of inline files are returned by CSPHashes() for use in your
+	//Content-Security-Policy header. This should only be used for small files.
+	Inline bool
+
+	//Critical marks the file as needed early in page load (i.e.: fonts or a hero image)
+	//such that the browser should be told to preload the file. See PreloadTags() and
+	//PreloadLinkHeader().
+	Critical bool
+
+	//Storage is where the cache busting copy of this file is stored, overriding the
+	//config's UseMemory field for just this file. For example, small CSS files can be
+	//stored in memory while a large WASM file is stored on disk. This is only applicable
+	//when the original files are stored on disk since copies of files read from an
+	//embedded or other filesystem are always stored in memory.
+	Storage Storage
+
+	//Variants are additional files derived from this file, for example WebP and AVIF
+	//versions of a PNG image. Each variant is generated when Create() is called, cache
+	//busted, and added to StaticFiles. See PictureTag().
+	Variants []Variant
+
+	//Vendor marks the file as already versioned, i.e.: jquery-3.7.1.min.js, such that a
+	//cache busting copy isn't needed. The file is served as-is on its URL path, from
+	//memory or disk per the config and Storage, with the same caching as cache busting
+	//files. This makes vendor files visible in diagnostics and template funcs rather than
+	//relying on requests falling through to the file server.
+	Vendor bool
+
+	//Include is called when Create() is called to decide if the file is used in this
+	//build or environment, i.e. analytics.js only when a flag is set, or a file only for
+	//one architecture using a func defined in files with build tags. Files for which false
+	//is returned are removed from StaticFiles, so one list of static files can describe
+	//every variant of your app. The file is always used if Include is nil.
+	Include func() bool
+
+	//Priority marks the file as needed as soon as possible after your app starts, i.e.
+	//the CSS and JavaScript used on every page. Priority files are moved to the start of
+	//StaticFiles and hashed first. The first time Create() is called, the copies of
+	//priority files stored in memory are served by Handler() while the other files are
+	//still being hashed, reducing the time requests for these files are not found when
+	//there are many static files.
+	Priority bool
+
+	//ManifestPlaceholder is text in this file, i.e. __ASSET_MANIFEST__, that is replaced
+	//with a JSON object mapping the original URL path of each other static file to its
+	//cache busting URL when Create() is called. Use this for the entry file of a
+	//JavaScript bundle so that dynamically imported chunks can be resolved at runtime.
+	//See AddChunks().
+	ManifestPlaceholder string
+
+	//WebAppManifest marks the file as a web app manifest, i.e. manifest.json, such that
+	//the URL of each icon and screenshot listed in the file is replaced with the cache
+	//busting URL of the icon or screenshot when Create() is called. Files with the
+	//.webmanifest extension are always treated as web app manifests. The icons and
+	//screenshots must also be static files.
+	WebAppManifest bool
+
+	//Groups is the list of named groups, i.e. "admin" or "checkout", this file belongs to.
+	//Groups allow each page to reference just the files it needs, see Group() and
+	//GroupTags().
+	Groups []string
+
+	//DependsOn is the list of the original names of the static files that must be loaded
+	//before this file, i.e. app.js depends on jquery.min.js. Elements output for a group,
+	//see GroupTags(), and preload elements, see PreloadTags(), list each file after the
+	//files it depends on.
+	DependsOn []string
+
+	//Imports is the list of the original names of the static files this file imports, for
+	//ES modules. For example, an entry module app.js that imports util.js would list
+	//"util.js". This is used to preload an entry module and its imports, see
+	//ModulePreloadTags().
+	Imports []string
+
 	//cacheBustLocalPath is the full, complete path to the cache busting copy of the
 	//file. This is constructed from the LocalPath and the cache busting file's name
 	//if the cache busting files are not stored in memory.
@@ -121,16 +219,87 @@ type StaticFile struct {
 	//simply a copy of the file at the time creation of the cache busting file is
 	//performed. This is the file's data when it is stored in memory.
 	fileData []byte
+
+	//hash is the full, untruncated, uppercase hex encoded hash of the file's contents.
+	//This is used to deterministically pick an asset host when AssetHosts is set.
+	hash string
+
+	//integrity is the subresource integrity value for the file's contents, in the format
+	//expected by the integrity attribute of <script> and <link> elements (sha256-...).
+	integrity string
+
+	//previousURLPaths is the list of cache busting URL paths used for this file prior to
+	//the current cacheBustURLPath, most recent first. This is used to serve the current
+	//version of a file when an outdated cache busting URL path is requested.
+	previousURLPaths []string
+
+	//variantOf is the URL path of the static file this file was generated from. This is
+	//only set for files generated from a Variant.
+	variantOf string
+
+	//fontOf is the URL path of the CSS file this font file was found in. This is only set
+	//for fonts added by RegisterFonts.
+	fontOf string
+
+	//sourceData is the contents of a file generated from a Variant. This is used instead of
+	//reading the original file since the generated file doesn't exist on disk or in a
+	//filesystem.
+	sourceData []byte
+
+	//streamed is true if the file was hashed by streaming it from the filesystem and is
+	//served directly from the filesystem rather than from a copy in memory. See
+	//StreamMinBytes.
+	streamed bool
+
+	//evicted is true if the data of the file's copy stored in memory is kept in the memory
+	//cache, which may remove the data from memory, rather than in fileData. See
+	//MemoryCacheBytes.
+	evicted bool
+
+	//decodedURLPath and decodedAliases are the URL path and aliases after being decoded,
+	//and cleaned, by Create(). This is used so that a path is only decoded once. See
+	//normalizeURLPaths.
+	decodedURLPath string
+	decodedAliases []string
 }
 
+//Storage is where the cache busting copy of a file is stored.
+type Storage int
+
+//storage locations
+const (
+	//StorageDefault stores the cache busting copy based on the config: in memory if the
+	//original file is embedded, read from a filesystem, or UseMemory is true, otherwise on
+	//disk.
+	StorageDefault Storage = iota
+
+	//StorageMemory stores the cache busting copy in memory.
+	StorageMemory
+
+	//StorageDisk stores the cache busting copy on disk, in the same directory as the
+	//original file.
+	StorageDisk
+)
+
 //Config is the set of configuration settings for cache busting.
 type Config struct {
 	//Development is used to disable cache busting.
 	Development bool
 
+	//DevelopmentPassthrough causes Create() to map each file to itself, rather than
+	//returning ErrNoCacheBustingInDevelopment, when Development is true. The "cache
+	//busting" URL path of each file is the original file's URL path and the original
+	//files are served as-is. This allows code that uses the cache busting data, such as
+	//template funcs, manifests, and the handler, to work unchanged in development.
+	DevelopmentPassthrough bool
+
 	//Debug enables printing out diagnostic information.
 	Debug bool
 
+	//DebugWriter is where the table of cache busting files is written to when Debug is
+	//true. If not provided, os.Stdout is used. Set to io.Discard to silence the table.
+	DebugWriter io.Writer
+
 	//HashLength defines the number of characters prepended to each original file's name
 	//to create the cache busting file's name.
 	HashLength uint
@@ -154,6 +323,244 @@ type Config struct {
 	//stored in memory. This is useful for times when your app is running on a system
 	//that cannot write to disk.
 	UseMemory bool
+
+	//MemoryFallback causes the cache busting copy of a file to be stored in memory, with a
+	//logged warning, if the copy cannot be saved to disk because the filesystem is
+	//read-only or not writable. Without this, ErrReadOnly is returned. This is useful when
+	//the same app is deployed both to systems that can and cannot write to disk.
+	MemoryFallback bool
+
+	//DiskSpaceMargin is the number of bytes that must remain free on disk after the cache
+	//busting copies are saved. The free space is checked before any copy is saved so
+	//that Create() fails with ErrInsufficientDiskSpace rather than partially filling the
+	//disk. The check is skipped on operating systems where the free space cannot be
+	//determined, i.e. Windows.
+	DiskSpaceMargin int64
+
+	//TempDir is the directory each cache busting copy is written to before being moved
+	//to its final location, so that a partially written copy is never served. If not
+	//provided, copies are written in the same directory as the original file. TempDir
+	//must be on the same filesystem as the original files since copies are moved by
+	//renaming. Don't use a small /tmp, i.e. a tmpfs in a container, for large files.
+	TempDir string
+
+	//MappingFile causes a .cachebust.json file to be saved in each directory cache busting
+	//copies are saved to on disk. The file records the cache busting copy, hash, size, and
+	//modification time of each original file in the directory. The next time Create() is
+	//called, i.e. when the app restarts, files whose size and modification time haven't
+	//changed aren't read and hashed again. External tools, such as nginx configuration
+	//generators and deploy scripts, can also read the file. The handler responds to
+	//requests for the file with a 404, but make sure any other server of the directory,
+	//i.e. nginx, doesn't serve the file either.
+	MappingFile bool
+
+	//FS is a filesystem the original files are read from, for example os.DirFS, a zip
+	//archive, or fstest.MapFS. This is an alternative to UseEmbedded and EmbeddedFS for
+	//filesystems other than embed.FS. Since the filesystem may not be writable, the
+	//copies are always stored in memory. Paths to each static file must use a "/"
+	//separator. See NewFSConfig().
+	FS fs.FS
+
+	//AssetHosts is an optional list of hosts, including the scheme, that cache busting
+	//files are served from (for example, https://cdn1.example.com). When provided, each
+	//file is assigned to one of the hosts based on the file's hash so that the same file
+	//is always served from the same host. This is used when building full URLs for
+	//templates and exporting the URL pairs.
+	AssetHosts []string
+
+	//BasePath is the URL path your app is served under when it isn't served at the root
+	//of the host, i.e.: /myapp for example.com/myapp/. The BasePath is added to each URL
+	//built for templates, manifests, and rewritten CSS files, and removed from requests
+	//by Handler() before looking up a file. The URL paths of your static files do not
+	//include the BasePath.
+	BasePath string
+
+	//HistoryLength is the number of previous cache busting URL paths to remember for each
+	//file. Requests for a previous cache busting URL path will be served the current
+	//version of the file rather than a 404. This prevents errors right after a deploy
+	//when a browser or proxy has cached HTML that references the old cache busting files.
+	//Set to 0 to disable.
+	HistoryLength uint
+
+	//HistoryFile is an optional path to a file used to persist the history of cache
+	//busting URL paths between restarts of your app. This is only used if HistoryLength
+	//is greater than 0.
+	HistoryFile string
+
+	//SelfHeal causes StaticFileHandler to recreate a cache busting copy saved to disk if
+	//the copy has been deleted, for example by an external cleanup process, rather than
+	//responding with a 404. If the original file has changed since Create() was called,
+	//the original file is served without caching instead. This is only applicable when
+	//cache busting copies are saved to disk.
+	SelfHeal bool
+
+	//MaxMemoryBytes is the maximum total size, in bytes, of the cache busting copies that
+	//can be stored in memory. Create() returns ErrMemoryBudgetExceeded if the copies would
+	//use more memory than this. This prevents large files, such as videos, from being
+	//added to memory unknowingly. Set to 0 for no limit.
+	MaxMemoryBytes int64
+
+	//MemoryCacheBytes is the maximum total size, in bytes, of the data of the cache busting
+	//copies stored in memory that is kept in memory. When the size would be exceeded, the
+	//data of the least recently served copies is removed from memory and read again from
+	//the original file, embedded or on disk, the next time the copy is served. Use this
+	//when storing many files in memory so that rarely requested files don't use memory
+	//forever. Copies whose data differs from the original file's data, i.e. CSS files with
+	//rewritten URLs, are always kept in memory. Set to 0 to keep all copies in memory.
+	MemoryCacheBytes int64
+
+	//LowercaseHash causes the hash in the name of each cache busting copy to be lowercase
+	//hexadecimal rather than the default uppercase.
+	LowercaseHash bool
+
+	//HashURLPath causes the URL path of each static file to be mixed into the hash of the
+	//file's contents. Use this when the same file is served from more than one URL path,
+	//i.e. once per tenant, and each URL path should get a different cache busting copy.
+	//The integrity of each file is still calculated from the file's contents alone.
+	HashURLPath bool
+
+	//HashSalt is mixed into the hash of every static file's contents, i.e. a release ID.
+	//Changing the salt changes the name of every cache busting copy, causing browsers and
+	//CDNs to fetch every file again, without changing the files themselves. Use this to
+	//invalidate all cached files in an emergency, such as after serving bad headers. The
+	//integrity of each file is still calculated from the file's contents alone.
+	HashSalt string
+
+	//NoCopy causes Create() to not create any cache busting copies. Instead, a version
+	//query, i.e. "?v=A1B2C3D4", is added to the original file's URL path in the URLs
+	//output by the template funcs, GetURLPairs(), and manifests. The original files are
+	//served as-is by the regular file server. Use this when neither writing to disk nor
+	//storing copies in memory is allowed. Note that some CDNs and proxies ignore the query
+	//when caching.
+	NoCopy bool
+
+	//BuildID, i.e. a release version or git commit, is used as the version query of every
+	//file when NoCopy is true. The original files don't need to be read at all when this
+	//is provided. If not provided, the hash of each file is used.
+	BuildID string
+
+	//NameFunc builds the name of each cache busting copy from the original file's name and
+	//the full hash of the file's contents, replacing the default naming of the hash,
+	//truncated to HashLength, prepended to the original file's name. Use this to match an
+	//existing naming convention, i.e. script.min.A1B2C3D4.js. The name must change when the
+	//hash changes.
+	NameFunc func(originalName, fullHash string) string
+
+	//NameMatch reports if name is the name of a cache busting copy of originalName, as
+	//built by NameFunc. This is used to find old copies to remove and to ignore copies
+	//registered as static files. If NameFunc is provided without NameMatch, old copies are
+	//not removed.
+	NameMatch func(name, originalName string) bool
+
+	//Normalize removes the UTF-8 byte order mark and converts CRLF line endings to LF in
+	//text files, i.e. .js, .css, and .svg, before hashing. This results in the same hash for
+	//a file whether it was checked out or built on Windows or Linux. The modification time
+	//in the header of gzipped (.gz) files is also cleared. The cache busting copy is the
+	//normalized file.
+	Normalize bool
+
+	//BuildInfo is text, i.e. a build time and git commit, appended as a comment to the end
+	//of the cache busting copy of each file with an extension listed in
+	//BuildInfoExtensions. The comment also includes the original file's name. This helps
+	//identify which build a file cached by a user's browser came from. The comment is
+	//added before hashing so the hash matches the copy's contents.
+	BuildInfo string
+
+	//BuildInfoExtensions is the list of file extensions BuildInfo is appended to. If not
+	//provided, BuildInfo is appended to .js, .mjs, and .css files. Extensions without a
+	//known comment syntax are ignored.
+	BuildInfoExtensions []string
+
+	//StreamMinBytes is the size, in bytes, at or above which files read from an embedded
+	//or other filesystem are hashed by streaming the file and served directly from the
+	//filesystem rather than being copied into memory. This allows large files, such as
+	//videos, to be embedded without using as much memory as the file's size. Files whose
+	//data is modified when read, see Normalize, BuildInfo, and ManifestPlaceholder, are
+	//always copied into memory. Set to 0 to disable.
+	StreamMinBytes int64
+
+	//ServeFromEmbedded causes each embedded file to be served directly from EmbeddedFS
+	//rather than from a copy in memory, regardless of the file's size. Since embedded
+	//files are already in the executable, a copy in memory duplicates each file and
+	//roughly doubles the memory used by your static files. Only the cache busting name of
+	//each file is stored. As with StreamMinBytes, files whose data is modified when read
+	//are still copied into memory.
+	ServeFromEmbedded bool
+
+	//RegisterFonts causes each font file referenced by an @font-face rule in a CSS file to
+	//be added as a static file, if it isn't one already, when Create() is called. The URL
+	//of each font in the CSS file is replaced with the cache busting URL of the font.
+	//Font URLs are resolved relative to the CSS file's URL path and the font files are
+	//found relative to the CSS file's local path. Fonts that don't exist are ignored.
+	RegisterFonts bool
+
+	//RewriteCSSURLs causes each url() in a CSS file that refers to a static file, i.e. an
+	//image or SVG sprite, to be replaced with the cache busting URL of the file when
+	//Create() is called. The query and fragment of each URL are kept, so
+	//url(../img/sprite.svg#icon-user) still refers to the same icon. URLs are resolved
+	//relative to the CSS file's URL path.
+	RewriteCSSURLs bool
+
+	//CheckFilesExist causes validation, see Validate(), to check that each original file
+	//exists. Every missing file is reported before Create() reads or writes any file,
+	//rather than Create() failing partway through on the first missing file.
+	CheckFilesExist bool
+
+	//Store is where the cache busting copies are published to, for example a bucket used
+	//as the origin of a CDN, when Create() is called. See AssetStore and Publish().
+	Store AssetStore
+
+	//PublishCacheControl is the Cache-Control header value cache busting copies are
+	//published to Store with. If not provided, copies are cached for a year and marked
+	//as immutable since the contents of a cache busting copy never change.
+	PublishCacheControl string
+
+	//PublishConcurrency is the number of files published to Store at once. If not
+	//provided, files are published one at a time. The store must be safe to use from
+	//multiple goroutines when this is greater than 1.
+	PublishConcurrency int
+
+	//AfterCreate is called at the end of Create() with the details of each file created.
+	//Use this to purge CDN caches, notify other services, or upload the files with your
+	//own tooling. Returning an error causes Create() to fail, and the files created to be
+	//removed, as with any other error.
+	AfterCreate func(files []CreatedFile) error
+
+	//Purger removes outdated URLs from a CDN's cache at the end of Create(). When Create()
+	//is called again, for example after files were changed, the original URL path and
+	//prior cache busting URL path of each file whose hash changed are purged. A failure
+	//to purge is only logged since the new copies are complete and in use. See
+	//CloudflarePurger, FastlyPurger, and CloudFrontPurger.
+	//
+	//Changes since the app was last run, i.e. after a deploy, are only purged if
+	//HistoryLength and HistoryFile are set, since the prior cache busting URL paths are
+	//read from the HistoryFile. Otherwise, only changes between calls to Create() in the
+	//same run of your app are purged.
+	Purger Purger
+
+	//current is the *lookup of the static files used when serving requests and rendering
+	//templates. This is replaced, never modified, by Create() so that requests don't
+	//require locking. See lookup.
+	current atomic.Value
+
+	//fsRoot and fsURLPrefix are the directory in FS and the URL path prefix the files were
+	//found in and served under when using NewFSConfig().
+	fsRoot      string
+	fsURLPrefix string
+
+	//creating is set to 1, atomically, while Create() is running. This prevents Create()
+	//from being run concurrently on the same config, i.e. from a file watcher and a signal
+	//handler at the same time, since that would corrupt StaticFiles.
+	creating int32
+
+	//report is the Report of the last successful call to Create(). This is replaced, like
+	//current, rather than modified so that LastReport() can be called while Create() is
+	//running. See LastReport().
+	report atomic.Value
+
+	//fsErr is the error encountered when finding the files in FS for NewFSConfig(). This is
+	//returned by Create() since NewFSConfig() does not return an error.
+	fsErr error
 }
 
 //default values
@@ -161,6 +568,10 @@ const (
 	//minHashLength is just a value chosen for the shortest hash length we want to support.
 	minHashLength = uint(8)
 
+	//maxHashLength is the number of characters in a hex encoded SHA-256 hash. A longer hash
+	//length cannot be used since there aren't enough characters.
+	maxHashLength = uint(sha256.Size * 2)
+
 	//defaultHashLength is the hash length we will use unless the user provides a value in
 	//their config's HashLength field that is longer than minHashLength.
 	defaultHashLength = minHashLength
@@ -171,6 +582,11 @@ var (
 	//ErrNoFiles is returned when no static files were provided to cache bust.
 	ErrNoFiles = errors.New("cachebusting: no files provided")
 
+	//ErrConfigNotInitialized is returned when Create() is called on a zero value config,
+	//i.e. the package level config when none of the Default...Config() funcs were called
+	//first.
+	ErrConfigNotInitialized = errors.New("cachebusting: config not initialized, use NewConfig() or call a Default...Config() func first")
+
 	//ErrEmptyPath is returned when a static file's local or url path is blank.
 	ErrEmptyPath = errors.New("cachebusting: empty path provided is invalid")
 
@@ -185,6 +601,22 @@ var (
 	//ErrHashLengthToShort is returned when a too short hash length is provided to the config.
 	ErrHashLengthToShort = errors.New("cachebusting: hash length too short, must be at least " + strconv.FormatUint(uint64(minHashLength), 10))
 
+	//ErrHashLengthTooLong is returned when a hash length longer than the number of characters
+	//in the hash is provided to the config.
+	ErrHashLengthTooLong = errors.New("cachebusting: hash length too long, must be at most " + strconv.FormatUint(uint64(maxHashLength), 10))
+
+	//ErrFileMissing is returned when CheckFilesExist is true and an original file does not
+	//exist.
+	ErrFileMissing = errors.New("cachebusting: file does not exist")
+
+	//ErrDuplicateURLPath is returned when two different files are served on the same URL
+	//path.
+	ErrDuplicateURLPath = errors.New("cachebusting: url path used for more than one file")
+
+	//ErrHashCollision is returned when two different files would be served on the same
+	//cache busting URL path and using a longer hash did not resolve the collision.
+	ErrHashCollision = errors.New("cachebusting: cache busting filename collision")
+
 	//ErrFileNotStoredInMemory is returned when a user tries to look up a file's data but
 	//that file's data is stored on disk, not in memory.
 	ErrFileNotStoredInMemory = errors.New("cachebusting: file not stored in memory")
@@ -192,12 +624,104 @@ var (
 	//ErrNotFound is returned when a user tries to look up a file in the list of static files
 	//but the file data cannot be found. This means the file was not cache-busted.
 	ErrNotFound = errors.New("cachebusting: file not found")
+
+	//ErrNotHashed is returned when a file's hash is requested but the file has not been
+	//hashed yet since Create() hasn't been called.
+	ErrNotHashed = errors.New("cachebusting: file not hashed")
+
+	//ErrMemoryBudgetExceeded is returned when the cache busting copies stored in memory
+	//would use more than MaxMemoryBytes.
+	ErrMemoryBudgetExceeded = errors.New("cachebusting: memory budget exceeded")
+
+	//ErrDiskStorageUnavailable is returned when a static file's Storage is StorageDisk but
+	//the original files are embedded or read from a filesystem and therefore copies
+	//cannot be saved to disk.
+	ErrDiskStorageUnavailable = errors.New("cachebusting: disk storage unavailable for files read from a filesystem")
+
+	//ErrCreateInProgress is returned when Create() is called while Create() is already
+	//running for the same config.
+	ErrCreateInProgress = errors.New("cachebusting: create already in progress")
 )
 
+//FileError records an error and the path of the static file that caused it. The path is
+//the local path, URL path, or cache busting URL path depending on what caused the error.
+//Use errors.Is() to check for a specific error, i.e. errors.Is(err, ErrNotFound), and
+//errors.As() to retrieve the path.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+//Error returns the error message followed by the path.
+func (e *FileError) Error() string {
+	return e.Err.Error() + ": " + e.Path
+}
+
+//Unwrap returns the underlying error for use with errors.Is() and errors.As().
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+//multiError records more than one error, for example each problem found by Validate().
+type multiError []error
+
+//joinErrors returns the errors provided as one error, ignoring nil errors. nil is
+//returned if no errors are provided.
+func joinErrors(errs ...error) error {
+	var m multiError
+	for _, err := range errs {
+		if err != nil {
+			m = append(m, err)
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+
+	return m
+}
+
+//Error returns the message of each error on its own line.
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for k, err := range m {
+		msgs[k] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+//Is reports if any of the errors matches target for use with errors.Is().
+func (m multiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//As finds the first of the errors that matches target for use with errors.As().
+func (m multiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 //config is the package level saved config. This stores your config when you want to store
 //it for global use. It is populated when you use one of the Default...Config() funcs.
 var config Config
 
+//configMu protects the package level config from being modified while it is being read
+//by Snapshot() or while Create() is running. The package level funcs that modify the
+//config (Default...Config(), HashLength(), etc.) use this.
+var configMu sync.RWMutex
+
 //NewStaticFile returns an object for a static file with the paths defined. This is just a
 //helper func around creating the StaticFile object.
 func NewStaticFile(localPath, urlPath string) StaticFile {
@@ -207,6 +731,16 @@ func NewStaticFile(localPath, urlPath string) StaticFile {
 	}
 }
 
+//isZero returns true if a config was never set up with NewConfig(), another New...Config()
+//func, or a Default...Config() func since no files or defaults are set.
+func (c *Config) isZero() bool {
+	return c.HashLength == 0 &&
+		len(c.StaticFiles) == 0 &&
+		!c.UseEmbedded &&
+		c.FS == nil &&
+		c.fsErr == nil
+}
+
 //NewConfig returns a config for managing your cache bust files with some defaults set.
 func NewConfig() *Config {
 	return &Config{
@@ -218,7 +752,9 @@ func NewConfig() *Config {
 //NewConfig() and saves the config to the package.
 func DefaultConfig() {
 	cfg := NewConfig()
+	configMu.Lock()
 	config = *cfg
+	configMu.Unlock()
 }
 
 //NewOnDiskConfig returns a config for managing your cache busted files when the original
@@ -234,7 +770,9 @@ func NewOnDiskConfig(files ...StaticFile) *Config {
 //and some defaults.
 func DefaultOnDiskConfig(files ...StaticFile) {
 	cfg := NewOnDiskConfig(files...)
+	configMu.Lock()
 	config = *cfg
+	configMu.Unlock()
 }
 
 //NewEmbeddedConfig returns a config for managing your cache busted files when the original
@@ -252,50 +790,263 @@ func NewEmbeddedConfig(e embed.FS, files ...StaticFile) *Config {
 //and some defaults.
 func DefaultEmbeddedConfig(e embed.FS, files ...StaticFile) {
 	cfg := NewEmbeddedConfig(e, files...)
+	configMu.Lock()
+	config = *cfg
+	configMu.Unlock()
+}
+
+//NewFSConfig returns a config for managing your cache busted files when the original files
+//are stored in a filesystem such as os.DirFS, embed.FS, a zip archive, or fstest.MapFS.
+//Every file in the root directory, including files in nested directories, is added as a
+//static file. Each file's URL path is the urlPrefix joined with the file's path relative
+//to root. For example, with a root of "website/static" and urlPrefix of "/static", the
+//file "website/static/js/vendor/chart.min.js" is served at "/static/js/vendor/chart.min.js".
+//
+//Any error encountered while finding files is returned when Create() is called.
+func NewFSConfig(fsys fs.FS, root, urlPrefix string) *Config {
+	root = path.Clean(filepath.ToSlash(root))
+	files, err := findFSFiles(fsys, root, urlPrefix)
+
+	return &Config{
+		HashLength:  defaultHashLength,
+		StaticFiles: files,
+		FS:          fsys,
+		fsRoot:      root,
+		fsURLPrefix: path.Clean(path.Join("/", urlPrefix)),
+		fsErr:       err,
+	}
+}
+
+//DefaultFSConfig initializes the package level config with the files found in a filesystem.
+func DefaultFSConfig(fsys fs.FS, root, urlPrefix string) {
+	cfg := NewFSConfig(fsys, root, urlPrefix)
+	configMu.Lock()
 	config = *cfg
+	configMu.Unlock()
+}
+
+//findFSFiles returns a static file for each file in the root directory, and nested
+//directories, of a filesystem. Symlinked directories are followed, see walkFiles().
+func findFSFiles(fsys fs.FS, root, urlPrefix string) (files []StaticFile, err error) {
+	err = walkFiles(fsys, root, func(p string) error {
+		rel := strings.TrimPrefix(p, root+"/")
+		if root == "." {
+			rel = p
+		}
+
+		files = append(files, NewStaticFile(p, path.Join("/", urlPrefix, rel)))
+		return nil
+	})
+
+	//don't cache bust copies created previously, i.e. when the filesystem is os.DirFS and
+	//UseMemory was false at some point.
+	files, _ = withoutHashedCopies(files)
+	return
+}
+
+//hashedNameRegex matches the name of a cache busting copy, capturing the original file's
+//name.
+var hashedNameRegex = regexp.MustCompile(`^[A-Fa-f0-9]{` + strconv.FormatUint(uint64(minHashLength), 10) + `,` + strconv.FormatUint(uint64(maxHashLength), 10) + `}\.(.+)$`)
+
+//withoutHashedCopies removes the cache busting copies, i.e. A1B2C3D4.script.min.js, from a
+//list of static files. A file is only treated as a cache busting copy if its name matches
+//the naming pattern and the original file, i.e. script.min.js, is in the list and served
+//from the same URL directory. This prevents a file that just happens to start with a hex
+//string from being removed.
+func withoutHashedCopies(files []StaticFile) (kept, skipped []StaticFile) {
+	urlPaths := make(map[string]bool, len(files))
+	for _, s := range files {
+		urlPaths[s.URLPath] = true
+	}
+
+	kept = make([]StaticFile, 0, len(files))
+	for _, s := range files {
+		m := hashedNameRegex.FindStringSubmatch(path.Base(s.URLPath))
+		if m != nil && urlPaths[path.Join(path.Dir(s.URLPath), m[1])] {
+			skipped = append(skipped, s)
+			continue
+		}
+
+		kept = append(kept, s)
+	}
+
+	return
+}
+
+//withoutHashedCopies removes the cache busting copies from a list of static files, using
+//NameMatch to identify copies if provided. See withoutHashedCopies().
+func (c *Config) withoutHashedCopies(files []StaticFile) (kept, skipped []StaticFile) {
+	if c.NameMatch == nil {
+		return withoutHashedCopies(files)
+	}
+
+	byDir := make(map[string][]string)
+	for _, s := range files {
+		dir := path.Dir(s.URLPath)
+		byDir[dir] = append(byDir[dir], path.Base(s.URLPath))
+	}
+
+	kept = make([]StaticFile, 0, len(files))
+	for _, s := range files {
+		name := path.Base(s.URLPath)
+		isCopy := false
+		for _, original := range byDir[path.Dir(s.URLPath)] {
+			if original != name && c.NameMatch(name, original) {
+				isCopy = true
+				break
+			}
+		}
+
+		if isCopy {
+			skipped = append(skipped, s)
+			continue
+		}
+
+		kept = append(kept, s)
+	}
+
+	return
+}
+
+//usesFS returns true if the original files are read from a filesystem, embedded or
+//otherwise, rather than from disk via the os package.
+func (c *Config) usesFS() bool {
+	return c.UseEmbedded || c.FS != nil
+}
+
+//storesInMemory returns true if the cache busting copies of files are stored in memory
+//rather than on disk. This does not take into account each file's Storage, see inMemory().
+func (c *Config) storesInMemory() bool {
+	return c.usesFS() || c.UseMemory
+}
+
+//inMemory returns true if the cache busting copy of a static file is stored in memory
+//rather than on disk, taking into account the file's Storage.
+func (c *Config) inMemory(s StaticFile) bool {
+	if c.usesFS() {
+		return true
+	}
+
+	switch s.Storage {
+	case StorageMemory:
+		return true
+	case StorageDisk:
+		return false
+	default:
+		return c.UseMemory
+	}
 }
 
-//validate handles validation of a provided config.
+//validate handles validation of a provided config. All problems found are returned,
+//joined into one error, rather than just the first problem so that each problem can be
+//fixed at once. Use errors.Is() to check for a specific problem.
 func (c *Config) validate() (err error) {
+	//check if the config was never set up, which is a more useful error than no files
+	//being provided.
+	if c.isZero() {
+		return ErrConfigNotInitialized
+	}
+
+	//check if an error occured finding files in the filesystem for NewFSConfig.
+	if c.fsErr != nil {
+		return c.fsErr
+	}
+
 	//check if no files were provided.
 	if len(c.StaticFiles) == 0 {
 		return ErrNoFiles
 	}
 
+	var errs []error
+	localPaths := make(map[string]string, len(c.StaticFiles))
 	for k, s := range c.StaticFiles {
 		//check if any file paths are blank.
 		l := strings.TrimSpace(s.LocalPath)
 		u := strings.TrimSpace(s.URLPath)
 		if l == "" || u == "" {
-			return ErrEmptyPath
+			errs = append(errs, &FileError{Path: l + u, Err: ErrEmptyPath})
+			continue
 		}
 
 		//make sure if user is using embedded file, the paths use a "/" separator.
-		if c.UseEmbedded {
+		if c.usesFS() {
 			l = filepath.ToSlash(l)
 			c.StaticFiles[k].LocalPath = l
+
+			//copies of files read from a filesystem can only be stored in memory.
+			if s.Storage == StorageDisk {
+				errs = append(errs, &FileError{Path: l, Err: ErrDiskStorageUnavailable})
+			}
 		}
 
 		//make sure url paths use a "/" separator and path starts with a "/".
-		//Join adds the "/" in case the user forgot it, Clean removes any double "//"
-		//in cases where user did add "/" and we just added another.
-		u = path.Clean(path.Join("/", filepath.ToSlash(u)))
+		u = cleanURLPath(u)
 		c.StaticFiles[k].URLPath = u
+
+		//check if two different files would be served on the same URL path. The same
+		//file listed more than once is allowed. Aliases are checked as well.
+		if other, ok := localPaths[u]; ok && other != c.StaticFiles[k].LocalPath {
+			errs = append(errs, &FileError{Path: u, Err: ErrDuplicateURLPath})
+		}
+		localPaths[u] = c.StaticFiles[k].LocalPath
+
+		for i, a := range s.Aliases {
+			a = cleanURLPath(a)
+			c.StaticFiles[k].Aliases[i] = a
+
+			if other, ok := localPaths[a]; ok && other != c.StaticFiles[k].LocalPath {
+				errs = append(errs, &FileError{Path: a, Err: ErrDuplicateURLPath})
+			}
+			localPaths[a] = c.StaticFiles[k].LocalPath
+		}
+
+		//check that the original file exists.
+		if c.CheckFilesExist && s.sourceData == nil {
+			err := c.statOriginal(c.StaticFiles[k].LocalPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, &FileError{Path: c.StaticFiles[k].LocalPath, Err: ErrFileMissing})
+			} else if err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
 
-	//check if the static hash length was provided or is too short
+	//check if the static hash length was provided or is too short or too long
 	if c.HashLength == 0 {
 		c.HashLength = defaultHashLength
 	} else if c.HashLength < minHashLength {
-		return ErrHashLengthToShort
+		errs = append(errs, ErrHashLengthToShort)
+	} else if c.HashLength > maxHashLength {
+		errs = append(errs, ErrHashLengthTooLong)
 	}
 
 	//if user is using embedded files, make sure something was provided.
 	if c.UseEmbedded && c.EmbeddedFS == (embed.FS{}) {
-		return ErrNoEmbeddedFilesProvided
+		errs = append(errs, ErrNoEmbeddedFilesProvided)
 	}
 
-	return
+	return joinErrors(errs...)
+}
+
+//Validate checks the config for problems, returning all problems found joined into one
+//error. This is also done when Create() is called. Use this to check a config, for
+//example at startup or in a test, without creating the cache busting files. Paths are
+//cleaned and a default HashLength is set as part of validation.
+func (c *Config) Validate() error {
+	return c.validate()
+}
+
+//statOriginal returns an error if an original file, on disk or in the embedded or other
+//filesystem, cannot be found.
+func (c *Config) statOriginal(localPath string) error {
+	var err error
+	if c.usesFS() {
+		_, err = fs.Stat(c.sourceFS(), localPath)
+	} else {
+		_, err = os.Stat(localPath)
+	}
+
+	return err
 }
 
 //Create handles the creation of the cache busting files and associated data. This calculates
@@ -303,129 +1054,321 @@ func (c *Config) validate() (err error) {
 //by a new name using the hash. The copy of the original static file is either saved to disk
 //(for original files stored on disk) or in memory (for embedded files or if the config's
 //UseMemory field is set to true). This also saves some info for use in serving each cache
-//busting copy of the static original file.
+//busting copy of the static original file. ErrCreateInProgress is returned if Create() is
+//already running for this config.
 func (c *Config) Create() (err error) {
+	//make sure Create() isn't already running, see creating.
+	if !atomic.CompareAndSwapInt32(&c.creating, 0, 1) {
+		return ErrCreateInProgress
+	}
+	defer atomic.StoreInt32(&c.creating, 0)
+	started := time.Now()
+
+	//undo any changes if an error occurs so that a failed Create() leaves the config, and
+	//the cache busting files on disk, as they were. The static files, and the files being
+	//served, are restored and any copies saved to disk are removed. Old cache busting
+	//files are only removed once every copy has been saved.
+	original := append([]StaticFile(nil), c.StaticFiles...)
+	previous := c.currentLookup()
+	var written []string
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		for _, p := range written {
+			os.Remove(p)
+		}
+		c.StaticFiles = original
+		c.current.Store(previous)
+	}()
+
+	//make sure URL paths match the decoded path of requests.
+	c.normalizeURLPaths()
+
+	//remove the files that aren't used in this build or environment.
+	var excluded []StaticFile
+	c.StaticFiles, excluded = withoutExcluded(c.StaticFiles)
+	if c.Debug {
+		for _, s := range excluded {
+			log.Println("cachebusting.Create (debug)", "skipping static file not included", s.LocalPath)
+		}
+	}
+
 	//validate the config
 	err = c.validate()
 	if err != nil {
 		return
 	}
 
+	//ignore any cache busting copies created previously that were registered as static
+	//files, otherwise the copies would be cache busted again.
+	var skipped []StaticFile
+	c.StaticFiles, skipped = c.withoutHashedCopies(c.StaticFiles)
+	if c.Debug {
+		for _, s := range skipped {
+			log.Println("cachebusting.Create (debug)", "skipping cache busting copy registered as a static file", s.LocalPath)
+		}
+	}
+
 	//ignore creating cache busting files in development.
 	if c.Development {
 		if c.Debug {
 			log.Println("cachebusting.Create (debug)", "creation of cache busting files is disabled, config field Development is true")
 		}
 
+		if c.DevelopmentPassthrough {
+			c.passthrough()
+			return nil
+		}
+
 		return ErrNoCacheBustingInDevelopment
 	}
 
-	//determine the correct func to use for reading original file's data.
-	//We aren't using Open(), even though that would have been nicer, since os.Open (for on
-	//disk files) returns a *File type while embed.Open (for embedded files) returns just a
-	//File type (notice no pointer *).
-	var readFunc func(string) ([]byte, error)
-	if c.UseEmbedded {
-		readFunc = c.EmbeddedFS.ReadFile
-	} else {
-		readFunc = os.ReadFile
+	//map files to themselves, with a version query, rather than creating copies.
+	if c.NoCopy {
+		err = c.noCopy()
+		if err != nil {
+			return
+		}
+
+		c.report.Store(c.newReport(started, c.createdFiles(make([][]byte, len(c.StaticFiles))), make([]time.Duration, len(c.StaticFiles))))
+		return
 	}
 
-	//Handle each static file.
-	//This will:
-	// 1) Hash the file to create a somewhat random and unique element to prepend to the file's name.
-	// 2) Create a copy of the file, either on disk or in memory, using the hash and original file's name.
-	// 3) Store some info about each cache busting file.
-	for k, s := range c.StaticFiles {
-		//use correct path separator
-		//If using embedded files, the path separator is always "/" so we need to parse
-		//the path as such in case user used filepath.Join to build the path and thus the
-		//file's local path has possibly Windows "\" separators.
-		originalPath := s.LocalPath
-		if c.UseEmbedded {
-			originalPath = filepath.ToSlash(s.LocalPath)
+	//generate the files derived from each file's variants.
+	err = c.generateVariants()
+	if err != nil {
+		return
+	}
+
+	//add the fonts referenced by CSS files.
+	if c.RegisterFonts {
+		err = c.registerFonts()
+		if err != nil {
+			return
 		}
+	}
 
-		//get just the name of the static file
-		//This is used as a base to create the filename of the cache busting file. The
-		//hash calculated from the file's data is prepended to this.
-		originalFilename := filepath.Base(originalPath)
+	//load the history of cache busting URL paths saved from a prior run of the app.
+	var history map[string][]string
+	if c.HistoryLength > 0 && c.HistoryFile != "" {
+		history, err = readHistoryFile(c.HistoryFile)
+		if err != nil {
+			return
+		}
+	}
 
-		//get just the directory of the static file
-		//This is used for removing old cache busting files from this directory as well
-		//as saving the new cache busting file
-		originalDirectory := filepath.Dir(s.LocalPath)
+	//Read and hash each static file.
+	//The hash is used to create a somewhat random and unique element to prepend to the
+	//file's name. All files are hashed prior to creating any copies so that the cache
+	//busting filenames can be checked for collisions first.
+	fileData := make([][]byte, len(c.StaticFiles))
+	hashLengths := make([]uint, len(c.StaticFiles))
+	durations := make([]time.Duration, len(c.StaticFiles))
+	trusted := make([]bool, len(c.StaticFiles))
+	mappings := make(map[string]mappingFile)
+	order, priority := priorityOrder(c.StaticFiles)
+	for i, k := range order {
+		s := c.StaticFiles[k]
+
+		//serve the priority files, which have all been hashed, while the other files
+		//are hashed.
+		if i > 0 && i == priority {
+			c.warmUp(order[:priority], fileData, hashLengths)
+		}
 
-		//remove any old cache busting files if the files are stored on disk.
-		//This prevents the filesystem from getting clogged up with all sorts of old
-		//unneeded files.
-		if !c.UseEmbedded && !c.UseMemory {
-			innerErr := removeOldCacheBustingFiles(originalDirectory, originalFilename, c.HashLength)
-			if err != nil {
-				return innerErr
+		fileStarted := time.Now()
+
+		//use hashes recorded in the mapping file for files that haven't changed, see
+		//MappingFile. The file is only read if its copy needs to be saved.
+		if hash, integrity, ok := c.trustedHash(s, mappings); ok {
+			trusted[k] = true
+			c.StaticFiles[k].hash = hash
+			c.StaticFiles[k].integrity = integrity
+			c.StaticFiles[k].streamed = false
+			hashLengths[k] = c.HashLength
+			if hashLengths[k] == 0 {
+				hashLengths[k] = defaultHashLength
 			}
+
+			durations[k] = time.Since(fileStarted)
+			continue
 		}
 
-		//read in the original file
-		originalFile, innerErr := readFunc(originalPath)
+		//hash large files by streaming them from the filesystem, see StreamMinBytes.
+		h, streamed, innerErr := c.streamHash(s)
 		if innerErr != nil {
 			return innerErr
 		}
+		c.StaticFiles[k].streamed = streamed
+
+		if !streamed {
+			//read in the original file
+			originalFile, innerErr := c.readOriginal(s)
+			if innerErr != nil {
+				return innerErr
+			}
+			fileData[k] = originalFile
 
-		//calculate hash of the original file's data
-		//This gives us a random and unique element we can prepend to the file's name
-		//so that the file's name will change if the contents have changed therefore
-		//not using the browser cached version of the file.
-		h := sha256.Sum256(originalFile)
-		hash := strings.ToUpper(hex.EncodeToString(h[:]))
+			//calculate hash of the original file's data
+			//This gives us a random and unique element we can prepend to the file's name
+			//so that the file's name will change if the contents have changed therefore
+			//not using the browser cached version of the file.
+			h = sha256.Sum256(originalFile)
+		}
+		c.StaticFiles[k].hash = c.nameHash(s, h)
+		c.StaticFiles[k].integrity = "sha256-" + base64.StdEncoding.EncodeToString(h[:])
 
-		//trim the hash as needed.
-		if c.HashLength == 0 {
+		//use hash length set in config
+		hashLengths[k] = c.HashLength
+		if hashLengths[k] == 0 {
 			//double check even though this should have been caught in validate.
 			//use default.
-			hash = hash[:defaultHashLength]
-		} else if int(c.HashLength) > len(hash) {
-			//hash length set in config is longer then the actual hash.
-			//use entire hash.
+			hashLengths[k] = defaultHashLength
+		}
 
-		} else {
-			//use hash length set in config
-			hash = hash[:c.HashLength]
+		durations[k] = time.Since(fileStarted)
+	}
+
+	//make sure the copies fit in the memory allowed.
+	if c.MaxMemoryBytes > 0 {
+		err = c.checkMemoryBudget(fileData)
+		if err != nil {
+			return
+		}
+	}
+
+	//make sure no two different files end up with the same cache busting URL path. This
+	//can happen, although rarely, with short hash lengths. Colliding files get a longer
+	//hash.
+	err = c.resolveCollisions(hashLengths)
+	if err != nil {
+		return
+	}
+
+	//replace the URLs in CSS files with the cache busting URLs. This changes the CSS files'
+	//contents and therefore their hashes.
+	if c.RegisterFonts || c.RewriteCSSURLs {
+		err = c.rewriteCSSURLs(fileData, hashLengths)
+		if err != nil {
+			return
+		}
+	}
+
+	//replace the URLs of icons in web app manifests with the cache busting URLs. This
+	//changes the manifests' contents and therefore their hashes.
+	err = c.rewriteWebAppManifests(fileData, hashLengths)
+	if err != nil {
+		return
+	}
+
+	//replace the placeholder in entry files with the mapping of cache busting URLs. This
+	//changes the entry files' contents and therefore their hashes.
+	err = c.injectManifests(fileData, hashLengths)
+	if err != nil {
+		return
+	}
+
+	//make sure each copy saved to disk can be saved given the length of its path.
+	err = c.checkPathLengths(hashLengths)
+	if err != nil {
+		return
+	}
+
+	//make sure the copies saved to disk fit on the disk.
+	err = c.checkDiskSpace(fileData, hashLengths)
+	if err != nil {
+		return
+	}
+
+	//listing of each directory static files are stored in, used for removing old cache
+	//busting files. Each directory is only read once.
+	dirListings := make(map[string][]fs.DirEntry)
+
+	//old cache busting files to remove once every copy has been saved.
+	type oldFiles struct {
+		directory        string
+		originalFilename string
+		keep             string
+	}
+	var removals []oldFiles
+
+	//Handle each static file.
+	//This will:
+	// 1) Create a copy of the file, either on disk or in memory, using the hash and original file's name.
+	// 2) Store some info about each cache busting file.
+	for k, s := range c.StaticFiles {
+		//get just the name of the static file
+		//This is used as a base to create the filename of the cache busting file. The
+		//hash calculated from the file's data is prepended to this.
+		originalFilename := filepath.Base(s.LocalPath)
+		if c.usesFS() {
+			originalFilename = path.Base(filepath.ToSlash(s.LocalPath))
+		}
+
+		//vendor files are served as-is, no cache busting copy is made.
+		if s.Vendor {
+			c.vendor(k, originalFilename, fileData[k])
+			continue
 		}
 
+		//get just the directory of the static file
+		//This is used for removing old cache busting files from this directory as well
+		//as saving the new cache busting file
+		originalDirectory := filepath.Dir(s.LocalPath)
+
 		//create the filename for the cache busting copy of the file
-		cachebustFilename := hash + "." + originalFilename
-
-		//save a copy of the file's contents
-		//When saving a file back to disk, the default for original files stored on
-		//disk, this simply saves a copy of the file with the new name back to the
-		//same directory.
-		//For embedded files, or when UseMemory is true for original files stored on
-		//disk, this saves a copy of the file to the app's memory.
-		if !c.UseEmbedded && !c.UseMemory {
-			cachebustPath := filepath.Join(originalDirectory, cachebustFilename)
-
-			f, innerErr := os.Create(cachebustPath)
-			if innerErr != nil {
-				return innerErr
-			}
-			defer f.Close()
+		cachebustFilename := c.cacheBustFilename(c.StaticFiles[k].hash, hashLengths[k], originalFilename)
 
-			_, innerErr = f.Write(originalFile)
-			if innerErr != nil {
-				return innerErr
+		//remember to remove any old cache busting files if the files are stored on disk.
+		//This prevents the filesystem from getting clogged up with all sorts of old
+		//unneeded files. A copy with the same name already existing means the file hasn't
+		//changed, the copy must not be removed if an error occurs.
+		existed := false
+		if !c.inMemory(s) {
+			files, ok := dirListings[originalDirectory]
+			if !ok {
+				var innerErr error
+				files, innerErr = os.ReadDir(originalDirectory)
+				if innerErr != nil {
+					return innerErr
+				}
+				dirListings[originalDirectory] = files
 			}
-			f.Close()
 
-			if c.Debug {
-				log.Println("cachebusting.Create (debug)", "copying cache busting files to", cachebustPath)
+			for _, f := range files {
+				if f.Name() == cachebustFilename {
+					existed = true
+					break
+				}
 			}
+		}
 
-			c.StaticFiles[k].cacheBustLocalPath = cachebustPath
-
+		//save a copy of the file's contents. A file whose hash was trusted from the
+		//mapping file doesn't need to be saved, or even read, if the copy already exists.
+		if trusted[k] && existed {
+			c.StaticFiles[k].fileData = nil
+			c.StaticFiles[k].cacheBustLocalPath = filepath.Join(originalDirectory, cachebustFilename)
 		} else {
-			c.StaticFiles[k].fileData = originalFile
-			c.StaticFiles[k].cacheBustLocalPath = cachebustFilename + " (in memory)" //diagnostics
+			if trusted[k] {
+				fileData[k], err = c.readOriginal(s)
+				if err != nil {
+					return
+				}
+			}
+
+			innerErr := c.saveCopy(k, cachebustFilename, fileData[k])
+			if innerErr != nil {
+				return innerErr
+			}
+		}
+		//the copy may have been stored in memory instead, see MemoryFallback.
+		if !c.inMemory(c.StaticFiles[k]) {
+			removals = append(removals, oldFiles{originalDirectory, originalFilename, cachebustFilename})
+			if !existed {
+				written = append(written, c.StaticFiles[k].cacheBustLocalPath)
+			}
 		}
 
 		//save the url path/endpoint this file should be served on
@@ -436,43 +1379,375 @@ func (c *Config) Create() (err error) {
 		//can use os.DirFS and http.FileServer. Using path here, not filepath, since we
 		//always want to treat the output as separated by "/".
 		c.StaticFiles[k].cacheBustURLPath = path.Join(path.Dir(s.URLPath), cachebustFilename)
-	}
 
-	//the below code is messy, I am aware
-	if c.Debug {
-		//tabwriter used to organize logging output better
-		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 1, ' ', tabwriter.Debug)
+		//remember the cache busting URL path used prior so that requests for the outdated
+		//file can still be served.
+		if c.HistoryLength > 0 {
+			previous := s.previousURLPaths
+			if s.cacheBustURLPath != "" {
+				previous = append([]string{s.cacheBustURLPath}, previous...)
+			}
+			previous = append(previous, history[s.URLPath]...)
 
-		log.Println("cachebusting.Create (debug)", "cache busted files matching...")
-		cols := []string{"ORIGINAL FILENAME", "CACHEBUST FILENAME"}
-		fmt.Fprintln(tw, strings.Join(cols, "\t"))
-		for _, v := range c.StaticFiles {
-			cols := []string{filepath.Base(v.LocalPath), filepath.Base(v.cacheBustLocalPath)}
-			fmt.Fprintln(tw, strings.Join(cols, "\t"))
+			c.StaticFiles[k].previousURLPaths = trimHistory(previous, c.StaticFiles[k].cacheBustURLPath, c.HistoryLength)
 		}
-		tw.Flush()
+	}
+
+	//limit the memory used by copies stored in memory, see MemoryCacheBytes.
+	cache := c.useMemoryCache()
 
-		log.Println("")
+	//build the lookups used when serving files. The lookup, and the memory cache, are
+	//replaced with the prior ones if an error occurs.
+	c.storeIndex(cache)
 
-		log.Println("cachebusting.Create (debug)", "cache busted url matching...")
-		cols = []string{"ORIGINAL URL PATH", "CACHEBUST URL PATH"}
-		fmt.Fprintln(tw, strings.Join(cols, "\t"))
-		for _, v := range c.StaticFiles {
-			cols = []string{v.URLPath, v.cacheBustURLPath}
-			fmt.Fprintln(tw, strings.Join(cols, "\t"))
+	//save the mapping of each original file to its copy for use the next time Create() is
+	//called. A failure is only logged since the copies are complete and in use.
+	if c.MappingFile {
+		mappingErr := c.writeMappingFiles()
+		if mappingErr != nil {
+			log.Println("cachebusting.Create", "could not save mapping file", mappingErr)
 		}
-		tw.Flush()
+	}
+
+	//save the history of cache busting URL paths for use the next time the app starts.
+	if c.HistoryLength > 0 && c.HistoryFile != "" {
+		err = c.writeHistoryFile()
+		if err != nil {
+			return
+		}
+	}
+
+	//publish the cache busting copies.
+	if c.Store != nil {
+		err = c.Publish(context.Background(), c.Store)
+		if err != nil {
+			return
+		}
+	}
+
+	//let the user handle the files created.
+	created := c.createdFiles(fileData)
+	if c.AfterCreate != nil {
+		err = c.AfterCreate(created)
+		if err != nil {
+			return
+		}
+	}
+
+	//remove the old cache busting files now that every copy has been saved. A failure to
+	//remove an old file is only logged since the new copies are complete and in use.
+	for _, r := range removals {
+		isCopy, removeErr := c.copyMatcher(r.originalFilename)
+		if removeErr == nil {
+			removeErr = removeOldCacheBustingFilesFromList(r.directory, dirListings[r.directory], isCopy, r.keep)
+		}
+		if removeErr != nil {
+			log.Println("cachebusting.Create", "could not remove old cache busting files", r.directory, removeErr)
+		}
+	}
+
+	//purge the outdated URLs from the CDN.
+	if c.Purger != nil {
+		if urlPaths := c.changedURLPaths(original, history); len(urlPaths) > 0 {
+			purgeErr := c.Purger.Purge(context.Background(), urlPaths)
+			if purgeErr != nil {
+				log.Println("cachebusting.Create", "could not purge changed files", purgeErr)
+			}
+		}
+	}
+
+	c.report.Store(c.newReport(started, created, durations))
+
+	if c.Debug {
+		log.Println("cachebusting.Create (debug)", "cache busted files matching...")
+		fmt.Fprint(c.debugWriter(), c.String())
 	}
 
 	return
 }
 
+//vendor maps a vendor file to itself. The file's data is stored in memory if the file is
+//stored in memory, otherwise the original file on disk is served.
+//
+//k is the index of the static file in StaticFiles.
+func (c *Config) vendor(k int, originalFilename string, data []byte) {
+	s := c.StaticFiles[k]
+	c.StaticFiles[k].cacheBustURLPath = s.URLPath
+
+	if s.streamed {
+		c.StaticFiles[k].fileData = nil
+		c.StaticFiles[k].cacheBustLocalPath = originalFilename + " (streamed)" //diagnostics
+		return
+	}
+
+	if c.inMemory(s) {
+		c.StaticFiles[k].fileData = data
+		c.StaticFiles[k].cacheBustLocalPath = originalFilename + " (in memory)" //diagnostics
+		return
+	}
+
+	c.StaticFiles[k].fileData = nil
+	c.StaticFiles[k].cacheBustLocalPath = s.LocalPath
+}
+
+//passthrough maps each static file to itself for use in development. No copies are made,
+//the original files are served instead. Hashes are not calculated since the original
+//files may change at any time during development.
+func (c *Config) passthrough() {
+	for k, s := range c.StaticFiles {
+		c.StaticFiles[k].cacheBustURLPath = s.URLPath
+		c.StaticFiles[k].cacheBustLocalPath = s.LocalPath
+		c.StaticFiles[k].fileData = nil
+		c.StaticFiles[k].hash = ""
+		c.StaticFiles[k].integrity = ""
+	}
+
+	c.buildIndex()
+}
+
+//debugWriter returns the writer diagnostic output is written to.
+func (c *Config) debugWriter() io.Writer {
+	if c.DebugWriter == nil {
+		return os.Stdout
+	}
+
+	return c.DebugWriter
+}
+
 //Create handles creation of the cache busting files using the default package level config.
 func Create() (err error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
 	err = config.Create()
 	return
 }
 
+//readFunc returns the func to use for reading an original file's data.
+//We aren't using Open(), even though that would have been nicer, since os.Open (for on
+//disk files) returns a *File type while embed.Open (for embedded files) returns just a
+//File type (notice no pointer *).
+func (c *Config) readFunc() func(string) ([]byte, error) {
+	if c.UseEmbedded {
+		return limitedRead(c.EmbeddedFS.ReadFile)
+	} else if c.FS != nil {
+		return limitedRead(func(p string) ([]byte, error) {
+			return fs.ReadFile(c.FS, p)
+		})
+	}
+
+	return limitedRead(os.ReadFile)
+}
+
+//readOriginal reads the original file's data for a static file. Files generated from a
+//Variant use the generated data since the file doesn't exist.
+func (c *Config) readOriginal(s StaticFile) ([]byte, error) {
+	if s.sourceData != nil {
+		return s.sourceData, nil
+	}
+
+	//use correct path separator
+	//If using embedded files, the path separator is always "/" so we need to parse
+	//the path as such in case user used filepath.Join to build the path and thus the
+	//file's local path has possibly Windows "\" separators.
+	originalPath := s.LocalPath
+	if c.usesFS() {
+		originalPath = filepath.ToSlash(s.LocalPath)
+	}
+
+	data, err := c.readFunc()(originalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.appendBuildInfo(s, c.normalize(s, data)), nil
+}
+
+//saveCopy saves the cache busting copy of a static file using the cache busting filename.
+//When saving a file back to disk, the default for original files stored on disk, this
+//simply saves a copy of the file with the new name back to the same directory as the
+//original file. For embedded files, or when UseMemory is true for original files stored
+//on disk, this saves a copy of the file to the app's memory.
+//
+//k is the index of the static file in StaticFiles.
+func (c *Config) saveCopy(k int, cachebustFilename string, data []byte) error {
+	if c.StaticFiles[k].streamed {
+		c.StaticFiles[k].fileData = nil
+		c.StaticFiles[k].cacheBustLocalPath = cachebustFilename + " (streamed)" //diagnostics
+		return nil
+	}
+
+	if c.inMemory(c.StaticFiles[k]) {
+		c.StaticFiles[k].fileData = data
+		c.StaticFiles[k].cacheBustLocalPath = cachebustFilename + " (in memory)" //diagnostics
+		return nil
+	}
+
+	//clear any copy stored in memory previously in case the file's Storage changed.
+	c.StaticFiles[k].fileData = nil
+
+	cachebustPath := filepath.Join(filepath.Dir(c.StaticFiles[k].LocalPath), cachebustFilename)
+
+	err := writeFile(cachebustPath, data, c.TempDir)
+	if err != nil && isReadOnlyErr(err) {
+		if !c.MemoryFallback {
+			return &FileError{Path: cachebustPath, Err: joinErrors(ErrReadOnly, err)}
+		}
+
+		log.Println("cachebusting.Create", "could not save cache busting copy to disk, storing in memory instead", cachebustPath, err)
+		c.StaticFiles[k].Storage = StorageMemory
+		c.StaticFiles[k].fileData = data
+		c.StaticFiles[k].cacheBustLocalPath = cachebustFilename + " (in memory)" //diagnostics
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if c.Debug {
+		log.Println("cachebusting.Create (debug)", "copying cache busting files to", cachebustPath)
+	}
+
+	c.StaticFiles[k].cacheBustLocalPath = cachebustPath
+	return nil
+}
+
+//checkMemoryBudget returns ErrMemoryBudgetExceeded if the total size of the data of each
+//static file stored in memory is more than MaxMemoryBytes. The error lists the size of each file, largest
+//first, so the files causing the problem can be easily found.
+//
+//fileData is the data of each static file, in the same order as StaticFiles.
+func (c *Config) checkMemoryBudget(fileData [][]byte) error {
+	var total int64
+	order := make([]int, 0, len(fileData))
+	for k, d := range fileData {
+		if !c.inMemory(c.StaticFiles[k]) {
+			continue
+		}
+
+		total += int64(len(d))
+		order = append(order, k)
+	}
+
+	if total <= c.MaxMemoryBytes {
+		return nil
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(fileData[order[i]]) > len(fileData[order[j]])
+	})
+
+	sizes := make([]string, 0, len(order))
+	for _, k := range order {
+		sizes = append(sizes, c.StaticFiles[k].LocalPath+" ("+strconv.Itoa(len(fileData[k]))+" bytes)")
+	}
+
+	return fmt.Errorf("%w: %d bytes needed, %d bytes allowed, files: %s", ErrMemoryBudgetExceeded, total, c.MaxMemoryBytes, strings.Join(sizes, ", "))
+}
+
+//upperHex returns the uppercase hex encoding of a hash. This avoids the extra allocation of
+//encoding to lowercase and then converting to uppercase.
+func upperHex(h [sha256.Size]byte) string {
+	const chars = "0123456789ABCDEF"
+
+	var b [sha256.Size * 2]byte
+	for i, v := range h {
+		b[i*2] = chars[v>>4]
+		b[i*2+1] = chars[v&0x0f]
+	}
+
+	return string(b[:])
+}
+
+//cacheBustFilename returns the name of the cache busting copy of a file. This is the hash,
+//truncated to the given length, prepended to the original file's name.
+func cacheBustFilename(hash string, hashLength uint, originalFilename string) string {
+	if hashLength > uint(len(hash)) {
+		hashLength = uint(len(hash))
+	}
+
+	return hash[:hashLength] + "." + originalFilename
+}
+
+//cacheBustFilename returns the name of the cache busting copy of a file, using a lowercase
+//hash if LowercaseHash is true. NameFunc is used, with the full hash, if provided.
+func (c *Config) cacheBustFilename(hash string, hashLength uint, originalFilename string) string {
+	if c.LowercaseHash {
+		hash = strings.ToLower(hash)
+	}
+
+	if c.NameFunc != nil {
+		return c.NameFunc(originalFilename, hash)
+	}
+
+	return cacheBustFilename(hash, hashLength, originalFilename)
+}
+
+//resolveCollisions checks if any two different files would be served on the same cache
+//busting URL path. This can happen when two different files with the same name are served
+//from the same URL directory and the truncated hashes of the files happen to match. Each
+//colliding file is given a longer hash until the collision is resolved. An error is
+//returned if the collision cannot be resolved, which would mean the full hashes of two
+//different files match or the same URL path was used for two different files.
+//
+//hashLengths is the length of the hash to use for each static file and is modified as
+//needed.
+func (c *Config) resolveCollisions(hashLengths []uint) error {
+	for {
+		//group files by the cache busting URL path each would be served on.
+		byURLPath := make(map[string][]int, len(c.StaticFiles))
+		for k, s := range c.StaticFiles {
+			if s.Vendor {
+				continue
+			}
+
+			name := c.cacheBustFilename(s.hash, hashLengths[k], path.Base(filepath.ToSlash(s.LocalPath)))
+			u := path.Join(path.Dir(s.URLPath), name)
+			byURLPath[u] = append(byURLPath[u], k)
+		}
+
+		//find files that collide. The same file being listed more than once is not a
+		//collision since the contents, and thus the full hash, are the same.
+		collided := false
+		for u, indexes := range byURLPath {
+			if len(indexes) < 2 {
+				continue
+			}
+
+			differ := false
+			for _, k := range indexes[1:] {
+				if c.StaticFiles[k].hash != c.StaticFiles[indexes[0]].hash {
+					differ = true
+					break
+				}
+			}
+			if !differ {
+				continue
+			}
+
+			for _, k := range indexes {
+				if hashLengths[k] >= maxHashLength {
+					return &FileError{Path: u, Err: ErrHashCollision}
+				}
+
+				hashLengths[k] += minHashLength
+				if hashLengths[k] > maxHashLength {
+					hashLengths[k] = maxHashLength
+				}
+			}
+
+			if c.Debug {
+				log.Println("cachebusting.Create (debug)", "cache busting filename collision, using longer hash for", u)
+			}
+
+			collided = true
+		}
+
+		if !collided {
+			return nil
+		}
+	}
+}
+
 //removeOldCacheBustingFiles deletes already existing cache busting files from a given
 //directory. This prevents the directory from needlessly getting filled up with unused
 //files.
@@ -489,28 +1764,92 @@ func removeOldCacheBustingFiles(directory, originalFilename string, hashLength u
 		return err
 	}
 
-	//check if each file is an old cache busting file.
-	for _, f := range files {
-		if f.IsDir() {
-			return err
-		}
+	isCopy, err := defaultCopyMatcher(originalFilename, hashLength)
+	if err != nil {
+		return err
+	}
 
-		//we know our hash only contains uppercase A-F and 0-9 digits since we are encoding
-		//the hash to uppercase hexidecimal.
-		exp := "[A-F0-9]{" + strconv.FormatUint(uint64(hashLength), 10) + "}." + originalFilename
+	return removeOldCacheBustingFilesFromList(directory, files, isCopy, "")
+}
 
-		//we aren't using regexp.MustCompile here since the expression changes with user input,
-		//the expression isn't hardcoded in the app, so we want to return the error rather then
-		//just panicing.
-		r, err := regexp.Compile(exp)
-		if err != nil {
-			return err
+//removeOldCopies deletes already existing cache busting files of an original file from a
+//given directory, matching files using NameMatch if provided. The file named keep, the
+//current cache busting copy, is not removed.
+func (c *Config) removeOldCopies(directory, originalFilename, keep string) error {
+	files, err := os.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+
+	isCopy, err := c.copyMatcher(originalFilename)
+	if err != nil {
+		return err
+	}
+
+	return removeOldCacheBustingFilesFromList(directory, files, isCopy, keep)
+}
+
+//defaultCopyMatcher returns a func that reports if a file's name is the name of a cache
+//busting copy of the original file, using the default naming of a hash prepended to the
+//original file's name.
+func defaultCopyMatcher(originalFilename string, hashLength uint) (func(string) bool, error) {
+	//we know our hash only contains A-F and 0-9 digits since we are encoding the hash to
+	//hexidecimal. Both upper and lowercase are matched so that old files are removed even
+	//if LowercaseHash was changed.
+	//The hash may be longer than hashLength if the hash was lengthened to resolve a
+	//collision.
+	exp := "^[A-Fa-f0-9]{" + strconv.FormatUint(uint64(hashLength), 10) + "," + strconv.FormatUint(uint64(maxHashLength), 10) + "}\\." + regexp.QuoteMeta(originalFilename) + "$"
+
+	//we aren't using regexp.MustCompile here since the expression changes with user input,
+	//the expression isn't hardcoded in the app, so we want to return the error rather then
+	//just panicing.
+	r, err := regexp.Compile(exp)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.MatchString, nil
+}
+
+//copyMatcher returns a func that reports if a file's name is the name of a cache busting
+//copy of the original file. NameMatch is used if provided. If NameFunc is provided without
+//NameMatch, no file is matched since the naming cannot be reversed.
+func (c *Config) copyMatcher(originalFilename string) (func(string) bool, error) {
+	if c.NameMatch != nil {
+		return func(name string) bool {
+			return c.NameMatch(name, originalFilename)
+		}, nil
+	} else if c.NameFunc != nil {
+		return func(string) bool { return false }, nil
+	}
+
+	return defaultCopyMatcher(originalFilename, c.HashLength)
+}
+
+//removeOldCacheBustingFilesFromList deletes already existing cache busting files from a
+//given directory using an already retrieved list of the files in the directory. This is
+//used so that a directory with many static files is only read once rather than once per
+//static file. isCopy reports if a file is a cache busting copy. The file named keep, the
+//current cache busting copy, is not removed. Only regular files are removed, a symlink is
+//never removed or followed since cache busting copies are never symlinks and the link
+//could point outside of the directory.
+func removeOldCacheBustingFilesFromList(directory string, files []fs.DirEntry, isCopy func(string) bool, keep string) error {
+	//check if each file is an old cache busting file.
+	for _, f := range files {
+		if !f.Type().IsRegular() || f.Name() == keep {
+			continue
 		}
 
-		if r.MatchString(f.Name()) {
+		if isCopy(f.Name()) {
+			//make sure the file wasn't replaced with a symlink since the directory was read.
 			pathToOldFile := filepath.Join(directory, f.Name())
+			info, statErr := os.Lstat(pathToOldFile)
+			if statErr != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
 			removeErr := os.Remove(pathToOldFile)
-			if removeErr != nil {
+			if removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
 				return removeErr
 			}
 		}
@@ -528,20 +1867,22 @@ func (c *Config) FindFileDataByCacheBustURLPath(urlPath string) (b []byte, err e
 		log.Println("cachebusting.FindFileDataByCacheBustURLPath (debug)", urlPath)
 	}
 
-	if !c.UseEmbedded && !c.UseMemory {
-		err = ErrFileNotStoredInMemory
-		return
-	}
-
-	for _, v := range c.StaticFiles {
-		if v.cacheBustURLPath == urlPath {
-			b = v.fileData
+	s, _, found := c.findByCacheBustURLPath(lookupPath(urlPath))
+	if !found {
+		if !c.storesInMemory() {
+			err = &FileError{Path: urlPath, Err: ErrFileNotStoredInMemory}
 			return
 		}
+
+		err = &FileError{Path: urlPath, Err: ErrNotFound}
+		return
+	}
+	if !c.inMemory(s) {
+		err = &FileError{Path: urlPath, Err: ErrFileNotStoredInMemory}
+		return
 	}
 
-	err = ErrNotFound
-	return
+	return c.copyData(s)
 }
 
 //FindFileDataByCacheBustURLPath wraps FindFileDataByCacheBustURLPath for the package level config.
@@ -550,15 +1891,230 @@ func FindFileDataByCacheBustURLPath(path string) (b []byte, err error) {
 }
 
 //GetConfig returns the current state of the package level config.
+//
+//The returned config is the live package level config. Do not modify the fields of the
+//returned config directly while your app is serving requests, use the package level
+//setter funcs (HashLength(), Debug(), etc.) instead. To inspect the config, for example
+//for diagnostics, use Snapshot() instead.
 func GetConfig() *Config {
 	return &config
 }
 
+//Snapshot returns a copy of the package level config. The copy, including the list of
+//static files, can be inspected or modified without affecting the package level config.
+func Snapshot() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return config.copy()
+}
+
+//copy returns a deep copy of the config. The file data of each static file is not copied
+//since the file data is never modified after Create() and can safely be shared.
+func (c *Config) copy() (cp Config) {
+	cp = *c
+	cp.creating = 0
+
+	if c.StaticFiles != nil {
+		cp.StaticFiles = make([]StaticFile, len(c.StaticFiles))
+		for k, s := range c.StaticFiles {
+			s.previousURLPaths = append([]string(nil), s.previousURLPaths...)
+			s.Imports = append([]string(nil), s.Imports...)
+			s.Groups = append([]string(nil), s.Groups...)
+			s.DependsOn = append([]string(nil), s.DependsOn...)
+			s.Aliases = append([]string(nil), s.Aliases...)
+			cp.StaticFiles[k] = s
+		}
+	}
+
+	cp.AssetHosts = append([]string(nil), c.AssetHosts...)
+	cp.BuildInfoExtensions = append([]string(nil), c.BuildInfoExtensions...)
+	return
+}
+
+//Clone returns a deep copy of the config. This is useful for building a new config from an
+//existing one, for example when reloading your configuration, without modifying the config
+//that is currently in use.
+func (c *Config) Clone() *Config {
+	cp := c.copy()
+	return &cp
+}
+
+//Equal returns true if two configs have the same settings and static files. If Create()
+//has been called on both configs, the cache busting results (hashes and paths) of each
+//static file must also be the same. Use this to determine if a new config differs from
+//the config currently in use and thus if Create() needs to be called again.
+//
+//Every setting is compared. Funcs, such as NameFunc and AfterCreate, are only the same if
+//they are the same func. Interfaces, such as FS, Store, and DebugWriter, are only the same
+//if they hold the same comparable value, i.e. the same pointer.
+func (c *Config) Equal(o *Config) bool {
+	if c == nil || o == nil {
+		return c == o
+	}
+
+	if c.Development != o.Development ||
+		c.DevelopmentPassthrough != o.DevelopmentPassthrough ||
+		c.Debug != o.Debug ||
+		c.HashLength != o.HashLength ||
+		c.UseEmbedded != o.UseEmbedded ||
+		c.EmbeddedFS != o.EmbeddedFS ||
+		c.UseMemory != o.UseMemory ||
+		c.MemoryFallback != o.MemoryFallback ||
+		c.DiskSpaceMargin != o.DiskSpaceMargin ||
+		c.TempDir != o.TempDir ||
+		c.MappingFile != o.MappingFile ||
+		!sameFS(c.FS, o.FS) ||
+		c.HistoryLength != o.HistoryLength ||
+		c.HistoryFile != o.HistoryFile ||
+		c.SelfHeal != o.SelfHeal ||
+		c.MaxMemoryBytes != o.MaxMemoryBytes ||
+		c.MemoryCacheBytes != o.MemoryCacheBytes ||
+		c.LowercaseHash != o.LowercaseHash ||
+		c.HashURLPath != o.HashURLPath ||
+		c.HashSalt != o.HashSalt ||
+		c.BasePath != o.BasePath ||
+		c.NoCopy != o.NoCopy ||
+		c.BuildID != o.BuildID ||
+		c.Normalize != o.Normalize ||
+		c.BuildInfo != o.BuildInfo ||
+		c.StreamMinBytes != o.StreamMinBytes ||
+		c.ServeFromEmbedded != o.ServeFromEmbedded ||
+		c.RegisterFonts != o.RegisterFonts ||
+		c.RewriteCSSURLs != o.RewriteCSSURLs ||
+		c.CheckFilesExist != o.CheckFilesExist ||
+		!sameValue(c.Store, o.Store) ||
+		c.PublishCacheControl != o.PublishCacheControl ||
+		c.PublishConcurrency != o.PublishConcurrency ||
+		!sameValue(c.Purger, o.Purger) ||
+		!sameFunc(c.NameFunc, o.NameFunc) ||
+		!sameFunc(c.NameMatch, o.NameMatch) ||
+		!sameFunc(c.AfterCreate, o.AfterCreate) ||
+		!sameValue(c.DebugWriter, o.DebugWriter) ||
+		!equalStrings(c.BuildInfoExtensions, o.BuildInfoExtensions) ||
+		!equalStrings(c.AssetHosts, o.AssetHosts) {
+		return false
+	}
+
+	if len(c.StaticFiles) != len(o.StaticFiles) {
+		return false
+	}
+	for k := range c.StaticFiles {
+		if !c.StaticFiles[k].equal(o.StaticFiles[k]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//equal returns true if two static files have the same paths, settings, and cache busting
+//results.
+func (s StaticFile) equal(o StaticFile) bool {
+	return s.LocalPath == o.LocalPath &&
+		s.URLPath == o.URLPath &&
+		equalStrings(s.Aliases, o.Aliases) &&
+		s.Inline == o.Inline &&
+		s.Critical == o.Critical &&
+		s.Priority == o.Priority &&
+		s.Storage == o.Storage &&
+		equalVariants(s.Variants, o.Variants) &&
+		s.Vendor == o.Vendor &&
+		sameFunc(s.Include, o.Include) &&
+		s.ManifestPlaceholder == o.ManifestPlaceholder &&
+		s.WebAppManifest == o.WebAppManifest &&
+		equalStrings(s.Groups, o.Groups) &&
+		equalStrings(s.DependsOn, o.DependsOn) &&
+		equalStrings(s.Imports, o.Imports) &&
+		s.cacheBustLocalPath == o.cacheBustLocalPath &&
+		s.cacheBustURLPath == o.cacheBustURLPath &&
+		s.hash == o.hash &&
+		s.integrity == o.integrity &&
+		equalStrings(s.previousURLPaths, o.previousURLPaths) &&
+		s.variantOf == o.variantOf &&
+		s.fontOf == o.fontOf &&
+		s.streamed == o.streamed &&
+		s.evicted == o.evicted
+}
+
+//equalVariants returns true if two lists of variants have the same extensions and
+//transform funcs, see sameFunc, in the same order.
+func equalVariants(a, b []Variant) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k := range a {
+		if a[k].Ext != b[k].Ext || !sameFunc(a[k].Transform, b[k].Transform) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//sameFunc returns true if two funcs are both nil or are the same func. Funcs cannot be
+//compared directly so the funcs' code is compared, meaning two closures created by the
+//same func literal are the same even if they captured different variables.
+func sameFunc(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsNil() || vb.IsNil() {
+		return va.IsNil() && vb.IsNil()
+	}
+
+	return va.Pointer() == vb.Pointer()
+}
+
+//sameFS returns true if two filesystems are the same. Filesystems that cannot be compared,
+//such as fstest.MapFS, are only the same if both are nil.
+func sameFS(a, b fs.FS) bool {
+	return sameValue(a, b)
+}
+
+//sameValue returns true if two values stored in interfaces, i.e. filesystems or stores,
+//are the same. Values that cannot be compared are only the same if both are nil.
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+//containsString returns true if the slice contains the string.
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+//equalStrings returns true if two slices contain the same strings in the same order.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+
+	return true
+}
+
 //GetFilenamePairs returns the original to cache busting filename pairs.
 func (c *Config) GetFilenamePairs() (pairs map[string]string) {
 	pairs = make(map[string]string)
 
-	for _, v := range c.StaticFiles {
+	for _, v := range c.servedFiles() {
 		original := filepath.Base(v.LocalPath)
 		cachebust := filepath.Base(v.cacheBustURLPath)
 
@@ -573,90 +2129,107 @@ func GetFilenamePairs() (pairs map[string]string) {
 	return config.GetFilenamePairs()
 }
 
-//StaticFileHandler is an example func that can be used to serve static files whether you
-//are using embedded or on-disk original files and in memory or on disk cache busting files.
-//You would use this func in your http router. This is an example since it requires a strict
-//local directory structure and strict url path to each static file.
-//Notes:
-// - See package level comment about expected directory structure.
-// - Extra headers added for diagnosing where files are stored in browser dev tools.
-// - Set cacheDays to 0 to prevent caching in the user's browser.
-func (c *Config) StaticFileHandler(cacheDays int, pathToStaticFiles string) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		//set header to control caching of file in user's browser
-		//max age is in days
-		//if value is 0, files won't be cached in browser
-		maxAge := cacheDays * 24 * 60 * 60
-		w.Header().Set("Cache-Control", "no-transform,public,max-age="+strconv.Itoa(maxAge))
-
-		//serve the file being requested.
-		//Cache busting files will be stored in the app's memory if the app is using embedded
-		//files or the app is storing cache busting versions of on disk files in memory (i.e.
-		//app is deployed on a system that doesn't allow writing to disk). If the file cannot
-		//be found and served, the file being requested is most likely a vendor file.
-		if c.UseEmbedded || c.UseMemory {
-			//try finding cache busting file in memory.
-			fd, err := FindFileDataByCacheBustURLPath(r.URL.Path)
-			if err == nil {
-				w.Header().Set("X-Static-Served-From", "memory")
-				w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(r.URL.Path)))
-				w.Write(fd)
-				return
-			} else if err != ErrNotFound {
-				log.Println("cachebusting.StaticFileHandler", "odd error serving file from memory", err)
-			}
-		}
+//GetURLPairs returns the original filename to cache busting URL pairs. If AssetHosts
+//is set, the URLs are full URLs including the asset host each file is assigned to,
+//otherwise the URLs are just the cache busting URL paths.
+func (c *Config) GetURLPairs() (pairs map[string]string) {
+	pairs = make(map[string]string)
 
-		//serve files that couldn't be found in app's memory.
-		//This is with a cache busting file saved to disk (default when original static is
-		//stored on disk) or a vendor file. Get the correct list of filesystem based on if
-		//the app is using embedded files or files stored on disk.
-		var httpFS http.FileSystem
-		if c.UseEmbedded {
-			w.Header().Set("X-Static-Served-From", "embedded")
-
-			//dir is equivalent to "/" now. This doesn't work for us because requests
-			//are coming in for files with url paths starting at /static/.
-			//Note: See package level comment about expected directory structure.
-			rootDir := c.EmbeddedFS
-
-			//change to the /website directory. Inside this directory is the static
-			//directory where files are stored. The directory structure now matches the
-			//request path.
-			const dirName = "website"
-			websiteDir, err := fs.Sub(rootDir, dirName)
-			if err != nil {
-				log.Println("cachebusting.StaticFileHandler", "could not find "+dirName+" in embedded files.", err)
-				return
-			}
+	for _, v := range c.servedFiles() {
+		original := filepath.Base(v.LocalPath)
+		pairs[original] = c.cacheBustURL(v)
+	}
 
-			//serve the /website directory where static/... is located
-			httpFS = http.FS(websiteDir)
-		} else {
-			w.Header().Set("X-Static-Served-From", "disk")
+	return
+}
 
-			//This was the old way of serving static files before support for embedded files existed.
-			//os.DirFS opens the "website" directory so that when a path is requested starting with
-			//"static", the directory structure will match the url path.
-			dir := os.DirFS(pathToStaticFiles)
-			httpFS = http.FS(dir)
-		}
+//GetURLPairs returns the URL pairs for the package level config.
+func GetURLPairs() (pairs map[string]string) {
+	return config.GetURLPairs()
+}
 
-		fileserver := http.FileServer(httpFS)
-		fileserver.ServeHTTP(w, r)
+//GetHash returns the full, untruncated, uppercase hex encoded SHA-256 hash of a file's
+//contents given the original file's name. This is the hash of the cache busting copy,
+//which may differ from the original file if the file was modified when read (see
+//Normalize and BuildInfo). Use this for audit logging or to compare against hashes
+//calculated elsewhere, i.e. in CI, to detect tampering. ErrNotHashed is returned if
+//Create() hasn't been called.
+func (c *Config) GetHash(original string) (hash string, err error) {
+	s, found := c.findByOriginalName(original)
+	if !found {
+		err = &FileError{Path: original, Err: ErrNotFound}
 		return
-	})
+	}
+	if s.hash == "" {
+		err = &FileError{Path: original, Err: ErrNotHashed}
+		return
+	}
+
+	return s.hash, nil
+}
+
+//GetHash returns the hash of a file for the package level config.
+func GetHash(original string) (hash string, err error) {
+	return config.GetHash(original)
+}
+
+//CSPHashes returns the Content-Security-Policy hash source expressions, i.e.:
+//'sha256-...', for each static file marked as Inline. Include these in the script-src
+//or style-src directive of your Content-Security-Policy header so that browsers allow
+//the inlined contents to be used. Hashes are only available after Create() is called.
+//Hashes are returned in order of each file's URL path.
+func (c *Config) CSPHashes() (hashes []string) {
+	for _, v := range c.sortedStaticFiles() {
+		if !v.Inline || v.integrity == "" {
+			continue
+		}
+
+		hashes = append(hashes, "'"+v.integrity+"'")
+	}
+
+	return
+}
+
+//CSPHashes returns the CSP hash sources for the package level config.
+func CSPHashes() (hashes []string) {
+	return config.CSPHashes()
+}
+
+//assetHost returns the asset host a static file is served from. The host is chosen by
+//taking the file's hash modulo the number of hosts so that a file is always assigned to
+//the same host for as long as the file's contents do not change. A blank string is
+//returned if no asset hosts are provided.
+func (c *Config) assetHost(s StaticFile) string {
+	if len(c.AssetHosts) == 0 {
+		return ""
+	}
+
+	//use the first few characters of the hash, this is plenty to distribute files
+	//evenly across the hosts.
+	const hashChars = 8
+	if len(s.hash) < hashChars {
+		return strings.TrimSuffix(c.AssetHosts[0], "/")
+	}
+
+	n, err := strconv.ParseUint(s.hash[:hashChars], 16, 64)
+	if err != nil {
+		return strings.TrimSuffix(c.AssetHosts[0], "/")
+	}
+
+	host := c.AssetHosts[n%uint64(len(c.AssetHosts))]
+	return strings.TrimSuffix(host, "/")
 }
 
-//DefaultStaticFileHandler is an example handler for serving static files using the
-//package level saved config.
-func DefaultStaticFileHandler(cacheDays int, pathToStaticFiles string) http.Handler {
-	return config.StaticFileHandler(cacheDays, pathToStaticFiles)
+//cacheBustURL returns the URL a static file's cache busting copy is served on. This is
+//the cache busting URL path prefixed with the file's asset host, if asset hosts are
+//being used.
+func (c *Config) cacheBustURL(s StaticFile) string {
+	return c.assetHost(s) + c.publicURLPath(s.cacheBustURLPath) + c.versionQuery(s)
 }
 
 //PrintEmbeddedFileList prints out the list of files embedded into the executable. This should
 //be used for diagnostics purposes only to confirm which files are embedded with the //go:embed
-//directives elsewhere in your app.
+//directives elsewhere in your app. See ListFiles() to get the list of files instead.
 func PrintEmbeddedFileList(e embed.FS) {
 	//the directory "." means the root directory of the embedded file.
 	const startingDirectory = "."
@@ -678,20 +2251,28 @@ func PrintEmbeddedFileList(e embed.FS) {
 
 //HashLength sets the HashLength field on the package level config.
 func HashLength(l uint) {
+	configMu.Lock()
 	config.HashLength = l
+	configMu.Unlock()
 }
 
 //Development sets the Development field on the package level config.
 func Development(yes bool) {
+	configMu.Lock()
 	config.Development = yes
+	configMu.Unlock()
 }
 
 //Debug sets the Debug field on the package level config.
 func Debug(yes bool) {
+	configMu.Lock()
 	config.Debug = yes
+	configMu.Unlock()
 }
 
 //UseMemory sets the UseMemory field on the package level config.
 func UseMemory(yes bool) {
+	configMu.Lock()
 	config.UseMemory = yes
+	configMu.Unlock()
 }
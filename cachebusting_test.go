@@ -1,12 +1,21 @@
 package cachebusting
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 )
 
 //go:embed _testdata
@@ -94,7 +103,7 @@ func TestValidate(t *testing.T) {
 	//Test with an no static files provided.
 	c := NewOnDiskConfig()
 	err = c.validate()
-	if err != ErrNoFiles {
+	if !errors.Is(err, ErrNoFiles) {
 		t.Fatal("ErrNoFiles should have occured by didn't")
 		return
 	}
@@ -105,7 +114,7 @@ func TestValidate(t *testing.T) {
 	css := NewStaticFile(" ", path.Join("/", "static", "css", "styles.min.css"))
 	c = NewOnDiskConfig(css)
 	err = c.validate()
-	if err != ErrEmptyPath {
+	if !errors.Is(err, ErrEmptyPath) {
 		t.Fatal("ErrEmptyPath should have occured by didn't")
 		return
 	}
@@ -116,7 +125,7 @@ func TestValidate(t *testing.T) {
 	css = NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), " ")
 	c = NewOnDiskConfig(css)
 	err = c.validate()
-	if err != ErrEmptyPath {
+	if !errors.Is(err, ErrEmptyPath) {
 		t.Fatal("ErrEmptyPath should have occured by didn't")
 		return
 	}
@@ -128,12 +137,31 @@ func TestValidate(t *testing.T) {
 	c = NewOnDiskConfig(css)
 	c.HashLength = 3
 	err = c.validate()
-	if err != ErrHashLengthToShort {
+	if !errors.Is(err, ErrHashLengthToShort) {
 		t.Fatal("ErrHashLengthToShort should have occured by didn't")
 		return
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Check if hash length is too long.
+	css = NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	c = NewOnDiskConfig(css)
+	c.HashLength = 65
+	err = c.validate()
+	if !errors.Is(err, ErrHashLengthTooLong) {
+		t.Fatal("ErrHashLengthTooLong should have occured by didn't")
+		return
+	}
+
+	c.HashLength = 64
+	err = c.validate()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
 	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
 	//Check if default hash length was used if hash length was 0.
 	css = NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
@@ -174,7 +202,7 @@ func TestValidate(t *testing.T) {
 	c = NewEmbeddedConfig(embed.FS{}, css)
 	c.HashLength = 0
 	err = c.validate()
-	if err != ErrNoEmbeddedFilesProvided {
+	if !errors.Is(err, ErrNoEmbeddedFilesProvided) {
 		t.Fatal("ErrNoEmbeddedFilesProvided should have occured but didn't")
 		return
 	}
@@ -201,6 +229,52 @@ func TestValidate(t *testing.T) {
 		}
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//All problems are returned at once.
+	css = NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	js := NewStaticFile(filepath.Join(dir, "_testdata", "static", "js", "script.min.js"), path.Join("/", "static", "css", "styles.min.css"))
+	empty := NewStaticFile(" ", path.Join("/", "static", "js", "script.min.js"))
+	c = NewOnDiskConfig(css, css, js, empty)
+	c.HashLength = 3
+	err = c.validate()
+	if !errors.Is(err, ErrDuplicateURLPath) || !errors.Is(err, ErrEmptyPath) || !errors.Is(err, ErrHashLengthToShort) {
+		t.Fatal("All problems should have been returned", err)
+		return
+	}
+	if strings.Count(err.Error(), "\n") != 2 {
+		t.Fatal("Each problem should have been returned once", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing files are reported, each of them, only when CheckFilesExist is true.
+	css = NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	missingA := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "missing-a.css"), path.Join("/", "static", "css", "missing-a.css"))
+	missingB := NewStaticFile(filepath.Join(dir, "_testdata", "static", "js", "missing-b.js"), path.Join("/", "static", "js", "missing-b.js"))
+	c = NewOnDiskConfig(css, missingA, missingB)
+	err = c.Validate()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	c.CheckFilesExist = true
+	err = c.Validate()
+	if !errors.Is(err, ErrFileMissing) || !strings.Contains(err.Error(), "missing-a.css") || !strings.Contains(err.Error(), "missing-b.js") {
+		t.Fatal("Missing files should have been reported", err)
+		return
+	}
+
+	c = NewEmbeddedConfig(embeddedFiles, NewStaticFile("_testdata/static/css/missing.css", "/static/css/missing.css"))
+	c.CheckFilesExist = true
+	err = c.Validate()
+	if !errors.Is(err, ErrFileMissing) {
+		t.Fatal("ErrFileMissing should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 }
 
 func TestCreate(t *testing.T) {
@@ -215,7 +289,7 @@ func TestCreate(t *testing.T) {
 	css := NewStaticFile(" ", path.Join("/", "static", "css", "styles.min.css"))
 	c := NewOnDiskConfig(css)
 	err = c.Create()
-	if err != ErrEmptyPath {
+	if !errors.Is(err, ErrEmptyPath) {
 		t.Fatal("ErrEmptyPath should have occured by didn't")
 		return
 	}
@@ -227,7 +301,7 @@ func TestCreate(t *testing.T) {
 	c = NewOnDiskConfig(css)
 	c.Development = true
 	err = c.Create()
-	if err != ErrNoCacheBustingInDevelopment {
+	if !errors.Is(err, ErrNoCacheBustingInDevelopment) {
 		t.Fatal("ErrNoCacheBustingInDevelopment should have occured by didn't")
 		return
 	}
@@ -343,10 +417,16 @@ func TestFindFileDataByCacheBustURLPath(t *testing.T) {
 	}
 
 	_, err = c.FindFileDataByCacheBustURLPath(css.URLPath + ".old")
-	if err != ErrNotFound {
+	if !errors.Is(err, ErrNotFound) {
 		t.Fatal("ErrNotFound should have occured but didn't")
 		return
 	}
+
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || fileErr.Path != css.URLPath+".old" {
+		t.Fatal("FileError with requested path should have been returned", err)
+		return
+	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 
 	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
@@ -360,7 +440,7 @@ func TestFindFileDataByCacheBustURLPath(t *testing.T) {
 	}
 
 	_, err = c.FindFileDataByCacheBustURLPath(css.URLPath)
-	if err != ErrFileNotStoredInMemory {
+	if !errors.Is(err, ErrFileNotStoredInMemory) {
 		t.Fatal("ErrFileNotStoredInMemory should have occured but didn't")
 		return
 	}
@@ -441,3 +521,1120 @@ func TestDefaultConfig(t *testing.T) {
 	}
 	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 }
+
+func TestGetURLPairs(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Without asset hosts, URLs are just the cache busting URL paths.
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	c := NewOnDiskConfig(css)
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	pairs := c.GetURLPairs()
+	if pairs["styles.min.css"] != c.StaticFiles[0].cacheBustURLPath {
+		t.Fatal("URL pair not set correctly", pairs)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//With asset hosts, URLs include the host and the same host is always chosen.
+	c.AssetHosts = []string{"https://cdn1.example.com/", "https://cdn2.example.com"}
+	pairs = c.GetURLPairs()
+	u := pairs["styles.min.css"]
+	if !strings.HasPrefix(u, "https://cdn") || !strings.HasSuffix(u, c.StaticFiles[0].cacheBustURLPath) {
+		t.Fatal("Sharded URL not built correctly", u)
+		return
+	}
+	if strings.Contains(u, ".com//") {
+		t.Fatal("Trailing slash on asset host not removed", u)
+		return
+	}
+	for i := 0; i < 5; i++ {
+		if c.GetURLPairs()["styles.min.css"] != u {
+			t.Fatal("Asset host not chosen deterministically")
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestGetHash(t *testing.T) {
+	data := []byte("body{}")
+	fsys := fstest.MapFS{
+		"static/css/styles.min.css": {Data: data},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files not hashed yet return an error.
+	_, err := c.GetHash("styles.min.css")
+	if !errors.Is(err, ErrNotHashed) {
+		t.Fatal("ErrNotHashed should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The full hash is returned once the file is hashed.
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	h := sha256.Sum256(data)
+	hash, err := c.GetHash("styles.min.css")
+	if err != nil || hash != strings.ToUpper(hex.EncodeToString(h[:])) {
+		t.Fatal("Hash not returned correctly", hash, err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unknown files return an error.
+	_, err = c.GetHash("missing.css")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("ErrNotFound should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestCSPHashes(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	css.Inline = true
+	js := NewStaticFile(filepath.Join(dir, "_testdata", "static", "js", "script.min.js"), path.Join("/", "static", "js", "script.min.js"))
+	c := NewOnDiskConfig(css, js)
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	hashes := c.CSPHashes()
+	if len(hashes) != 1 {
+		t.Fatal("Only inline files should have CSP hashes", hashes)
+		return
+	}
+
+	//sha256 of an empty file.
+	if hashes[0] != "'sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU='" {
+		t.Fatal("CSP hash not in expected format", hashes[0])
+		return
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	css := NewStaticFile(filepath.Join("_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	DefaultOnDiskConfig(css)
+
+	s := Snapshot()
+	if len(s.StaticFiles) != 1 || s.StaticFiles[0].LocalPath != css.LocalPath {
+		t.Fatal("Snapshot not copied correctly")
+		return
+	}
+
+	//modifying the snapshot should not modify the package level config.
+	s.StaticFiles[0].LocalPath = "modified"
+	s.HashLength = 50
+	c := GetConfig()
+	if c.StaticFiles[0].LocalPath != css.LocalPath {
+		t.Fatal("Package level static files modified via snapshot")
+		return
+	}
+	if c.HashLength == 50 {
+		t.Fatal("Package level config modified via snapshot")
+		return
+	}
+}
+
+func TestCloneAndEqual(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	c := NewOnDiskConfig(css)
+	c.UseMemory = true
+	c.AssetHosts = []string{"https://cdn.example.com"}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Clone is equal to the original.
+	cp := c.Clone()
+	if !c.Equal(cp) {
+		t.Fatal("Clone should be equal to original")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Modifying the clone does not modify the original and configs are no longer equal.
+	cp.StaticFiles[0].URLPath = "/other/styles.min.css"
+	cp.AssetHosts[0] = "https://other.example.com"
+	if c.StaticFiles[0].URLPath == cp.StaticFiles[0].URLPath || c.AssetHosts[0] == cp.AssetHosts[0] {
+		t.Fatal("Clone shares data with original")
+		return
+	}
+	if c.Equal(cp) {
+		t.Fatal("Configs should not be equal")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Configs that name copies differently are not equal.
+	named := c.Clone()
+	named.NameFunc = func(originalName, fullHash string) string { return fullHash + "." + originalName }
+	if c.Equal(named) {
+		t.Fatal("Configs with different NameFunc should not be equal")
+		return
+	}
+	named = c.Clone()
+	named.NameMatch = func(name, originalName string) bool { return false }
+	if c.Equal(named) {
+		t.Fatal("Configs with different NameMatch should not be equal")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Configs with different side effects are not equal.
+	hooked := c.Clone()
+	hooked.AfterCreate = func(files []CreatedFile) error { return nil }
+	if c.Equal(hooked) {
+		t.Fatal("Configs with different AfterCreate should not be equal")
+		return
+	}
+	hooked = c.Clone()
+	hooked.DebugWriter = &strings.Builder{}
+	if c.Equal(hooked) {
+		t.Fatal("Configs with different DebugWriter should not be equal")
+		return
+	}
+	if !hooked.Equal(hooked.Clone()) {
+		t.Fatal("Clone with the same DebugWriter should be equal")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A config that hasn't been created is not equal to a created config.
+	fresh := NewOnDiskConfig(css)
+	fresh.UseMemory = true
+	fresh.AssetHosts = []string{"https://cdn.example.com"}
+	if c.Equal(fresh) {
+		t.Fatal("Configs should not be equal")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestStaticFileEqual(t *testing.T) {
+	include := func() bool { return true }
+	transform := func(b []byte) ([]byte, error) { return b, nil }
+	otherTransform := func(b []byte) ([]byte, error) { return nil, nil }
+	base := func() StaticFile {
+		s := NewStaticFile("static/js/app.js", "/static/js/app.js")
+		s.Variants = []Variant{{Ext: ".br", Transform: transform}}
+		s.Include = include
+		return s
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Static files with the same fields are equal.
+	if !base().equal(base()) {
+		t.Fatal("Static files should be equal")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Changing any field that affects the output makes static files not equal.
+	tests := []struct {
+		name   string
+		modify func(s *StaticFile)
+	}{
+		{"LocalPath", func(s *StaticFile) { s.LocalPath = "static/js/other.js" }},
+		{"URLPath", func(s *StaticFile) { s.URLPath = "/static/js/other.js" }},
+		{"Aliases", func(s *StaticFile) { s.Aliases = []string{"/app.js"} }},
+		{"Inline", func(s *StaticFile) { s.Inline = true }},
+		{"Critical", func(s *StaticFile) { s.Critical = true }},
+		{"Priority", func(s *StaticFile) { s.Priority = true }},
+		{"Storage", func(s *StaticFile) { s.Storage = StorageMemory }},
+		{"Variants ext", func(s *StaticFile) { s.Variants[0].Ext = ".gz" }},
+		{"Variants transform", func(s *StaticFile) { s.Variants[0].Transform = otherTransform }},
+		{"Variants length", func(s *StaticFile) { s.Variants = nil }},
+		{"Vendor", func(s *StaticFile) { s.Vendor = true }},
+		{"Include", func(s *StaticFile) { s.Include = nil }},
+		{"ManifestPlaceholder", func(s *StaticFile) { s.ManifestPlaceholder = "__MANIFEST__" }},
+		{"WebAppManifest", func(s *StaticFile) { s.WebAppManifest = true }},
+		{"Groups", func(s *StaticFile) { s.Groups = []string{"app"} }},
+		{"DependsOn", func(s *StaticFile) { s.DependsOn = []string{"lib.js"} }},
+		{"Imports", func(s *StaticFile) { s.Imports = []string{"lib.js"} }},
+		{"cacheBustLocalPath", func(s *StaticFile) { s.cacheBustLocalPath = "static/js/A1B2C3D4.app.js" }},
+		{"cacheBustURLPath", func(s *StaticFile) { s.cacheBustURLPath = "/static/js/A1B2C3D4.app.js" }},
+		{"hash", func(s *StaticFile) { s.hash = "A1B2C3D4" }},
+		{"integrity", func(s *StaticFile) { s.integrity = "sha256-abc" }},
+		{"previousURLPaths", func(s *StaticFile) { s.previousURLPaths = []string{"/static/js/old.app.js"} }},
+		{"variantOf", func(s *StaticFile) { s.variantOf = "/static/js/app.ts" }},
+		{"fontOf", func(s *StaticFile) { s.fontOf = "/static/css/styles.css" }},
+		{"streamed", func(s *StaticFile) { s.streamed = true }},
+		{"evicted", func(s *StaticFile) { s.evicted = true }},
+	}
+	for _, tt := range tests {
+		s := base()
+		tt.modify(&s)
+		if base().equal(s) || s.equal(base()) {
+			t.Fatal("Static files should not be equal", tt.name)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestResolveCollisions(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Different files with the same truncated hash get longer hashes.
+	a := NewStaticFile(filepath.Join("a", "script.min.js"), "/static/js/script.min.js")
+	a.hash = "AAAAAAAA11111111" + strings.Repeat("0", 48)
+	b := NewStaticFile(filepath.Join("b", "script.min.js"), "/static/js/script.min.js")
+	b.hash = "AAAAAAAA22222222" + strings.Repeat("0", 48)
+	c := NewOnDiskConfig(a, b)
+
+	lengths := []uint{8, 8}
+	err := c.resolveCollisions(lengths)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if lengths[0] != 16 || lengths[1] != 16 {
+		t.Fatal("Hash lengths not extended as expected", lengths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The same file listed twice is not a collision.
+	b.hash = a.hash
+	c = NewOnDiskConfig(a, b)
+
+	lengths = []uint{8, 8}
+	err = c.resolveCollisions(lengths)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if lengths[0] != 8 || lengths[1] != 8 {
+		t.Fatal("Hash lengths should not have changed", lengths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Collisions that cannot be resolved return an error. This cannot happen with real
+	//hashes so fake hashes that only differ past the maximum hash length are used.
+	a.hash = strings.Repeat("A", 64) + "1"
+	b.hash = strings.Repeat("A", 64) + "2"
+	c = NewOnDiskConfig(a, b)
+
+	lengths = []uint{8, 8}
+	err = c.resolveCollisions(lengths)
+	if !errors.Is(err, ErrHashCollision) {
+		t.Fatal("ErrHashCollision should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+//benchmarkFiles creates n static files in a temporary directory for use in benchmarks and
+//allocation tests.
+func benchmarkFiles(b testing.TB, n int) (files []StaticFile) {
+	dir := b.TempDir()
+	for i := 0; i < n; i++ {
+		name := "file" + strconv.Itoa(i) + ".min.js"
+		p := filepath.Join(dir, name)
+		err := os.WriteFile(p, []byte("console.log("+strconv.Itoa(i)+");"), 0644)
+		if err != nil {
+			b.Fatal(err)
+			return
+		}
+
+		files = append(files, NewStaticFile(p, path.Join("/", "static", "js", name)))
+	}
+
+	return
+}
+
+func BenchmarkCreate(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			files := benchmarkFiles(b, n)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c := NewOnDiskConfig(files...)
+				c.UseMemory = true
+				err := c.Create()
+				if err != nil {
+					b.Fatal(err)
+					return
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFindFileDataByCacheBustURLPath(b *testing.B) {
+	files := benchmarkFiles(b, 1000)
+	c := NewOnDiskConfig(files...)
+	c.UseMemory = true
+	err := c.Create()
+	if err != nil {
+		b.Fatal(err)
+		return
+	}
+	u := c.StaticFiles[len(c.StaticFiles)-1].cacheBustURLPath
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, err := c.FindFileDataByCacheBustURLPath(u)
+			if err != nil {
+				b.Fatal(err)
+				return
+			}
+		}
+	})
+}
+
+func TestFindFileDataByCacheBustURLPathAllocs(t *testing.T) {
+	files := benchmarkFiles(t, 10)
+	c := NewOnDiskConfig(files...)
+	c.UseMemory = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	u := c.StaticFiles[len(c.StaticFiles)-1].cacheBustURLPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Looking up a clean path doesn't allocate.
+	allocs := testing.AllocsPerRun(100, func() {
+		_, err = c.FindFileDataByCacheBustURLPath(u)
+	})
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if allocs != 0 {
+		t.Fatal("Lookup should not allocate", allocs)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestUpperHex(t *testing.T) {
+	h := sha256.Sum256([]byte("cachebusting"))
+	if upperHex(h) != strings.ToUpper(hex.EncodeToString(h[:])) {
+		t.Fatal("Hash not encoded correctly", upperHex(h))
+		return
+	}
+}
+
+func TestCleanRequestPath(t *testing.T) {
+	tests := []struct {
+		in      string
+		cleaned string
+		ok      bool
+	}{
+		{"/static/css/styles.min.css", "/static/css/styles.min.css", true},
+		{"static/css/styles.min.css", "/static/css/styles.min.css", true},
+		{"//static//css/./styles.min.css", "/static/css/styles.min.css", true},
+		{"/static/css/", "/static/css/", true},
+		{"/", "/", true},
+		{"", "/", true},
+		{"/static/../../etc/passwd", "", false},
+		{"/static/..\\css/styles.min.css", "", false},
+		{"/static/css/styles.min.css\x00", "", false},
+		{"/static/\xff.css", "", false},
+	}
+
+	for _, tc := range tests {
+		cleaned, ok := cleanRequestPath(tc.in)
+		if cleaned != tc.cleaned || ok != tc.ok {
+			t.Fatal("Path not cleaned as expected", tc.in, cleaned, ok)
+			return
+		}
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	tests := []struct {
+		in     string
+		lookup string
+		clean  bool
+	}{
+		{"/static/css/styles.min.css", "/static/css/styles.min.css", true},
+		{"/", "/", true},
+		{"/static/css/", "/static/css", false},
+		{"//static/css/styles.min.css", "/static/css/styles.min.css", false},
+		{"/static/./css/styles.min.css", "/static/css/styles.min.css", false},
+		{"static/css/styles.min.css", "/static/css/styles.min.css", false},
+		{"/static/été.css", "/static/été.css", false},
+		{"/static/../secret", "/static/../secret", false},
+	}
+
+	for _, tc := range tests {
+		if isCleanPath(tc.in) != tc.clean {
+			t.Fatal("Path not checked as expected", tc.in)
+			return
+		}
+		if lookupPath(tc.in) != tc.lookup {
+			t.Fatal("Path not looked up as expected", tc.in, lookupPath(tc.in))
+			return
+		}
+	}
+}
+
+func TestStaticFileHandlerPaths(t *testing.T) {
+	css := NewStaticFile(path.Join("_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	c := NewEmbeddedConfig(embeddedFiles, css)
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	h := c.StaticFileHandler(1, "")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Duplicate slashes still find the file in memory.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.Replace(c.StaticFiles[0].cacheBustURLPath, "/css/", "//css/", 1), nil))
+	if rec.Header().Get("X-Static-Served-From") != "memory" {
+		t.Fatal("File not served from memory", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A trailing slash still finds the file in memory.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.StaticFiles[0].cacheBustURLPath+"/", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Static-Served-From") != "memory" {
+		t.Fatal("File not served from memory", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Looking up file data normalizes the path as well.
+	for _, u := range []string{
+		strings.Replace(c.StaticFiles[0].cacheBustURLPath, "/css/", "//css/", 1),
+		c.StaticFiles[0].cacheBustURLPath + "/",
+		strings.TrimPrefix(c.StaticFiles[0].cacheBustURLPath, "/"),
+	} {
+		_, err := c.FindFileDataByCacheBustURLPath(u)
+		if err != nil {
+			t.Fatal("File data not found", u, err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Invalid paths are rejected.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.URL.Path = "/static/../../cachebusting.go"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatal("Invalid path not rejected", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestNewFSConfig(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//All files, including nested files, are found and given URL paths.
+	fsys := fstest.MapFS{
+		"website/static/css/styles.min.css":           {Data: []byte("body{}")},
+		"website/static/js/script.min.js":             {Data: []byte("console.log(1);")},
+		"website/static/js/vendor/chart/chart.min.js": {Data: []byte("chart")},
+		"website/templates/index.html":                {Data: []byte("<html></html>")},
+	}
+	c := NewFSConfig(fsys, "website/static", "static")
+	if len(c.StaticFiles) != 3 {
+		t.Fatal("Files not found as expected", c.StaticFiles)
+		return
+	}
+
+	urls := make(map[string]string)
+	for _, s := range c.StaticFiles {
+		urls[s.LocalPath] = s.URLPath
+	}
+	if urls["website/static/js/vendor/chart/chart.min.js"] != "/static/js/vendor/chart/chart.min.js" {
+		t.Fatal("Nested URL path not built correctly", urls)
+		return
+	}
+
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	for _, s := range c.StaticFiles {
+		b, err := c.FindFileDataByCacheBustURLPath(s.cacheBustURLPath)
+		if err != nil {
+			t.Fatal("Error occured but should not have", err)
+			return
+		}
+		if string(b) != string(fsys[s.LocalPath].Data) {
+			t.Fatal("File data not stored correctly", s.LocalPath)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Works with embedded files and os.DirFS.
+	c = NewFSConfig(embeddedFiles, "_testdata/static", "/static")
+	if len(c.StaticFiles) == 0 {
+		t.Fatal("Embedded files not found")
+		return
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	c = NewFSConfig(os.DirFS("_testdata"), ".", "/")
+	if len(c.StaticFiles) == 0 {
+		t.Fatal("On disk files not found")
+		return
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A missing root directory returns an error on Create().
+	c = NewFSConfig(fsys, "missing", "/static")
+	err = c.Create()
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("fs.ErrNotExist should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestStaticFileHandlerNestedFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"website/static/css/styles.min.css":           {Data: []byte("body{}")},
+		"website/static/js/vendor/chart/chart.min.js": {Data: []byte("chart")},
+	}
+	c := NewFSConfig(fsys, "website/static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	h := c.StaticFileHandler(1, "")
+
+	var nested StaticFile
+	for _, s := range c.StaticFiles {
+		if strings.HasSuffix(s.LocalPath, "chart.min.js") {
+			nested = s
+		}
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nested cache busting file is served from memory.
+	if !strings.HasPrefix(nested.cacheBustURLPath, "/static/js/vendor/chart/") {
+		t.Fatal("Nested cache busting URL path not built correctly", nested.cacheBustURLPath)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, nested.cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "chart" || rec.Header().Get("X-Static-Served-From") != "memory" {
+		t.Fatal("Nested cache busting file not served as expected", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nested original file is served from the filesystem.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/js/vendor/chart/chart.min.js", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "chart" || rec.Header().Get("X-Static-Served-From") != "fs" {
+		t.Fatal("Nested original file not served as expected", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Requests outside of the URL prefix are not found.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/staticfiles/css/styles.min.css", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatal("Request outside URL prefix should not be found", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMaxMemoryBytes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/small.css": {Data: []byte("body{}")},
+		"static/large.js":  {Data: []byte(strings.Repeat("a", 100))},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files within budget are stored.
+	c := NewFSConfig(fsys, "static", "/static")
+	c.MaxMemoryBytes = 1000
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files over budget return an error listing the largest file first.
+	c = NewFSConfig(fsys, "static", "/static")
+	c.MaxMemoryBytes = 50
+	err = c.Create()
+	if !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Fatal("ErrMemoryBudgetExceeded should have occured but didn't", err)
+		return
+	}
+	if !strings.Contains(err.Error(), "files: static/large.js (100 bytes), static/small.css (6 bytes)") {
+		t.Fatal("File sizes not listed as expected", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMixedStorage(t *testing.T) {
+	dir := t.TempDir()
+	jsDir := filepath.Join(dir, "static", "js")
+	err := os.MkdirAll(jsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	for _, name := range []string{"small.js", "large.js"} {
+		err = os.WriteFile(filepath.Join(jsDir, name), []byte(name), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+
+	small := NewStaticFile(filepath.Join(jsDir, "small.js"), "/static/js/small.js")
+	small.Storage = StorageMemory
+	large := NewStaticFile(filepath.Join(jsDir, "large.js"), "/static/js/large.js")
+	c := NewOnDiskConfig(small, large)
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Each file is stored and served according to its storage.
+	if c.StaticFiles[0].fileData == nil {
+		t.Fatal("Small file should be stored in memory")
+		return
+	}
+	if _, err := os.Stat(c.StaticFiles[1].cacheBustLocalPath); err != nil || c.StaticFiles[1].fileData != nil {
+		t.Fatal("Large file should be stored on disk", err)
+		return
+	}
+
+	h := c.StaticFileHandler(1, dir)
+	for k, from := range []string{"memory", "disk"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.StaticFiles[k].cacheBustURLPath, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("X-Static-Served-From") != from {
+			t.Fatal("File not served as expected", c.StaticFiles[k].URLPath, rec.Code, rec.Header())
+			return
+		}
+	}
+
+	_, err = c.FindFileDataByCacheBustURLPath(c.StaticFiles[1].cacheBustURLPath)
+	if !errors.Is(err, ErrFileNotStoredInMemory) {
+		t.Fatal("ErrFileNotStoredInMemory should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Disk storage isn't available for files read from a filesystem.
+	fsys := fstest.MapFS{"static/large.js": {Data: []byte("large")}}
+	f := NewStaticFile("static/large.js", "/static/large.js")
+	f.Storage = StorageDisk
+	c = NewConfig()
+	c.FS = fsys
+	c.StaticFiles = []StaticFile{f}
+	err = c.Create()
+	if !errors.Is(err, ErrDiskStorageUnavailable) {
+		t.Fatal("ErrDiskStorageUnavailable should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestDevelopmentPassthrough(t *testing.T) {
+	fsys := fstest.MapFS{
+		"website/static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "website/static", "/static")
+	c.Development = true
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Development without passthrough still returns an error.
+	err := c.Create()
+	if !errors.Is(err, ErrNoCacheBustingInDevelopment) {
+		t.Fatal("ErrNoCacheBustingInDevelopment should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Passthrough maps each file to itself and serves the original.
+	c.DevelopmentPassthrough = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	pairs := c.GetURLPairs()
+	if pairs["script.min.js"] != "/static/js/script.min.js" {
+		t.Fatal("Identity mapping not created", pairs)
+		return
+	}
+
+	fsys["website/static/js/script.min.js"] = &fstest.MapFile{Data: []byte("console.log(2);")}
+	rec := httptest.NewRecorder()
+	c.StaticFileHandler(0, "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/js/script.min.js", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(2);" {
+		t.Fatal("Original file not served", rec.Code, rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestCreateInProgress(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Create() returns an error while another Create() is running.
+	c.creating = 1
+	err := c.Create()
+	if !errors.Is(err, ErrCreateInProgress) {
+		t.Fatal("ErrCreateInProgress should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Concurrent calls either succeed or return ErrCreateInProgress, and the guard is
+	//released after each call.
+	c.creating = 0
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Create()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrCreateInProgress) {
+			t.Fatal("Unexpected error", err)
+			return
+		}
+	}
+	if len(c.StaticFiles) != 1 || c.creating != 0 {
+		t.Fatal("Static files corrupted or guard not released", len(c.StaticFiles), c.creating)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestLowercaseHash(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	uppercase := c.StaticFiles[0].cacheBustLocalPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Lowercase hash is used and the old uppercase copy is removed.
+	c.LowercaseHash = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	name := filepath.Base(c.StaticFiles[0].cacheBustLocalPath)
+	if name != strings.ToLower(filepath.Base(uppercase)) || name == filepath.Base(uppercase) {
+		t.Fatal("Lowercase hash not used", name)
+		return
+	}
+	if !strings.HasSuffix(c.StaticFiles[0].cacheBustURLPath, "/"+name) {
+		t.Fatal("URL path doesn't use lowercase hash", c.StaticFiles[0].cacheBustURLPath)
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 2 {
+		t.Fatal("Old uppercase copy not removed", entries, err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestWithoutHashedCopies(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies are removed only if the original is registered in the same directory.
+	fsys := fstest.MapFS{
+		"static/css/styles.min.css":          {Data: []byte("body{}")},
+		"static/css/E3B0C442.styles.min.css": {Data: []byte("body{}")},
+		"static/js/DEADBEEF.other.js":        {Data: []byte("other")},
+		"static/js/e3b0c442.script.min.js":   {Data: []byte("old")},
+		"static/js/script.min.js":            {Data: []byte("new")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+
+	var urlPaths []string
+	for _, s := range c.StaticFiles {
+		urlPaths = append(urlPaths, s.URLPath)
+	}
+	expected := []string{"/static/css/styles.min.css", "/static/js/DEADBEEF.other.js", "/static/js/script.min.js"}
+	if strings.Join(urlPaths, ",") != strings.Join(expected, ",") {
+		t.Fatal("Copies not skipped as expected", urlPaths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Manually registered copies are skipped by Create().
+	c.StaticFiles = append(c.StaticFiles, NewStaticFile("static/css/E3B0C442.styles.min.css", "/static/css/E3B0C442.styles.min.css"))
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(c.StaticFiles) != 3 {
+		t.Fatal("Copy registered manually should have been skipped", c.StaticFiles)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestCreateRollback(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "styles.min.css")
+	err := os.WriteFile(p, []byte("body{}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/css/styles.min.css"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	oldLocalPath := c.StaticFiles[0].cacheBustLocalPath
+	oldURLPath := c.StaticFiles[0].cacheBustURLPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A failed Create() removes the copies it saved, keeps the old copies, and leaves the
+	//static files as they were.
+	err = os.WriteFile(p, []byte("body{color:red}"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c.HistoryLength = 1
+	c.HistoryFile = filepath.Join(dir, "missing", "history.json")
+	err = c.Create()
+	if err == nil {
+		t.Fatal("Error should have occured but didn't")
+		return
+	}
+
+	if c.StaticFiles[0].cacheBustLocalPath != oldLocalPath || c.StaticFiles[0].cacheBustURLPath != oldURLPath {
+		t.Fatal("Static files not restored", c.StaticFiles[0].cacheBustURLPath)
+		return
+	}
+	if _, statErr := os.Stat(oldLocalPath); statErr != nil {
+		t.Fatal("Old copy should not have been removed", statErr)
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(entries) != 2 {
+		t.Fatal("New copy should have been removed", len(entries))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A successful Create() removes the old copy once the new copy is saved.
+	c.HistoryFile = ""
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if _, statErr := os.Stat(oldLocalPath); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatal("Old copy should have been removed", statErr)
+		return
+	}
+	if _, statErr := os.Stat(c.StaticFiles[0].cacheBustLocalPath); statErr != nil {
+		t.Fatal("New copy should exist", statErr)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestNameFunc(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//name-v.HASH8.ext
+	nameFunc := func(originalName, fullHash string) string {
+		ext := path.Ext(originalName)
+		return strings.TrimSuffix(originalName, ext) + "-v." + fullHash[:8] + ext
+	}
+	nameMatch := func(name, originalName string) bool {
+		ext := path.Ext(originalName)
+		prefix := strings.TrimSuffix(originalName, ext) + "-v."
+		return strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) && len(name) == len(prefix)+8+len(ext)
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies are named using NameFunc.
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.NameFunc = nameFunc
+	c.NameMatch = nameMatch
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	s := c.StaticFiles[0]
+	expected := "script.min-v." + s.hash[:8] + ".js"
+	if filepath.Base(s.cacheBustLocalPath) != expected || s.cacheBustURLPath != "/static/js/"+expected {
+		t.Fatal("Copy not named using NameFunc", s.cacheBustLocalPath, s.cacheBustURLPath)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Old copies are found using NameMatch and removed.
+	oldCopy := s.cacheBustLocalPath
+	err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if _, statErr := os.Stat(oldCopy); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatal("Old copy should have been removed", statErr)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies registered as static files are skipped using NameMatch.
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":            {Data: []byte("new")},
+		"static/js/script.min-v.DEADBEEF.js": {Data: []byte("old")},
+	}
+	fc := NewFSConfig(fsys, "static", "/static")
+	fc.NameFunc = nameFunc
+	fc.NameMatch = nameMatch
+	err = fc.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(fc.StaticFiles) != 1 || fc.StaticFiles[0].URLPath != "/static/js/script.min.js" {
+		t.Fatal("Registered copy should have been skipped", len(fc.StaticFiles))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,116 @@
+/*
+Package cachebustingqtc provides the cachebusting template helpers for use with
+quicktemplate (github.com/valyala/quicktemplate) templates, which can't use an html/template
+FuncMap. This package doesn't import quicktemplate; the helpers return strings.
+
+For example, build the helpers once after calling Create():
+
+	var assets = cachebustingqtc.New(c)
+
+Then, in your .qtpl files, output elements unescaped with {%s= %} and URLs escaped with
+{%s %}:
+
+	<head>
+		{%s= assets.StyleTag("styles.min.css") %}
+		<link rel="icon" href="{%s assets.AssetURL("favicon.ico") %}">
+		{%s= assets.ScriptTag("script.min.js") %}
+	</head>
+
+Elements for files that aren't found are output as a blank string. Use the methods of
+cachebusting.Config directly to handle the error instead.
+*/
+package cachebustingqtc
+
+import (
+	"html/template"
+
+	"github.com/c9845/cachebusting"
+)
+
+//Helpers provides the cachebusting template helpers for a config.
+type Helpers struct {
+	c *cachebusting.Config
+}
+
+//New returns the helpers for a config. If c is nil, the package level config is used.
+func New(c *cachebusting.Config) Helpers {
+	return Helpers{c: c}
+}
+
+//config returns the config to use.
+func (h Helpers) config() *cachebusting.Config {
+	if h.c == nil {
+		return cachebusting.GetConfig()
+	}
+
+	return h.c
+}
+
+//html returns the HTML built by a cachebusting helper, or a blank string if an error
+//occured.
+func html(t template.HTML, err error) string {
+	if err != nil {
+		return ""
+	}
+
+	return string(t)
+}
+
+//AssetURL returns the URL to use for a file given the original file's name. See
+//cachebusting.Config.AssetURL().
+func (h Helpers) AssetURL(original string) string {
+	return h.config().AssetURL(original)
+}
+
+//SpriteURL returns the URL to use for an icon in an SVG sprite. See
+//cachebusting.Config.SpriteURL().
+func (h Helpers) SpriteURL(originalWithFragment string) string {
+	return h.config().SpriteURL(originalWithFragment)
+}
+
+//IntegrityPairs returns the original filename to subresource integrity value pairs. See
+//cachebusting.Config.GetIntegrityPairs().
+func (h Helpers) IntegrityPairs() map[string]string {
+	return h.config().GetIntegrityPairs()
+}
+
+//ScriptTag returns a <script> element for a file. See cachebusting.Config.ScriptTag().
+func (h Helpers) ScriptTag(original string) string {
+	return html(h.config().ScriptTag(original))
+}
+
+//StyleTag returns a <link rel="stylesheet"> element for a file. See
+//cachebusting.Config.StyleTag().
+func (h Helpers) StyleTag(original string) string {
+	return html(h.config().StyleTag(original))
+}
+
+//PreloadTags returns <link rel="preload"> elements for Critical files. See
+//cachebusting.Config.PreloadTags().
+func (h Helpers) PreloadTags() string {
+	return string(h.config().PreloadTags())
+}
+
+//PictureTag returns a <picture> element for an image and its variants. See
+//cachebusting.Config.PictureTag().
+func (h Helpers) PictureTag(original, alt string) string {
+	return html(h.config().PictureTag(original, alt))
+}
+
+//FaviconTags returns <link> elements for the favicon and web app manifest. See
+//cachebusting.Config.FaviconTags().
+func (h Helpers) FaviconTags() string {
+	return string(h.config().FaviconTags())
+}
+
+//ModulePreloadTags returns <link rel="modulepreload"> elements for an entry module and its
+//imports. See cachebusting.Config.ModulePreloadTags().
+func (h Helpers) ModulePreloadTags(entry string) string {
+	return html(h.config().ModulePreloadTags(entry))
+}
+
+//GroupTags returns the <script> and <link rel="stylesheet"> elements for a group of files.
+//See cachebusting.Config.GroupTags().
+func (h Helpers) GroupTags(name string) string {
+	return string(h.config().GroupTags(name))
+}
@@ -0,0 +1,38 @@
+package cachebustingqtc
+
+import (
+	"testing"
+
+	"github.com/c9845/cachebusting/cachebustingtest"
+)
+
+func TestHelpers(t *testing.T) {
+	c := cachebustingtest.NewConfig(t, "/static", map[string]string{
+		"css/styles.min.css": "body{}",
+		"js/script.min.js":   "console.log(1);",
+	})
+	h := New(c)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Helpers return the same elements as the html/template funcs.
+	expected, _ := c.StyleTag("styles.min.css")
+	if h.StyleTag("styles.min.css") != string(expected) {
+		t.Fatal("Element not as expected", h.StyleTag("styles.min.css"))
+		return
+	}
+	cachebustingtest.AssertCacheBusted(t, c, h.ScriptTag("script.min.js"))
+
+	if h.AssetURL("script.min.js") != c.AssetURL("script.min.js") {
+		t.Fatal("URL not as expected", h.AssetURL("script.min.js"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Elements for missing files are blank.
+	if h.ScriptTag("missing.js") != "" {
+		t.Fatal("Element should be blank", h.ScriptTag("missing.js"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
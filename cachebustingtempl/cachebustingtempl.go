@@ -0,0 +1,131 @@
+/*
+Package cachebustingtempl provides the cachebusting template helpers for use with templ
+(github.com/a-h/templ) templates, which can't use an html/template FuncMap. This package
+doesn't import templ; the components returned satisfy templ.Component.
+
+For example, build the helpers once after calling Create():
+
+	var assets = cachebustingtempl.New(c)
+
+Then, in your .templ files:
+
+	<head>
+		@assets.StyleTag("styles.min.css")
+		<link rel="icon" href={ assets.AssetURL("favicon.ico") }/>
+		@assets.ScriptTag("script.min.js")
+	</head>
+*/
+package cachebustingtempl
+
+import (
+	"context"
+	"html/template"
+	"io"
+
+	"github.com/c9845/cachebusting"
+)
+
+//Component is an element, or elements, rendered by a templ template. This satisfies
+//templ.Component.
+type Component interface {
+	Render(ctx context.Context, w io.Writer) error
+}
+
+//html is a Component that writes HTML built by a cachebusting helper. The helper's error,
+//i.e. the file not being found, is returned when rendering.
+type html struct {
+	h   template.HTML
+	err error
+}
+
+//Render writes the HTML.
+func (c html) Render(ctx context.Context, w io.Writer) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	_, err := io.WriteString(w, string(c.h))
+	return err
+}
+
+//Helpers provides the cachebusting template helpers for a config.
+type Helpers struct {
+	c *cachebusting.Config
+}
+
+//New returns the helpers for a config. If c is nil, the package level config is used.
+func New(c *cachebusting.Config) Helpers {
+	return Helpers{c: c}
+}
+
+//config returns the config to use.
+func (h Helpers) config() *cachebusting.Config {
+	if h.c == nil {
+		return cachebusting.GetConfig()
+	}
+
+	return h.c
+}
+
+//AssetURL returns the URL to use for a file given the original file's name. See
+//cachebusting.Config.AssetURL().
+func (h Helpers) AssetURL(original string) string {
+	return h.config().AssetURL(original)
+}
+
+//SpriteURL returns the URL to use for an icon in an SVG sprite. See
+//cachebusting.Config.SpriteURL().
+func (h Helpers) SpriteURL(originalWithFragment string) string {
+	return h.config().SpriteURL(originalWithFragment)
+}
+
+//IntegrityPairs returns the original filename to subresource integrity value pairs. See
+//cachebusting.Config.GetIntegrityPairs().
+func (h Helpers) IntegrityPairs() map[string]string {
+	return h.config().GetIntegrityPairs()
+}
+
+//ScriptTag returns a <script> element for a file. See cachebusting.Config.ScriptTag().
+func (h Helpers) ScriptTag(original string) Component {
+	t, err := h.config().ScriptTag(original)
+	return html{h: t, err: err}
+}
+
+//StyleTag returns a <link rel="stylesheet"> element for a file. See
+//cachebusting.Config.StyleTag().
+func (h Helpers) StyleTag(original string) Component {
+	t, err := h.config().StyleTag(original)
+	return html{h: t, err: err}
+}
+
+//PreloadTags returns <link rel="preload"> elements for Critical files. See
+//cachebusting.Config.PreloadTags().
+func (h Helpers) PreloadTags() Component {
+	return html{h: h.config().PreloadTags()}
+}
+
+//PictureTag returns a <picture> element for an image and its variants. See
+//cachebusting.Config.PictureTag().
+func (h Helpers) PictureTag(original, alt string) Component {
+	t, err := h.config().PictureTag(original, alt)
+	return html{h: t, err: err}
+}
+
+//FaviconTags returns <link> elements for the favicon and web app manifest. See
+//cachebusting.Config.FaviconTags().
+func (h Helpers) FaviconTags() Component {
+	return html{h: h.config().FaviconTags()}
+}
+
+//ModulePreloadTags returns <link rel="modulepreload"> elements for an entry module and its
+//imports. See cachebusting.Config.ModulePreloadTags().
+func (h Helpers) ModulePreloadTags(entry string) Component {
+	t, err := h.config().ModulePreloadTags(entry)
+	return html{h: t, err: err}
+}
+
+//GroupTags returns the <script> and <link rel="stylesheet"> elements for a group of files.
+//See cachebusting.Config.GroupTags().
+func (h Helpers) GroupTags(name string) Component {
+	return html{h: h.config().GroupTags(name)}
+}
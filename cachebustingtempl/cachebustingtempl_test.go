@@ -0,0 +1,50 @@
+package cachebustingtempl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/c9845/cachebusting"
+	"github.com/c9845/cachebusting/cachebustingtest"
+)
+
+func TestHelpers(t *testing.T) {
+	c := cachebustingtest.NewConfig(t, "/static", map[string]string{
+		"css/styles.min.css": "body{}",
+		"js/script.min.js":   "console.log(1);",
+	})
+	h := New(c)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Components render the same elements as the html/template funcs.
+	var b strings.Builder
+	err := h.ScriptTag("script.min.js").Render(context.Background(), &b)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	expected, _ := c.ScriptTag("script.min.js")
+	if b.String() != string(expected) {
+		t.Fatal("Element not as expected", b.String())
+		return
+	}
+	cachebustingtest.AssertCacheBusted(t, c, b.String())
+
+	if h.AssetURL("styles.min.css") != c.AssetURL("styles.min.css") {
+		t.Fatal("URL not as expected", h.AssetURL("styles.min.css"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Errors are returned when rendering.
+	b.Reset()
+	err = h.StyleTag("missing.css").Render(context.Background(), &b)
+	if !errors.Is(err, cachebusting.ErrNotFound) || b.Len() != 0 {
+		t.Fatal("Error should have been returned", err, b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,158 @@
+/*
+Package cachebustingtest provides helpers for testing your app's usage of the cachebusting
+package. This lets you build a temporary directory of static files, create the cache busting
+files, and check that your templates output the cache busting URLs without needing to copy
+test fixtures into your app.
+
+For example:
+
+	func TestLayout(t *testing.T) {
+		c := cachebustingtest.NewConfig(t, "/static", map[string]string{
+			"css/styles.min.css": "body{}",
+			"js/script.min.js":   "console.log(1);",
+		})
+
+		out := cachebustingtest.ExecuteTemplate(t, c, `{{styleTag "styles.min.css"}}`, nil)
+		cachebustingtest.AssertCacheBusted(t, c, out)
+	}
+*/
+package cachebustingtest
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/c9845/cachebusting"
+)
+
+//NewAssetTree creates a temporary directory containing the given files. The keys of files
+//are the paths to each file, using forward slashes, relative to the directory and the
+//values are the contents of each file. The path to the directory is returned. The
+//directory is removed when the test completes.
+func NewAssetTree(t testing.TB, files map[string]string) (dir string) {
+	t.Helper()
+
+	dir = t.TempDir()
+	for p, contents := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(p))
+
+		err := os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err != nil {
+			t.Fatal("cachebustingtest: could not create directory", err)
+			return
+		}
+
+		err = os.WriteFile(fullPath, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal("cachebustingtest: could not create file", err)
+			return
+		}
+	}
+
+	return
+}
+
+//NewConfig creates a temporary directory containing the given files, see NewAssetTree,
+//builds a config with each file served under urlPrefix, and calls Create(). The cache
+//busting files are stored in memory.
+func NewConfig(t testing.TB, urlPrefix string, files map[string]string) *cachebusting.Config {
+	t.Helper()
+
+	dir := NewAssetTree(t, files)
+
+	//sort the files so the order of the static files is consistent.
+	var paths []string
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var staticFiles []cachebusting.StaticFile
+	for _, p := range paths {
+		staticFiles = append(staticFiles, cachebusting.NewStaticFile(filepath.Join(dir, filepath.FromSlash(p)), path.Join("/", urlPrefix, p)))
+	}
+
+	c := cachebusting.NewOnDiskConfig(staticFiles...)
+	c.UseMemory = true
+	Create(t, c)
+
+	return c
+}
+
+//Create calls Create() on the config and fails the test if an error occurs.
+func Create(t testing.TB, c *cachebusting.Config) {
+	t.Helper()
+
+	err := c.Create()
+	if err != nil {
+		t.Fatal("cachebustingtest: could not create cache busting files", err)
+		return
+	}
+}
+
+//ExecuteTemplate parses the template text, with the config's template funcs available, and
+//executes the template with the provided data. The output is returned.
+func ExecuteTemplate(t testing.TB, c *cachebusting.Config, text string, data interface{}) string {
+	t.Helper()
+
+	tmpl, err := template.New("").Funcs(c.FuncMap()).Parse(text)
+	if err != nil {
+		t.Fatal("cachebustingtest: could not parse template", err)
+		return ""
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, data)
+	if err != nil {
+		t.Fatal("cachebustingtest: could not execute template", err)
+		return ""
+	}
+
+	return b.String()
+}
+
+//AssertCacheBusted fails the test if the output references the original URL path of any of
+//the config's static files rather than the cache busting URL, or if none of the cache
+//busting URLs are found in the output.
+func AssertCacheBusted(t testing.TB, c *cachebusting.Config, output string) {
+	t.Helper()
+
+	found := false
+	for _, s := range c.StaticFiles {
+		if strings.Contains(output, `"`+s.URLPath+`"`) {
+			t.Errorf("cachebustingtest: original URL path %s found in output", s.URLPath)
+		}
+	}
+
+	for _, u := range c.GetURLPairs() {
+		if strings.Contains(output, u) {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("cachebustingtest: no cache busting URLs found in output:\n%s", output)
+	}
+}
+
+//AssertContainsCacheBusted fails the test if the output does not contain the cache busting
+//URL for the file with the given original name.
+func AssertContainsCacheBusted(t testing.TB, c *cachebusting.Config, output, original string) {
+	t.Helper()
+
+	u, ok := c.GetURLPairs()[original]
+	if !ok {
+		t.Errorf("cachebustingtest: %s is not a static file", original)
+		return
+	}
+
+	if !strings.Contains(output, u) {
+		t.Errorf("cachebustingtest: cache busting URL %s for %s not found in output:\n%s", u, original, output)
+	}
+}
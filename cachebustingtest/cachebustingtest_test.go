@@ -0,0 +1,45 @@
+package cachebustingtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewAssetTree(t *testing.T) {
+	dir := NewAssetTree(t, map[string]string{
+		"css/styles.min.css": "body{}",
+	})
+
+	b, err := os.ReadFile(filepath.Join(dir, "css", "styles.min.css"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if string(b) != "body{}" {
+		t.Fatal("File contents not written correctly", string(b))
+		return
+	}
+}
+
+func TestNewConfigAndAssert(t *testing.T) {
+	c := NewConfig(t, "/static", map[string]string{
+		"css/styles.min.css": "body{}",
+		"js/script.min.js":   "console.log(1);",
+	})
+	if len(c.StaticFiles) != 2 || c.StaticFiles[0].URLPath != "/static/css/styles.min.css" {
+		t.Fatal("Config not built correctly", c.StaticFiles)
+		return
+	}
+
+	out := ExecuteTemplate(t, c, `{{styleTag "styles.min.css"}}{{scriptTag "script.min.js"}}`, nil)
+	if strings.Contains(out, `"/static/css/styles.min.css"`) {
+		t.Fatal("Template output not cache busted", out)
+		return
+	}
+
+	AssertCacheBusted(t, c, out)
+	AssertContainsCacheBusted(t, c, out, "styles.min.css")
+	AssertContainsCacheBusted(t, c, out, "script.min.js")
+}
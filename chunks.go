@@ -0,0 +1,133 @@
+package cachebusting
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+//maxManifestPasses is the maximum number of times manifests are injected into entry files
+//while waiting for the cache busting URLs to stop changing. URLs only change between
+//passes if an entry file's new hash collides with another file's hash.
+const maxManifestPasses = 4
+
+//ErrURLsNotStable is returned when the cache busting URLs embedded in files, i.e. the
+//mapping injected into an entry file, are still changing after maxManifestPasses. The
+//files would otherwise refer to outdated URLs.
+var ErrURLsNotStable = errors.New("cachebusting: cache busting urls embedded in files did not stop changing")
+
+//AddChunks adds each file in dir, for example the chunks output by a JavaScript bundler,
+//to the config as a Vendor file served on urlPrefix. Chunks are already named using a
+//hash by the bundler so a cache busting copy isn't needed. Use ManifestPlaceholder on the
+//entry file so that the entry file knows the URL of each chunk.
+//
+//If the config uses embedded files or a filesystem, dir is a path in that filesystem,
+//otherwise dir is a directory on disk.
+func (c *Config) AddChunks(dir, urlPrefix string) error {
+	if c.usesFS() {
+		var fsys fs.FS = c.FS
+		if c.UseEmbedded {
+			fsys = c.EmbeddedFS
+		}
+
+		files, err := findFSFiles(fsys, path.Clean(filepath.ToSlash(dir)), urlPrefix)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			f.Vendor = true
+			c.StaticFiles = append(c.StaticFiles, f)
+		}
+
+		return nil
+	}
+
+	//walk the directory as a filesystem so that symlinked directories are followed.
+	return walkFiles(os.DirFS(dir), ".", func(rel string) error {
+		f := NewStaticFile(filepath.Join(dir, filepath.FromSlash(rel)), path.Join("/", urlPrefix, rel))
+		f.Vendor = true
+		c.StaticFiles = append(c.StaticFiles, f)
+		return nil
+	})
+}
+
+//plannedURL returns the URL a static file will be served on given the file's hash length,
+//prior to the cache busting URL path being saved.
+func (c *Config) plannedURL(s StaticFile, hashLength uint) string {
+	if s.Vendor {
+		return c.assetHost(s) + c.publicURLPath(s.URLPath)
+	}
+
+	name := c.cacheBustFilename(s.hash, hashLength, path.Base(filepath.ToSlash(s.LocalPath)))
+	return c.assetHost(s) + c.publicURLPath(path.Join(path.Dir(s.URLPath), name))
+}
+
+//injectManifests replaces the ManifestPlaceholder in each entry file's data with the
+//mapping of each other file's original URL path to its cache busting URL. Since this
+//changes the entry file's hash, collisions are checked again and the mapping rebuilt if a
+//collision changed any URL. ErrURLsNotStable is returned if the mapping is still changing
+//after maxManifestPasses.
+//
+//fileData and hashLengths are in the same order as StaticFiles and are updated in place.
+func (c *Config) injectManifests(fileData [][]byte, hashLengths []uint) error {
+	original := make(map[int][]byte)
+	for k, s := range c.StaticFiles {
+		if s.ManifestPlaceholder != "" {
+			original[k] = fileData[k]
+		}
+	}
+	if len(original) == 0 {
+		return nil
+	}
+
+	var previous []byte
+	for pass := 0; ; pass++ {
+		b, err := manifestMapping(c.StaticFiles, func(k int) string {
+			return c.plannedURL(c.StaticFiles[k], hashLengths[k])
+		})
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(b, previous) {
+			return nil
+		}
+		if pass == maxManifestPasses {
+			return ErrURLsNotStable
+		}
+		previous = b
+
+		for k, data := range original {
+			fileData[k] = bytes.ReplaceAll(data, []byte(c.StaticFiles[k].ManifestPlaceholder), b)
+
+			h := sha256.Sum256(fileData[k])
+			c.StaticFiles[k].hash = c.nameHash(c.StaticFiles[k], h)
+			c.StaticFiles[k].integrity = "sha256-" + base64.StdEncoding.EncodeToString(h[:])
+		}
+
+		err = c.resolveCollisions(hashLengths)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//manifestMapping returns the JSON mapping of the URL path of each file, other than entry
+//files, to the URL returned by urlOf for the file. This replaces the ManifestPlaceholder
+//in entry files, see injectManifests().
+func manifestMapping(files []StaticFile, urlOf func(k int) string) ([]byte, error) {
+	mapping := make(map[string]string, len(files))
+	for k, s := range files {
+		if s.ManifestPlaceholder == "" {
+			mapping[s.URLPath] = urlOf(k)
+		}
+	}
+
+	return json.Marshal(mapping)
+}
@@ -0,0 +1,79 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestChunks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/app.js":                {Data: []byte("const manifest = __ASSET_MANIFEST__;")},
+		"static/js/other.js":              {Data: []byte("console.log(1);")},
+		"build/chunks/chunk-5F3A9C.js":    {Data: []byte("export default 1;")},
+		"build/chunks/nested/chunk-B1.js": {Data: []byte("export default 2;")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k, s := range c.StaticFiles {
+		if s.URLPath == "/static/js/app.js" {
+			c.StaticFiles[k].ManifestPlaceholder = "__ASSET_MANIFEST__"
+		}
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Chunks are added as vendor files.
+	err := c.AddChunks("build/chunks", "/static/chunks")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(c.StaticFiles) != 4 || !c.StaticFiles[2].Vendor {
+		t.Fatal("Chunks not added as expected", c.StaticFiles)
+		return
+	}
+
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Entry file contains the mapping of every other file's cache busting URL.
+	urls := make(map[string]string)
+	var entry StaticFile
+	for _, s := range c.StaticFiles {
+		urls[s.URLPath] = s.cacheBustURLPath
+		if s.ManifestPlaceholder != "" {
+			entry = s
+		}
+	}
+
+	data := string(entry.fileData)
+	if !strings.HasPrefix(data, "const manifest = ") {
+		t.Fatal("Placeholder not replaced", data)
+		return
+	}
+
+	var mapping map[string]string
+	err = json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(data, "const manifest = "), ";")), &mapping)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(mapping) != 3 ||
+		mapping["/static/js/other.js"] != urls["/static/js/other.js"] ||
+		mapping["/static/chunks/nested/chunk-B1.js"] != "/static/chunks/nested/chunk-B1.js" {
+		t.Fatal("Mapping not built correctly", mapping, urls)
+		return
+	}
+
+	//the entry file's hash is of the contents with the mapping.
+	if err := c.checkCopy(entry); err != nil {
+		t.Fatal("Entry file hash doesn't match contents", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,71 @@
+package cachebusting
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//corsMaxAge is the number of seconds browsers can cache the response to a CORS preflight
+//request for.
+const corsMaxAge = 24 * 60 * 60
+
+//allowedOrigin returns the value of the Access-Control-Allow-Origin header for a request's
+//origin, or a blank string if the origin isn't allowed. credentials is true if the request
+//may include credentials, see HandlerOptions.AllowCredentials. Credentials are only ever
+//allowed for origins listed explicitly. An origin allowed by "*" is given "*", and not
+//credentials, since reflecting any origin with credentials would let any site make
+//requests with the user's cookies.
+func allowedOrigin(origin string, opts HandlerOptions) (allowed string, credentials bool) {
+	if origin == "" {
+		return
+	}
+
+	for _, o := range opts.AllowedOrigins {
+		if o != "*" && strings.EqualFold(o, origin) {
+			return origin, opts.AllowCredentials
+		}
+	}
+
+	for _, o := range opts.AllowedOrigins {
+		if o == "*" {
+			return "*", false
+		}
+	}
+
+	return
+}
+
+//setCORSHeaders sets the CORS headers on a response if the request's origin is allowed,
+//see HandlerOptions.AllowedOrigins. True is returned if the request is a preflight request
+//that has been responded to.
+func setCORSHeaders(w http.ResponseWriter, r *http.Request, opts HandlerOptions) (preflight bool) {
+	if len(opts.AllowedOrigins) == 0 {
+		return false
+	}
+
+	//the response depends on the origin so caches must store a response per origin.
+	w.Header().Add("Vary", "Origin")
+
+	allowed, credentials := allowedOrigin(r.Header.Get("Origin"), opts)
+	if allowed != "" {
+		w.Header().Set("Access-Control-Allow-Origin", allowed)
+		if credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+
+	if allowed != "" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		if h := r.Header.Get("Access-Control-Request-Headers"); h != "" {
+			w.Header().Set("Access-Control-Allow-Headers", h)
+		}
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,107 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandlerCORS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/fonts/font.woff2": {Data: []byte("wOF2")},
+		"static/vendor/lib.js":    {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k := range c.StaticFiles {
+		if c.StaticFiles[k].URLPath == "/static/vendor/lib.js" {
+			c.StaticFiles[k].Vendor = true
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	font, _ := c.findByOriginalName("font.woff2")
+
+	h := c.Handler(HandlerOptions{
+		CacheDays:      1,
+		AllowedOrigins: []string{"https://www.example.com"},
+	})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An allowed origin is allowed for copies and original files.
+	for _, p := range []string{font.cacheBustURLPath, "/static/vendor/lib.js"} {
+		req := httptest.NewRequest(http.MethodGet, p, nil)
+		req.Header.Set("Origin", "https://www.example.com")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatal("File not served", p, rec.Code)
+			return
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "https://www.example.com" || rec.Header().Get("Vary") != "Origin" {
+			t.Fatal("CORS headers not set", p, rec.Header())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Other origins are not allowed.
+	req := httptest.NewRequest(http.MethodGet, font.cacheBustURLPath, nil)
+	req.Header.Set("Origin", "https://evil.example.org")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("Origin should not be allowed", rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Preflight requests are responded to.
+	req = httptest.NewRequest(http.MethodOptions, font.cacheBustURLPath, nil)
+	req.Header.Set("Origin", "https://www.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Methods") == "" || rec.Body.Len() != 0 {
+		t.Fatal("Preflight not responded to", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Credentials are allowed for origins listed explicitly.
+	h = c.Handler(HandlerOptions{
+		CacheDays:        1,
+		AllowedOrigins:   []string{"*", "https://cdn.example.com"},
+		AllowCredentials: true,
+	})
+	req = httptest.NewRequest(http.MethodGet, font.cacheBustURLPath, nil)
+	req.Header.Set("Origin", "https://cdn.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://cdn.example.com" || rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatal("CORS headers not set for credentials", rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Any other origin is allowed by "*" without credentials, the origin is not reflected.
+	for _, method := range []string{http.MethodGet, http.MethodOptions} {
+		req = httptest.NewRequest(method, font.cacheBustURLPath, nil)
+		req.Header.Set("Origin", "https://evil.example.org")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Header().Get("Access-Control-Allow-Origin") != "*" || rec.Header().Get("Access-Control-Allow-Credentials") != "" {
+			t.Fatal("Credentials allowed for any origin", method, rec.Header())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,127 @@
+package cachebusting
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//cssURLRe matches a url() in a CSS file. The quote used, if any, is the first group and the
+//URL is the second group.
+var cssURLRe = regexp.MustCompile(`(?i)url\(\s*(['"]?)([^'")]+)['"]?\s*\)`)
+
+//isCSS returns true if the static file is a CSS file whose URLs may be rewritten.
+func isCSS(s StaticFile) bool {
+	return !s.Vendor && strings.ToLower(path.Ext(filepath.ToSlash(s.LocalPath))) == ".css"
+}
+
+//isLocalCSSURL returns true if a URL in a CSS file may refer to a static file. URLs to
+//other hosts and data URLs are not.
+func isLocalCSSURL(u string) bool {
+	return !(u == "" || strings.Contains(u, "://") || strings.HasPrefix(u, "//") || strings.HasPrefix(u, "data:"))
+}
+
+//resolveCSSURL returns the URL path a URL in a CSS file refers to, resolved relative to
+//the CSS file's URL path as a browser would, and the query and fragment of the URL, i.e.
+//?#iefix, which are kept when the URL is rewritten.
+func resolveCSSURL(cssURLPath, u string) (urlPath, suffix string) {
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u, suffix = u[:i], u[i:]
+	}
+
+	if !strings.HasPrefix(u, "/") {
+		u = path.Join(path.Dir(cssURLPath), u)
+	}
+
+	return decodeURLPath(path.Clean(u)), suffix
+}
+
+//rewriteCSSURLs replaces each URL in each CSS file's data that refers to a static file
+//with the cache busting URL of the file. If RewriteCSSURLs is false, only the URLs in
+//@font-face rules are replaced, see RegisterFonts. The query and fragment of each URL,
+//i.e. the #icon-user of an SVG sprite, are kept. URLs that don't match a static file are
+//left as-is. Since this changes the CSS file's hash, collisions are checked again and the
+//URLs rewritten again if a collision changed any URL. ErrURLsNotStable is returned if the
+//URLs are still changing after maxManifestPasses.
+//
+//fileData and hashLengths are in the same order as StaticFiles and are updated in place.
+func (c *Config) rewriteCSSURLs(fileData [][]byte, hashLengths []uint) error {
+	byURLPath := make(map[string]int, len(c.StaticFiles))
+	original := make(map[int][]byte)
+	for k, s := range c.StaticFiles {
+		byURLPath[s.URLPath] = k
+		if isCSS(s) && fileData[k] != nil {
+			original[k] = fileData[k]
+		}
+	}
+	if len(original) == 0 {
+		return nil
+	}
+
+	previous := make(map[int][]byte, len(original))
+	for pass := 0; ; pass++ {
+		changed := false
+		for k, data := range original {
+			rewritten := c.rewriteCSS(c.StaticFiles, k, data, byURLPath, func(i int) string {
+				return c.plannedURL(c.StaticFiles[i], hashLengths[i])
+			})
+
+			if bytes.Equal(rewritten, previous[k]) {
+				continue
+			}
+			previous[k] = rewritten
+			changed = true
+
+			fileData[k] = rewritten
+			h := sha256.Sum256(rewritten)
+			c.StaticFiles[k].hash = c.nameHash(c.StaticFiles[k], h)
+			c.StaticFiles[k].integrity = "sha256-" + base64.StdEncoding.EncodeToString(h[:])
+		}
+		if !changed {
+			return nil
+		}
+		if pass == maxManifestPasses {
+			return ErrURLsNotStable
+		}
+
+		err := c.resolveCollisions(hashLengths)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//rewriteCSS returns the data of the CSS file at index k in files with each URL that refers
+//to a static file replaced with the URL returned by urlOf for the file, see
+//rewriteCSSURLs(). byURLPath maps each file's URL path to its index in files.
+func (c *Config) rewriteCSS(files []StaticFile, k int, data []byte, byURLPath map[string]int, urlOf func(i int) string) []byte {
+	cssURLPath := files[k].URLPath
+	replace := func(b []byte) []byte {
+		return cssURLRe.ReplaceAllFunc(b, func(m []byte) []byte {
+			sub := cssURLRe.FindSubmatch(m)
+			u := strings.TrimSpace(string(sub[2]))
+			if !isLocalCSSURL(u) {
+				return m
+			}
+
+			urlPath, suffix := resolveCSSURL(cssURLPath, u)
+			i, ok := byURLPath[c.trimBasePath(urlPath)]
+			if !ok || i == k {
+				return m
+			}
+
+			quote := string(sub[1])
+			return []byte("url(" + quote + urlOf(i) + suffix + quote + ")")
+		})
+	}
+
+	if c.RewriteCSSURLs {
+		return replace(data)
+	}
+
+	return fontFaceRe.ReplaceAllFunc(data, replace)
+}
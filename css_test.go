@@ -0,0 +1,67 @@
+package cachebusting
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRewriteCSSURLs(t *testing.T) {
+	css := `.user{background:url('../img/sprite.svg#icon-user')}
+.logo{background:url(/static/img/logo.png?v=1)}
+.remote{background:url(https://example.com/img/logo.png)}
+.missing{background:url(../img/missing.png)}`
+	fsys := fstest.MapFS{
+		"static/css/styles.min.css": {Data: []byte(css)},
+		"static/img/sprite.svg":     {Data: []byte("<svg></svg>")},
+		"static/img/logo.png":       {Data: []byte("logo")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//URLs are not rewritten by default.
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	urls := make(map[string]string)
+	var rewritten string
+	for _, s := range c.StaticFiles {
+		urls[s.URLPath] = s.cacheBustURLPath
+		if s.URLPath == "/static/css/styles.min.css" {
+			rewritten = string(s.fileData)
+		}
+	}
+	if rewritten != css {
+		t.Fatal("URLs should not have been rewritten", rewritten)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//URLs of static files are rewritten keeping the fragment and query.
+	c.RewriteCSSURLs = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	for _, s := range c.StaticFiles {
+		urls[s.URLPath] = s.cacheBustURLPath
+		if s.URLPath == "/static/css/styles.min.css" {
+			rewritten = string(s.fileData)
+		}
+	}
+	if !strings.Contains(rewritten, "url('"+urls["/static/img/sprite.svg"]+"#icon-user')") || !strings.Contains(rewritten, "url("+urls["/static/img/logo.png"]+"?v=1)") {
+		t.Fatal("URLs not rewritten as expected", rewritten)
+		return
+	}
+	if !strings.Contains(rewritten, "url(https://example.com/img/logo.png)") || !strings.Contains(rewritten, "url(../img/missing.png)") {
+		t.Fatal("Other URLs should not have been rewritten", rewritten)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
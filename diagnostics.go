@@ -0,0 +1,191 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+//configJSON is the format a config is output as when marshalled to JSON. This is used
+//instead of the Config itself so that unexported fields are included and so that file
+//data and the embedded filesystem are not output.
+type configJSON struct {
+	Development            bool
+	DevelopmentPassthrough bool
+	Debug                  bool
+	HashLength             uint
+	UseEmbedded            bool
+	UseMemory              bool
+	MemoryFallback         bool
+	DiskSpaceMargin        int64
+	TempDir                string
+	MappingFile            bool
+	AssetHosts             []string
+	BasePath               string
+	HistoryLength          uint
+	HistoryFile            string
+	SelfHeal               bool
+	MaxMemoryBytes         int64
+	MemoryCacheBytes       int64
+	LowercaseHash          bool
+	HashURLPath            bool
+	HashSalt               string
+	NoCopy                 bool
+	BuildID                string
+	Normalize              bool
+	BuildInfo              string
+	StreamMinBytes         int64
+	ServeFromEmbedded      bool
+	RegisterFonts          bool
+	RewriteCSSURLs         bool
+	StaticFiles            []staticFileJSON
+}
+
+//staticFileJSON is the format a static file is output as when marshalled to JSON. The
+//file's data is redacted, only the size of the data is output.
+type staticFileJSON struct {
+	LocalPath          string
+	URLPath            string
+	Aliases            []string
+	CacheBustLocalPath string
+	CacheBustURLPath   string
+	Hash               string
+	Integrity          string
+	InMemory           bool
+	Streamed           bool
+	Size               int
+	Inline             bool
+	Critical           bool
+	Priority           bool
+	Vendor             bool
+}
+
+//MarshalJSON outputs the config as JSON for diagnostics, for example in an admin page or
+//debug endpoint. The data of each file stored in memory is not included. The static files
+//are the ones built by the last call to Create().
+//
+//The config is copied to call this, so don't call this on a config while Create() may be
+//running on it. For the package level config, marshal Snapshot() instead.
+func (c Config) MarshalJSON() ([]byte, error) {
+	files := c.sortedStaticFiles()
+	j := configJSON{
+		Development:            c.Development,
+		DevelopmentPassthrough: c.DevelopmentPassthrough,
+		Debug:                  c.Debug,
+		HashLength:             c.HashLength,
+		UseEmbedded:            c.UseEmbedded,
+		UseMemory:              c.UseMemory,
+		MemoryFallback:         c.MemoryFallback,
+		DiskSpaceMargin:        c.DiskSpaceMargin,
+		TempDir:                c.TempDir,
+		MappingFile:            c.MappingFile,
+		AssetHosts:             c.AssetHosts,
+		BasePath:               c.BasePath,
+		HistoryLength:          c.HistoryLength,
+		HistoryFile:            c.HistoryFile,
+		SelfHeal:               c.SelfHeal,
+		MaxMemoryBytes:         c.MaxMemoryBytes,
+		MemoryCacheBytes:       c.MemoryCacheBytes,
+		LowercaseHash:          c.LowercaseHash,
+		HashURLPath:            c.HashURLPath,
+		HashSalt:               c.HashSalt,
+		NoCopy:                 c.NoCopy,
+		BuildID:                c.BuildID,
+		Normalize:              c.Normalize,
+		BuildInfo:              c.BuildInfo,
+		StreamMinBytes:         c.StreamMinBytes,
+		ServeFromEmbedded:      c.ServeFromEmbedded,
+		RegisterFonts:          c.RegisterFonts,
+		RewriteCSSURLs:         c.RewriteCSSURLs,
+		StaticFiles:            make([]staticFileJSON, 0, len(files)),
+	}
+
+	for _, s := range files {
+		j.StaticFiles = append(j.StaticFiles, staticFileJSON{
+			LocalPath:          s.LocalPath,
+			URLPath:            s.URLPath,
+			Aliases:            s.Aliases,
+			CacheBustLocalPath: s.cacheBustLocalPath,
+			CacheBustURLPath:   s.cacheBustURLPath,
+			Hash:               s.hash,
+			Integrity:          s.integrity,
+			InMemory:           s.fileData != nil || s.evicted,
+			Streamed:           s.streamed,
+			Size:               len(s.fileData),
+			Inline:             s.Inline,
+			Critical:           s.Critical,
+			Priority:           s.Priority,
+			Vendor:             s.Vendor,
+		})
+	}
+
+	return json.Marshal(j)
+}
+
+//String outputs the config as a human readable table for diagnostics and logging. The
+//data of each file stored in memory is not included. The static files are the ones built
+//by the last call to Create().
+//
+//The config is copied to call this, so don't call this on a config while Create() may be
+//running on it. For the package level config, use Snapshot().String() instead.
+func (c Config) String() string {
+	var b strings.Builder
+
+	fmt.Fprintln(&b, "Development:", c.Development)
+	fmt.Fprintln(&b, "HashLength:", c.HashLength)
+	fmt.Fprintln(&b, "UseEmbedded:", c.UseEmbedded)
+	fmt.Fprintln(&b, "UseMemory:", c.UseMemory)
+	if len(c.AssetHosts) > 0 {
+		fmt.Fprintln(&b, "AssetHosts:", strings.Join(c.AssetHosts, ", "))
+	}
+	if c.BasePath != "" {
+		fmt.Fprintln(&b, "BasePath:", c.BasePath)
+	}
+	if c.HistoryLength > 0 {
+		fmt.Fprintln(&b, "HistoryLength:", c.HistoryLength)
+	}
+
+	//tabwriter used to organize output better
+	tw := tabwriter.NewWriter(&b, 0, 4, 1, ' ', tabwriter.Debug)
+	cols := []string{"ORIGINAL FILENAME", "CACHEBUST FILENAME", "ORIGINAL URL PATH", "CACHEBUST URL PATH", "SIZE IN MEMORY"}
+	fmt.Fprintln(tw, strings.Join(cols, "\t"))
+	for _, v := range c.sortedStaticFiles() {
+		cachebustFilename := filepath.Base(v.cacheBustLocalPath)
+		if v.Vendor {
+			cachebustFilename = "(vendor, not cache busted)"
+		}
+
+		cols := []string{
+			filepath.Base(v.LocalPath),
+			cachebustFilename,
+			v.URLPath,
+			v.cacheBustURLPath,
+			strconv.Itoa(len(v.fileData)),
+		}
+		fmt.Fprintln(tw, strings.Join(cols, "\t"))
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
+//sortedStaticFiles returns a copy of the static files sorted by URL path. This is used
+//for output, such as String(), MarshalJSON(), and manifests, so that repeated builds of
+//the same files produce identical output regardless of the order files were provided in.
+//The files are copied from the lookup built by Create(), see servedFiles(), so this can
+//be used while Create() is running.
+func (c *Config) sortedStaticFiles() []StaticFile {
+	served := c.servedFiles()
+	files := make([]StaticFile, len(served))
+	copy(files, served)
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].URLPath < files[j].URLPath
+	})
+
+	return files
+}
@@ -0,0 +1,179 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJSONAndString(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("secret contents"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	js := NewStaticFile(p, "/static/js/script.min.js")
+	c := NewOnDiskConfig(js)
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//JSON includes paths but not file data.
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Fatal("File data should not be included in JSON", string(b))
+		return
+	}
+
+	var j configJSON
+	err = json.Unmarshal(b, &j)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(j.StaticFiles) != 1 || j.StaticFiles[0].CacheBustURLPath != c.StaticFiles[0].cacheBustURLPath || j.StaticFiles[0].Size != len("secret contents") {
+		t.Fatal("JSON not built correctly", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//String includes paths but not file data.
+	str := c.String()
+	if strings.Contains(str, "secret") {
+		t.Fatal("File data should not be included in string", str)
+		return
+	}
+	if !strings.Contains(str, c.StaticFiles[0].cacheBustURLPath) || !strings.Contains(str, "script.min.js") {
+		t.Fatal("String not built correctly", str)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.js", "b.js"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+
+	a := NewStaticFile(filepath.Join(dir, "a.js"), "/static/a.js")
+	b := NewStaticFile(filepath.Join(dir, "b.js"), "/static/b.js")
+
+	c1 := NewOnDiskConfig(a, b)
+	c1.UseMemory = true
+	c2 := NewOnDiskConfig(b, a)
+	c2.UseMemory = true
+	for _, c := range []*Config{c1, c2} {
+		err := c.Create()
+		if err != nil {
+			t.Fatal("Error occured but should not have", err)
+			return
+		}
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Output does not depend on the order files were provided in.
+	if c1.String() != c2.String() {
+		t.Fatal("String output differs", c1.String(), c2.String())
+		return
+	}
+
+	j1, _ := json.Marshal(c1)
+	j2, _ := json.Marshal(c2)
+	if string(j1) != string(j2) {
+		t.Fatal("JSON output differs", string(j1), string(j2))
+		return
+	}
+
+	m1, _ := json.Marshal(c1.Manifest(true))
+	m2, _ := json.Marshal(c2.Manifest(true))
+	if string(m1) != string(m2) {
+		t.Fatal("Manifest differs", string(m1), string(m2))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestDebugWriter(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), "/static/css/styles.min.css")
+	c := NewOnDiskConfig(css)
+	c.UseMemory = true
+	c.Debug = true
+
+	var b strings.Builder
+	c.DebugWriter = &b
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	if !strings.Contains(b.String(), c.StaticFiles[0].cacheBustURLPath) {
+		t.Fatal("Debug output not written to DebugWriter", b.String())
+		return
+	}
+}
+
+func TestMarshalSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("secret contents"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	js := NewStaticFile(p, "/static/js/script.min.js")
+	js.Include = func() bool { return true }
+	DefaultOnDiskConfig(js)
+	GetConfig().UseMemory = true
+	err = Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A snapshot, a Config rather than a *Config, is output without file data.
+	b, err := json.Marshal(Snapshot())
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if strings.Contains(string(b), "secret") || !strings.Contains(string(b), "script.min.js") {
+		t.Fatal("Snapshot JSON not as expected", string(b))
+		return
+	}
+
+	str := fmt.Sprint(Snapshot())
+	if strings.Contains(str, "secret") || !strings.Contains(str, "script.min.js") {
+		t.Fatal("Snapshot string not as expected", str)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
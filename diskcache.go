@@ -0,0 +1,43 @@
+package cachebusting
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"time"
+)
+
+//serveDiskCached serves the cache busting copy of a static file saved to disk from memory,
+//reading the copy from disk and caching it the first time the copy is requested. See
+//HandlerOptions.DiskCacheBytes. Copies too large to cache are not served. True is
+//returned if a response was written.
+func (c *Config) serveDiskCached(w http.ResponseWriter, r *http.Request, s StaticFile, cache *lruCache, charset string) bool {
+	data, ok := cache.get(s.cacheBustLocalPath)
+	if !ok {
+		info, err := os.Stat(s.cacheBustLocalPath)
+		if err != nil || info.Size() > cache.maxBytes {
+			return false
+		}
+
+		data, err = os.ReadFile(s.cacheBustLocalPath)
+		if err != nil {
+			log.Println("cachebusting.StaticFileHandler", "could not read cache busting file to cache", s.cacheBustLocalPath, err)
+			return false
+		}
+
+		//the copy's name includes the hash of its contents so the cached data never needs
+		//to be refreshed, a changed file gets a new name.
+		cache.add(s.cacheBustLocalPath, data)
+	}
+
+	w.Header().Set("X-Static-Served-From", "memory (cached)")
+	setContentType(w, path.Ext(r.URL.Path), charset)
+	if s.hash != "" {
+		w.Header().Set("ETag", etag(s))
+	}
+
+	http.ServeContent(w, r, path.Base(r.URL.Path), time.Time{}, bytes.NewReader(data))
+	return true
+}
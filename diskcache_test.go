@@ -0,0 +1,73 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandlerDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	jsDir := filepath.Join(dir, "static", "js")
+	err := os.MkdirAll(jsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	small := filepath.Join(jsDir, "script.min.js")
+	err = os.WriteFile(small, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	large := filepath.Join(jsDir, "large.js")
+	err = os.WriteFile(large, make([]byte, 100), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(NewStaticFile(small, "/static/js/script.min.js"), NewStaticFile(large, "/static/js/large.js"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	h := c.Handler(HandlerOptions{CacheDays: 1, PathToStaticFiles: dir, DiskCacheBytes: 50})
+	smallCopy, _ := c.findByOriginalName("script.min.js")
+	largeCopy, _ := c.findByOriginalName("large.js")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies are cached in memory on the first request.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, smallCopy.cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1);" || rec.Header().Get("X-Static-Served-From") != "memory (cached)" {
+		t.Fatal("Copy not served as expected", rec.Code, rec.Header(), rec.Body.String())
+		return
+	}
+
+	err = os.Remove(smallCopy.cacheBustLocalPath)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, smallCopy.cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1);" {
+		t.Fatal("Copy not served from memory", rec.Code, rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies larger than the cache are served from disk.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, largeCopy.cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 100 || rec.Header().Get("X-Static-Served-From") != "disk" {
+		t.Fatal("Large copy not served from disk", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,75 @@
+package cachebusting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+//ErrInsufficientDiskSpace is returned when there isn't enough free space on disk to save
+//the cache busting copies, plus DiskSpaceMargin.
+var ErrInsufficientDiskSpace = errors.New("cachebusting: insufficient disk space")
+
+//checkDiskSpace makes sure there is enough free space on disk to save the cache busting
+//copy of each file saved to disk, leaving DiskSpaceMargin bytes free. This is checked prior
+//to saving any copy so that Create() fails with a clear error rather than partially
+//filling the disk. Copies that already exist are not counted. Directories on the same
+//filesystem are counted together. The check is skipped if the free space cannot be
+//determined, i.e. on Windows.
+//
+//fileData and hashLengths are in the same order as StaticFiles.
+func (c *Config) checkDiskSpace(fileData [][]byte, hashLengths []uint) error {
+	type filesystem struct {
+		dir    string
+		free   uint64
+		needed uint64
+	}
+	filesystems := make(map[uint64]*filesystem)
+
+	for k, s := range c.StaticFiles {
+		if s.Vendor || c.inMemory(s) {
+			continue
+		}
+
+		dir := filepath.Dir(s.LocalPath)
+		name := c.cacheBustFilename(s.hash, hashLengths[k], filepath.Base(s.LocalPath))
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			continue
+		}
+
+		id, free, ok := diskSpace(dir)
+		if !ok {
+			continue
+		}
+
+		f, exists := filesystems[id]
+		if !exists {
+			f = &filesystem{dir: dir, free: free}
+			filesystems[id] = f
+		}
+		f.needed += uint64(len(fileData[k]))
+	}
+
+	//check in a consistent order so the same error is returned each time.
+	ids := make([]uint64, 0, len(filesystems))
+	for id := range filesystems {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	margin := uint64(0)
+	if c.DiskSpaceMargin > 0 {
+		margin = uint64(c.DiskSpaceMargin)
+	}
+
+	for _, id := range ids {
+		f := filesystems[id]
+		if f.needed+margin > f.free {
+			return fmt.Errorf("%w: %d bytes needed, %d bytes margin, %d bytes available, directory: %s", ErrInsufficientDiskSpace, f.needed, margin, f.free, f.dir)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd
+
+package cachebusting
+
+//diskSpace returns the ID of the filesystem a directory is on and the number of bytes
+//available to the app on the filesystem. The free space cannot be determined on this
+//operating system so the disk space check is skipped.
+func diskSpace(dir string) (id, free uint64, ok bool) {
+	return
+}
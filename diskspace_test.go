@@ -0,0 +1,58 @@
+package cachebusting
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, ok := diskSpace(dir); !ok {
+		t.Skip("free disk space cannot be determined")
+	}
+
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A margin larger than the free space fails before any copy is saved.
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.DiskSpaceMargin = 1 << 62
+	err = c.Create()
+	if !errors.Is(err, ErrInsufficientDiskSpace) {
+		t.Fatal("ErrInsufficientDiskSpace should have been returned", err)
+		return
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatal("No copy should have been saved", len(entries))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies are saved when there is enough space.
+	c.DiskSpaceMargin = 1024
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies that already exist aren't counted.
+	c.DiskSpaceMargin = 1 << 62
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,29 @@
+//go:build linux || darwin || freebsd
+
+package cachebusting
+
+import (
+	"os"
+	"syscall"
+)
+
+//diskSpace returns the ID of the filesystem a directory is on and the number of bytes
+//available to the app on the filesystem. False is returned if either cannot be determined.
+func diskSpace(dir string) (id, free uint64, ok bool) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return
+	}
+	st, isStat := info.Sys().(*syscall.Stat_t)
+	if !isStat {
+		return
+	}
+
+	var fs syscall.Statfs_t
+	err = syscall.Statfs(dir, &fs)
+	if err != nil {
+		return
+	}
+
+	return uint64(st.Dev), uint64(fs.Bavail) * uint64(fs.Bsize), true
+}
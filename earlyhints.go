@@ -0,0 +1,14 @@
+//go:build go1.19
+
+package cachebusting
+
+import (
+	"net/http"
+)
+
+//writeEarlyHints sends a 103 Early Hints response with the headers set so far. Go 1.19
+//and later send a 1xx status as an informational response, the handler can still write
+//the final status and body afterwards.
+func writeEarlyHints(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusEarlyHints)
+}
@@ -0,0 +1,12 @@
+//go:build !go1.19
+
+package cachebusting
+
+import (
+	"net/http"
+)
+
+//writeEarlyHints does nothing. Prior to Go 1.19 a 1xx status is sent as the final
+//response, which would replace the status and headers written by the handler. The Link
+//header is still sent with the final response.
+func writeEarlyHints(w http.ResponseWriter) {}
@@ -0,0 +1,81 @@
+//go:build go1.19
+
+package cachebusting
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEarlyHints(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	css.Critical = true
+	c := NewOnDiskConfig(css)
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	})
+	srv := httptest.NewServer(c.EarlyHints(page))
+	defer srv.Close()
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, header.Get("Link"))
+			}
+			return nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The client receives the 103 with the Link header and then the page's 200 and body.
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if res.StatusCode != http.StatusOK || string(b) != "<html></html>" {
+		t.Fatal("Final response not as expected", res.StatusCode, string(b))
+		return
+	}
+	if len(hints) != 1 || !strings.Contains(hints[0], c.StaticFiles[0].cacheBustURLPath) {
+		t.Fatal("Early hints not sent as expected", hints)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,49 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"io"
+)
+
+//etag returns the strong ETag a static file is served with. This is the file's hash,
+//quoted as required for an ETag header value.
+func etag(s StaticFile) string {
+	return `"` + s.hash + `"`
+}
+
+//GetETagPairs returns the cache busting URL path to ETag pairs. The ETags are the same
+//strong ETags the handler responds with, so a reverse proxy, i.e. nginx or Varnish, can
+//answer conditional requests without forwarding the request to your app. Files that
+//haven't been hashed are not included.
+func (c *Config) GetETagPairs() (pairs map[string]string) {
+	pairs = make(map[string]string)
+
+	for _, v := range c.servedFiles() {
+		if v.cacheBustURLPath == "" || v.hash == "" {
+			continue
+		}
+
+		pairs[v.cacheBustURLPath] = etag(v)
+	}
+
+	return
+}
+
+//GetETagPairs returns the ETag pairs for the package level config.
+func GetETagPairs() (pairs map[string]string) {
+	return config.GetETagPairs()
+}
+
+//WriteETags writes the cache busting URL path to ETag pairs, see GetETagPairs(), to w as
+//JSON. The URL paths are sorted so the same files always result in the same output. Use
+//this to save the pairs to a file for generating your reverse proxy's configuration.
+func (c *Config) WriteETags(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.GetETagPairs())
+}
+
+//WriteETags writes the ETag pairs for the package level config to w.
+func WriteETags(w io.Writer) error {
+	return config.WriteETags(w)
+}
@@ -0,0 +1,68 @@
+package cachebusting
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGetETagPairs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No pairs are returned prior to Create().
+	c := NewFSConfig(fsys, "static", "/static")
+	if pairs := c.GetETagPairs(); len(pairs) != 0 {
+		t.Fatal("No pairs should have been returned", pairs)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Each cache busting URL path maps to the ETag the handler responds with.
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	pairs := c.GetETagPairs()
+	if len(pairs) != 2 {
+		t.Fatal("Pairs not returned", pairs)
+		return
+	}
+
+	h := c.StaticFileHandler(1, "static")
+	for u, e := range pairs {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+		if rec.Header().Get("ETag") != e {
+			t.Fatal("ETag does not match handler", u, e, rec.Header().Get("ETag"))
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The pairs are written as JSON.
+	var b bytes.Buffer
+	err = c.WriteETags(&b)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	var written map[string]string
+	err = json.Unmarshal(b.Bytes(), &written)
+	if err != nil || len(written) != 2 {
+		t.Fatal("Pairs not written as expected", err, b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
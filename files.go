@@ -0,0 +1,96 @@
+package cachebusting
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+//defaultMaxOpenFiles is the number of files that can be open at once, for reading original
+//files or writing cache busting copies, unless changed with MaxOpenFiles().
+const defaultMaxOpenFiles = 64
+
+//openFiles limits the number of files open at once across all configs, i.e. if Create() is
+//called for multiple configs at the same time. This prevents running out of file
+//descriptors on systems with a low limit.
+var (
+	openFilesMu sync.Mutex
+	openFiles   = make(chan struct{}, defaultMaxOpenFiles)
+)
+
+//MaxOpenFiles sets the maximum number of files this package will have open at once. Set
+//to 0 to use the default of 64. Files already open are not affected.
+func MaxOpenFiles(n int) {
+	if n <= 0 {
+		n = defaultMaxOpenFiles
+	}
+
+	openFilesMu.Lock()
+	openFiles = make(chan struct{}, n)
+	openFilesMu.Unlock()
+}
+
+//acquireFile waits until a file can be opened. The returned func must be called once the
+//file is closed.
+func acquireFile() (release func()) {
+	openFilesMu.Lock()
+	sem := openFiles
+	openFilesMu.Unlock()
+
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+//limitedRead wraps a func that reads a file so that the number of files open at once is
+//limited.
+func limitedRead(read func(string) ([]byte, error)) func(string) ([]byte, error) {
+	return func(p string) ([]byte, error) {
+		release := acquireFile()
+		defer release()
+
+		return read(p)
+	}
+}
+
+//writeFile saves data to a file, limiting the number of files open at once. The data is
+//first written to a temporary file which is then renamed to p so that a partially written
+//file is never served, i.e. if the app is stopped or the disk fills up while writing. The
+//temporary file is created in tempDir, or the directory of p if tempDir is blank. tempDir
+//must be on the same filesystem as p so that the file can be renamed.
+func writeFile(p string, data []byte, tempDir string) (err error) {
+	release := acquireFile()
+	defer release()
+
+	if tempDir == "" {
+		tempDir = filepath.Dir(p)
+	}
+
+	//the leading "." keeps the temporary file from looking like a cache busting copy. The
+	//name is short, and not based on p's name, so that any name allowed by
+	//checkPathLengths() can be written.
+	f, err := os.CreateTemp(tempDir, ".cb-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), p)
+}
@@ -0,0 +1,131 @@
+package cachebusting
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMaxOpenFiles(t *testing.T) {
+	MaxOpenFiles(2)
+	defer MaxOpenFiles(0)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No more than the limit of reads run at once.
+	var open, most int32
+	read := limitedRead(func(p string) ([]byte, error) {
+		n := atomic.AddInt32(&open, 1)
+		for {
+			m := atomic.LoadInt32(&most)
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+		atomic.AddInt32(&open, -1)
+		return nil, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			read("file")
+		}()
+	}
+	wg.Wait()
+
+	if most > 2 {
+		t.Fatal("Too many files open at once", most)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Concurrent Create() calls on separate configs still succeed with a low limit.
+	MaxOpenFiles(1)
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fsys := fstest.MapFS{
+				"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+				"static/css/styles.min.css": {Data: []byte("body{}")},
+			}
+			errs[i] = NewFSConfig(fsys, "static", "/static").Create()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal("Error occured but shouldn't have", err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	tempDir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The file is staged in the temporary directory and moved into place.
+	err := writeFile(p, []byte("console.log(1);"), tempDir)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if b, err := os.ReadFile(p); err != nil || string(b) != "console.log(1);" {
+		t.Fatal("File not written as expected", err)
+		return
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Fatal("Temporary file should have been moved", entries)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Without a temporary directory the file is staged next to the file.
+	err = writeFile(p, []byte("console.log(2);"), "")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatal("Temporary file should have been moved", entries)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A missing temporary directory is an error and the file is left as-is.
+	err = writeFile(p, []byte("console.log(3);"), filepath.Join(tempDir, "missing"))
+	if err == nil {
+		t.Fatal("Error should have occured")
+		return
+	}
+	if b, _ := os.ReadFile(p); string(b) != "console.log(2);" {
+		t.Fatal("File should not have been changed", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A file with the longest name allowed can be written.
+	long := filepath.Join(dir, strings.Repeat("a", maxFilenameLength-3)+".js")
+	err = writeFile(long, []byte("console.log(4);"), "")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,114 @@
+package cachebusting
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//fontFaceRe matches an @font-face rule in a CSS file.
+var fontFaceRe = regexp.MustCompile(`(?is)@font-face\s*\{[^}]*\}`)
+
+//fontURLs returns each URL referenced by the src of an @font-face rule in a CSS file's
+//data, as written in the file. URLs to other hosts and data URLs are not included.
+func fontURLs(data []byte) (urls []string) {
+	for _, rule := range fontFaceRe.FindAll(data, -1) {
+		for _, m := range cssURLRe.FindAllSubmatch(rule, -1) {
+			u := strings.TrimSpace(string(m[2]))
+			if !isLocalCSSURL(u) {
+				continue
+			}
+
+			urls = append(urls, u)
+		}
+	}
+
+	return
+}
+
+//registerFonts adds each font file referenced by an @font-face rule in a CSS file to
+//StaticFiles, if the font isn't already a static file. The font's local path is found
+//relative to the CSS file's local path. Fonts that don't exist are ignored so that a typo
+//in a CSS file doesn't prevent the app from starting. Fonts added by a prior call to
+//Create() are replaced, keeping their history of cache busting URL paths.
+func (c *Config) registerFonts() error {
+	//remove fonts added previously so they aren't duplicated.
+	prior := make(map[string]StaticFile)
+	files := make([]StaticFile, 0, len(c.StaticFiles))
+	for _, s := range c.StaticFiles {
+		if s.fontOf != "" {
+			prior[s.URLPath] = s
+			continue
+		}
+
+		files = append(files, s)
+	}
+	c.StaticFiles = files
+
+	registered := make(map[string]bool, len(files))
+	for _, s := range files {
+		registered[s.URLPath] = true
+	}
+
+	for _, s := range files {
+		if !isCSS(s) {
+			continue
+		}
+
+		data, err := c.readOriginal(s)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range fontURLs(data) {
+			urlPath, _ := resolveCSSURL(s.URLPath, u)
+			if registered[urlPath] {
+				continue
+			}
+
+			rel := relativeURLPath(path.Dir(s.URLPath), urlPath)
+			localPath := filepath.Join(filepath.Dir(s.LocalPath), filepath.FromSlash(rel))
+			if c.usesFS() {
+				localPath = path.Join(path.Dir(filepath.ToSlash(s.LocalPath)), rel)
+			}
+			if c.statOriginal(localPath) != nil {
+				continue
+			}
+
+			f := prior[urlPath]
+			f.LocalPath = localPath
+			f.URLPath = urlPath
+			f.Storage = s.Storage
+			f.fontOf = s.URLPath
+
+			c.StaticFiles = append(c.StaticFiles, f)
+			registered[urlPath] = true
+		}
+	}
+
+	return nil
+}
+
+//relativeURLPath returns the path of target relative to the directory dir, both being
+//absolute URL paths, i.e. /static/fonts/a.woff2 relative to /static/css is ../fonts/a.woff2.
+func relativeURLPath(dir, target string) string {
+	d := strings.Split(strings.Trim(dir, "/"), "/")
+	t := strings.Split(strings.Trim(target, "/"), "/")
+	if d[0] == "" {
+		d = nil
+	}
+
+	i := 0
+	for i < len(d) && i < len(t)-1 && d[i] == t[i] {
+		i++
+	}
+
+	rel := make([]string, 0, len(d)-i+len(t)-i)
+	for range d[i:] {
+		rel = append(rel, "..")
+	}
+	rel = append(rel, t[i:]...)
+
+	return strings.Join(rel, "/")
+}
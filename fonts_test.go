@@ -0,0 +1,85 @@
+package cachebusting
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRegisterFonts(t *testing.T) {
+	css := `@font-face{font-family:"A";src:url("../fonts/a.woff2") format("woff2"),url(/static/fonts/b.woff?#iefix) format("woff")}
+@font-face{font-family:"M";src:url(../fonts/missing.woff2)}
+body{background:url(../img/bg.png)}`
+	fsys := fstest.MapFS{
+		"static/css/styles.min.css": {Data: []byte(css)},
+		"static/fonts/a.woff2":      {Data: []byte("a")},
+		"static/fonts/b.woff":       {Data: []byte("b")},
+		"static/img/bg.png":         {Data: []byte("bg")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Fonts are added and the CSS file references the cache busting copies.
+	c := NewFSConfig(fsys, "static/css", "/static/css")
+	c.RegisterFonts = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(c.StaticFiles) != 3 {
+		t.Fatal("Fonts not registered as expected", len(c.StaticFiles))
+		return
+	}
+
+	urls := make(map[string]string)
+	var rewritten string
+	for _, s := range c.StaticFiles {
+		urls[s.URLPath] = s.cacheBustURLPath
+		if s.URLPath == "/static/css/styles.min.css" {
+			rewritten = string(s.fileData)
+		}
+	}
+	if urls["/static/fonts/a.woff2"] == "" || urls["/static/fonts/b.woff"] == "" {
+		t.Fatal("Fonts not cache busted", urls)
+		return
+	}
+	if !strings.Contains(rewritten, `url("`+urls["/static/fonts/a.woff2"]+`")`) || !strings.Contains(rewritten, "url("+urls["/static/fonts/b.woff"]+"?#iefix)") {
+		t.Fatal("Font URLs not rewritten", rewritten)
+		return
+	}
+	if !strings.Contains(rewritten, "url(../fonts/missing.woff2)") || !strings.Contains(rewritten, "url(../img/bg.png)") {
+		t.Fatal("Other URLs should not have been rewritten", rewritten)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Calling Create() again doesn't duplicate the fonts.
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(c.StaticFiles) != 3 {
+		t.Fatal("Fonts should not have been duplicated", len(c.StaticFiles))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRelativeURLPath(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	tests := map[[2]string]string{
+		{"/static/css", "/static/fonts/a.woff2"}:     "../fonts/a.woff2",
+		{"/static/css", "/static/css/fonts/a.woff2"}: "fonts/a.woff2",
+		{"/", "/fonts/a.woff2"}:                      "fonts/a.woff2",
+		{"/static/css", "/a.woff2"}:                  "../../a.woff2",
+	}
+	for in, want := range tests {
+		if got := relativeURLPath(in[0], in[1]); got != want {
+			t.Fatal("Relative path not as expected", in, got, want)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,43 @@
+//go:build go1.18
+
+package cachebusting
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzCleanRequestPath(f *testing.F) {
+	f.Add("/static/css/styles.min.css")
+	f.Add("//static//css/../css/./styles.min.css")
+	f.Add("/static/%2e%2e/secret")
+	f.Add("/static/été.css")
+
+	f.Fuzz(func(t *testing.T, p string) {
+		cleaned, ok := cleanRequestPath(p)
+		if !ok {
+			return
+		}
+
+		if !strings.HasPrefix(cleaned, "/") {
+			t.Fatal("Cleaned path does not start with /", cleaned)
+		}
+		if strings.Contains(cleaned, "//") || strings.Contains(cleaned, "\\") {
+			t.Fatal("Cleaned path contains invalid separators", cleaned)
+		}
+		for _, elem := range strings.Split(cleaned, "/") {
+			if elem == ".." || elem == "." {
+				t.Fatal("Cleaned path contains relative elements", cleaned)
+			}
+		}
+		if !utf8.ValidString(cleaned) {
+			t.Fatal("Cleaned path is not valid UTF-8", cleaned)
+		}
+
+		again, ok := cleanRequestPath(cleaned)
+		if !ok || again != cleaned {
+			t.Fatal("Cleaning is not stable", cleaned, again)
+		}
+	})
+}
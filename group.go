@@ -0,0 +1,62 @@
+package cachebusting
+
+import (
+	"html/template"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//groupFiles returns the static files in a group, in the order the files are listed in
+//StaticFiles with each file after the files it depends on.
+func (c *Config) groupFiles(name string) (files []StaticFile) {
+	for _, s := range c.servedFiles() {
+		if containsString(s.Groups, name) {
+			files = append(files, s)
+		}
+	}
+
+	return orderByDependencies(files)
+}
+
+//Group returns the original filename to cache busting URL pairs, see GetURLPairs(), for
+//just the static files in a group. Use this rather than the pairs for every static file
+//when a page only needs a few files.
+func (c *Config) Group(name string) (pairs map[string]string) {
+	pairs = make(map[string]string)
+	for _, s := range c.groupFiles(name) {
+		pairs[filepath.Base(s.LocalPath)] = c.urlFor(s)
+	}
+
+	return
+}
+
+//Group returns the URL pairs of a group for the package level config.
+func Group(name string) (pairs map[string]string) {
+	return config.Group(name)
+}
+
+//GroupTags returns a <script> element for each JavaScript file and a <link
+//rel="stylesheet"> element for each CSS file in a group, in the order the files are listed
+//in StaticFiles with each file after the files it depends on (see DependsOn). Each element includes the integrity and crossorigin attributes, see
+//ScriptTag() and StyleTag(). Other files in the group are ignored.
+func (c *Config) GroupTags(name string) template.HTML {
+	var b strings.Builder
+	for _, s := range c.groupFiles(name) {
+		u := template.HTMLEscapeString(c.urlFor(s))
+
+		switch strings.ToLower(path.Ext(s.URLPath)) {
+		case ".js", ".mjs":
+			b.WriteString(`<script src="` + u + `"` + integrityAttrs(s) + "></script>\n")
+		case ".css":
+			b.WriteString(`<link rel="stylesheet" href="` + u + `"` + integrityAttrs(s) + ">\n")
+		}
+	}
+
+	return template.HTML(b.String())
+}
+
+//GroupTags returns the elements for a group using the package level config.
+func GroupTags(name string) template.HTML {
+	return config.GroupTags(name)
+}
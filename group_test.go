@@ -0,0 +1,120 @@
+package cachebusting
+
+import (
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGroup(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/checkout.js": {Data: []byte("checkout")},
+		"static/js/admin.js":    {Data: []byte("admin")},
+		"static/js/shared.js":   {Data: []byte("shared")},
+		"static/css/shared.css": {Data: []byte("body{}")},
+		"static/img/logo.png":   {Data: []byte("logo")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k, s := range c.StaticFiles {
+		switch s.URLPath {
+		case "/static/js/checkout.js":
+			c.StaticFiles[k].Groups = []string{"checkout"}
+		case "/static/js/admin.js":
+			c.StaticFiles[k].Groups = []string{"admin"}
+		case "/static/js/shared.js", "/static/css/shared.css", "/static/img/logo.png":
+			c.StaticFiles[k].Groups = []string{"admin", "checkout"}
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Only the files in the group are returned.
+	pairs := c.Group("checkout")
+	if len(pairs) != 4 || pairs["admin.js"] != "" || pairs["checkout.js"] == "" || pairs["shared.js"] == "" {
+		t.Fatal("Group pairs not as expected", pairs)
+		return
+	}
+	if len(c.Group("missing")) != 0 {
+		t.Fatal("Unknown group should have no pairs")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Tags are output for scripts and styles in the order files are listed.
+	var expected string
+	for _, s := range c.StaticFiles {
+		if !containsString(s.Groups, "admin") {
+			continue
+		}
+
+		switch s.URLPath {
+		case "/static/css/shared.css":
+			expected += `<link rel="stylesheet" href="` + s.cacheBustURLPath + `"` + integrityAttrs(s) + ">\n"
+		case "/static/js/admin.js", "/static/js/shared.js":
+			expected += `<script src="` + s.cacheBustURLPath + `"` + integrityAttrs(s) + "></script>\n"
+		}
+	}
+	if tags := string(c.GroupTags("admin")); tags != expected {
+		t.Fatal("Group tags not as expected", tags)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestOrderByDependencies(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/app.js":        {Data: []byte("app")},
+		"static/js/jquery.min.js": {Data: []byte("jquery")},
+		"static/js/plugin.js":     {Data: []byte("plugin")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k, s := range c.StaticFiles {
+		c.StaticFiles[k].Groups = []string{"page"}
+		c.StaticFiles[k].Critical = true
+
+		switch s.URLPath {
+		case "/static/js/app.js":
+			c.StaticFiles[k].DependsOn = []string{"plugin.js", "missing.js"}
+		case "/static/js/plugin.js":
+			c.StaticFiles[k].DependsOn = []string{"jquery.min.js", "app.js"} //cycle
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files are listed after the files they depend on.
+	var names []string
+	for _, s := range c.groupFiles("page") {
+		names = append(names, s.URLPath)
+	}
+	expected := []string{"/static/js/jquery.min.js", "/static/js/plugin.js", "/static/js/app.js"}
+	if !equalStrings(names, expected) {
+		t.Fatal("Files not in dependency order", names)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Preloads are also output in dependency order.
+	var urls []string
+	for _, p := range c.preloads() {
+		urls = append(urls, p.url)
+	}
+	for i, u := range urls {
+		s, _ := c.findByOriginalName(path.Base(expected[i]))
+		if u != s.cacheBustURLPath {
+			t.Fatal("Preloads not in dependency order", urls)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,799 @@
+package cachebusting
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+//StaticFileHandler is an example func that can be used to serve static files whether you
+//are using embedded or on-disk original files and in memory or on disk cache busting files.
+//You would use this func in your http router. Cache busting files are served by looking up
+//the file, so any directory structure works for them, but files that aren't cache busted
+//(i.e. vendor files) require a strict local directory structure and url path.
+//Notes:
+// - See package level comment about expected directory structure.
+// - Extra headers added for diagnosing where files are stored in browser dev tools.
+// - Set cacheDays to 0 to prevent caching in the user's browser.
+// - Files that aren't cache busted are revalidated by the browser on each use, see
+//   HandlerOptions.FallbackCacheControl.
+func (c *Config) StaticFileHandler(cacheDays int, pathToStaticFiles string) http.Handler {
+	return c.Handler(HandlerOptions{
+		CacheDays:         cacheDays,
+		PathToStaticFiles: pathToStaticFiles,
+	})
+}
+
+//HandlerOptions is the set of options for serving static files with Handler().
+type HandlerOptions struct {
+	//CacheDays is the number of days the user's browser should cache cache busting copies,
+	//and vendor files, for. These are marked immutable since their URL changes when their
+	//contents change. Set to 0 to prevent caching in the user's browser. Other files use
+	//FallbackCacheControl.
+	CacheDays int
+
+	//FallbackCacheControl is the Cache-Control header value for files that aren't cache
+	//busting copies or vendor files, i.e. original files served by the file server or
+	//when Development is true, since their contents can change without their URL
+	//changing. If not provided, no-cache is used so the browser revalidates each file.
+	FallbackCacheControl string
+
+	//PathToStaticFiles is the directory on disk the URL paths of your static files are
+	//relative to, i.e. the "website" directory noted in the package level comment. This
+	//is only used when the original files are stored on disk.
+	PathToStaticFiles string
+
+	//Roots maps URL path prefixes to the filesystem files requested under the prefix are
+	//served from, i.e. "/static/" to os.DirFS("website/static") and "/assets/" to an
+	//embed.FS. The prefix is removed from the request's URL path before the file is looked
+	//up in the filesystem. This is used, instead of the strict directory structure noted
+	//in the package level comment, for files that aren't cache busted. Requests that don't
+	//match a prefix are responded to with a 404.
+	Roots map[string]fs.FS
+
+	//OnServe is called after each request is served with details about the request. Use
+	//this for per-file analytics or logging.
+	OnServe func(ServeInfo)
+
+	//Counters counts the requests served by each outcome, see ServeOutcome. Use this to
+	//find templates that still reference files that aren't cache busted.
+	Counters *ServeCounters
+
+	//Tracer is used to create a span for each request, for example with OpenTelemetry,
+	//so that serving static files shows up in your traces. See Tracer.
+	Tracer Tracer
+
+	//GoneForUnknownCopies causes requests for a file named like a cache busting copy, but
+	//that is not a known copy, i.e. an old copy removed by Create(), to be responded to
+	//with a 410 Gone rather than a 404. A Link header with the URL of the current copy is
+	//included so that CDNs and crawlers can stop requesting the old URL.
+	GoneForUnknownCopies bool
+
+	//StripPrefix is the URL path prefix removed from requests before they reach the
+	//handler, i.e. by http.StripPrefix or a router mounting the handler under "/assets".
+	//The prefix is added back to the request's path so that the path matches the URL
+	//paths of your static files. If not provided, the request's original path, from
+	//r.RequestURI, is also checked when looking up a cache busting copy.
+	StripPrefix string
+
+	//Authorize is called before each request is served. Requests are responded to with a
+	//403 Forbidden if false is returned. Use this to restrict access to internal files,
+	//i.e. an admin bundle, by IP address or Referer while serving all files from the same
+	//route. The request's path has been cleaned, StripPrefix added back, and the config's
+	//BasePath removed, so the path can be compared to the URL paths of your static files.
+	Authorize func(*http.Request) bool
+
+	//Headers are set on every response, i.e. Content-Security-Policy or
+	//Cross-Origin-Resource-Policy. These are needed since requests served by Handler()
+	//don't pass through middleware applied to your other routes. The defaults, see
+	//defaultSecurityHeaders, are set unless overridden here. Set a header to a blank value
+	//to not set a default header.
+	Headers map[string]string
+
+	//AllowedOrigins are the origins allowed to request files cross-origin, i.e.
+	//"https://www.example.com" when files are served from a CDN subdomain, or "*" for any
+	//origin. Fonts require this when served from a different origin. The CORS headers are
+	//set on all responses, whether a cache busting copy or an original file is served,
+	//and preflight requests are responded to. CORS headers are not set if no origins are
+	//provided.
+	AllowedOrigins []string
+
+	//AllowCredentials allows cross-origin requests to include credentials, i.e. cookies,
+	//see AllowedOrigins. Credentials are only allowed for origins listed explicitly, never
+	//for origins allowed by "*".
+	AllowCredentials bool
+
+	//Charset is the charset added to the Content-Type header of text files, i.e. CSS and
+	//JavaScript, so that non-ASCII characters are decoded correctly by browsers and
+	//proxies. If not provided, utf-8 is used. See OmitCharset.
+	Charset string
+
+	//OmitCharset causes no charset to be added to the Content-Type header of text files,
+	//see Charset.
+	OmitCharset bool
+
+	//DiskCacheBytes is the maximum total size, in bytes, of the cache busting copies saved
+	//to disk that are cached in memory after they are first requested. This combines the
+	//low memory use at startup of saving copies to disk with serving frequently requested
+	//files from memory. When the size would be exceeded, the least recently requested
+	//copies are removed from memory. Copies larger than this are always served from
+	//disk. Set to 0 to always serve copies saved to disk from disk.
+	DiskCacheBytes int64
+}
+
+//charset returns the charset added to text content types, or a blank string if no
+//charset should be added. See HandlerOptions.Charset.
+func (opts HandlerOptions) charset() string {
+	if opts.OmitCharset {
+		return ""
+	}
+	if opts.Charset == "" {
+		return defaultCharset
+	}
+
+	return opts.Charset
+}
+
+//defaultFallbackCacheControl is the Cache-Control header value for files that aren't
+//cache busting copies, see HandlerOptions.FallbackCacheControl.
+const defaultFallbackCacheControl = "no-cache"
+
+//cacheControl returns the Cache-Control header value for a response. Only versioned
+//files, cache busting copies and vendor files, are cached for CacheDays since the URL of
+//other files doesn't change when their contents change.
+func (opts HandlerOptions) cacheControl(versioned bool) string {
+	if !versioned {
+		if opts.FallbackCacheControl == "" {
+			return defaultFallbackCacheControl
+		}
+
+		return opts.FallbackCacheControl
+	}
+
+	//max age is in days
+	//if value is 0, files won't be cached in browser
+	maxAge := opts.CacheDays * 24 * 60 * 60
+	if maxAge <= 0 {
+		return "no-transform,public,max-age=0"
+	}
+
+	return "no-transform,public,max-age=" + strconv.Itoa(maxAge) + ",immutable"
+}
+
+//defaultSecurityHeaders are the headers set on every response served by Handler(), see
+//HandlerOptions.Headers. nosniff prevents the browser from guessing a file's type from its
+//contents, i.e. running an uploaded or compressed file as a script.
+var defaultSecurityHeaders = map[string]string{
+	"X-Content-Type-Options": "nosniff",
+}
+
+//setHeaders sets the default security headers and the headers in opts on a response.
+func setHeaders(w http.ResponseWriter, opts HandlerOptions) {
+	for k, v := range defaultSecurityHeaders {
+		w.Header().Set(k, v)
+	}
+
+	for k, v := range opts.Headers {
+		if v == "" {
+			w.Header().Del(k)
+			continue
+		}
+
+		w.Header().Set(k, v)
+	}
+}
+
+//Tracer creates spans for tracing requests. This is a small interface so that this package
+//doesn't depend on a specific tracing library. For example, with OpenTelemetry:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string) (context.Context, cachebusting.Span) {
+//		ctx, span := o.t.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttribute(key string, value interface{}) {
+//		switch v := value.(type) {
+//		case string:
+//			s.SetAttributes(attribute.String(key, v))
+//		case int64:
+//			s.SetAttributes(attribute.Int64(key, v))
+//		}
+//	}
+//
+//	func (s otelSpan) End() { s.Span.End() }
+type Tracer interface {
+	//Start creates a span and a context containing the span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+//Span is a single traced operation created by a Tracer.
+type Span interface {
+	//SetAttribute sets an attribute on the span. The value is a string or int64.
+	SetAttribute(key string, value interface{})
+
+	//End completes the span.
+	End()
+}
+
+//span attribute names
+const (
+	spanName   = "cachebusting.serve"
+	attrPath   = "url.path"
+	attrAsset  = "cachebusting.asset"
+	attrSource = "cachebusting.source"
+	attrSize   = "cachebusting.size"
+	attrStatus = "http.response.status_code"
+)
+
+//ServeInfo is the details about a request served by Handler(), see HandlerOptions.OnServe.
+type ServeInfo struct {
+	//Path is the URL path that was requested.
+	Path string
+
+	//URLPath is the URL path of the original static file that was served. This is blank
+	//if the request wasn't for a known static file, i.e.: a vendor file.
+	URLPath string
+
+	//Source is where the file was served from: memory, disk, embedded, or fs. This is
+	//blank if the request was rejected before a file was looked up.
+	Source string
+
+	//Status is the HTTP status code of the response.
+	Status int
+
+	//Outcome is how the request was served, i.e. with a cache busting copy or by falling
+	//back to the file server. See ServeOutcome.
+	Outcome ServeOutcome
+
+	//Bytes is the number of bytes written in the response body.
+	Bytes int64
+
+	//Duration is how long it took to serve the request.
+	Duration time.Duration
+}
+
+//Handler returns an http.Handler that serves static files. See StaticFileHandler() for
+//notes on the expected directory structure.
+//
+//Files are looked up in a snapshot of StaticFiles taken at the end of Create(), so
+//requests are served without locking, even while Create() is running again. Call
+//Create() again after modifying StaticFiles.
+func (c *Config) Handler(opts HandlerOptions) http.Handler {
+	h := c.handler(opts)
+	if opts.OnServe == nil && opts.Tracer == nil && opts.Counters == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var span Span
+		if opts.Tracer != nil {
+			var ctx context.Context
+			ctx, span = opts.Tracer.Start(r.Context(), spanName)
+			r = r.WithContext(ctx)
+		}
+
+		start := time.Now()
+		rw := &responseRecorder{ResponseWriter: w}
+		h.ServeHTTP(rw, r)
+
+		info := ServeInfo{
+			Path:     r.URL.Path,
+			Source:   w.Header().Get("X-Static-Served-From"),
+			Status:   rw.status,
+			Bytes:    rw.bytes,
+			Duration: time.Since(start),
+		}
+		if info.Status == 0 {
+			info.Status = http.StatusOK
+		}
+		if rw.found {
+			info.URLPath = rw.file.URLPath
+		}
+		info.Outcome = c.serveOutcome(info.Status, rw.file, rw.found)
+		opts.Counters.add(info.Outcome)
+
+		if span != nil {
+			span.SetAttribute(attrPath, info.Path)
+			span.SetAttribute(attrAsset, info.URLPath)
+			span.SetAttribute(attrSource, info.Source)
+			span.SetAttribute(attrSize, info.Bytes)
+			span.SetAttribute(attrStatus, int64(info.Status))
+			span.End()
+		}
+
+		if opts.OnServe != nil {
+			opts.OnServe(info)
+		}
+	})
+}
+
+//responseRecorder records the status code and number of bytes of a response, and the
+//static file found for the request by handler(), so the file isn't looked up again.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	file   StaticFile
+	found  bool
+}
+
+//WriteHeader records the status code.
+func (rw *responseRecorder) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+//Write records the number of bytes written.
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+//Unwrap returns the underlying http.ResponseWriter. http.ResponseController, added in Go
+//1.20, uses this to reach the underlying writer's Flush(), Hijack(), etc.
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+//DefaultHandler returns an http.Handler that serves static files using the package level
+//config.
+func DefaultHandler(opts HandlerOptions) http.Handler {
+	return config.Handler(opts)
+}
+
+//handler serves static files, see StaticFileHandler().
+func (c *Config) handler(opts HandlerOptions) http.Handler {
+	var diskCache *lruCache
+	if opts.DiskCacheBytes > 0 {
+		diskCache = newLRUCache(opts.DiskCacheBytes)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setHeaders(w, opts)
+		if setCORSHeaders(w, r, opts) {
+			return
+		}
+
+		//make sure the request path is sane and in a consistent format prior to looking
+		//up the file. This prevents odd paths, for example with duplicate slashes, from
+		//not matching a cache busting file stored in memory and falling through to the
+		//file server.
+		cleaned, ok := cleanRequestPath(r.URL.Path)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if opts.StripPrefix != "" {
+			cleaned = addURLPrefix(cleaned, opts.StripPrefix)
+		}
+		cleaned = c.trimBasePath(cleaned)
+		if cleaned != r.URL.Path {
+			r = withPath(r, cleaned)
+		}
+
+		if opts.Authorize != nil && !opts.Authorize(r) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		//serve the file being requested.
+		//Cache busting files will be stored in the app's memory if the app is using embedded
+		//files or the app is storing cache busting versions of on disk files in memory (i.e.
+		//app is deployed on a system that doesn't allow writing to disk), unless the file's
+		//Storage says otherwise. If the file cannot be found and served, the file being
+		//requested is most likely a vendor file.
+		//In development, the original files are always served so that changes are seen
+		//without calling Create() again. The original files are also served when NoCopy
+		//is true since no copies exist.
+		s, outdated, found := c.findRequested(r)
+		if rw, ok := w.(*responseRecorder); ok {
+			rw.file, rw.found = s, found
+		}
+		versioned := found && !outdated
+		if found && (c.Development || c.NoCopy) {
+			found = false
+
+			//with NoCopy, the original file's URL is versioned by the query instead so
+			//the file can be cached as long as the version is the current version.
+			versioned = versioned && !c.Development && c.versionMatches(r, s)
+		}
+
+		//set header to control caching of file in user's browser. An outdated copy is
+		//served with the current copy's contents so it must not be cached as immutable.
+		w.Header().Set("Cache-Control", opts.cacheControl(versioned))
+
+		if found && c.inMemory(s) {
+			if outdated {
+				w.Header().Set("Warning", outdatedWarning)
+			}
+
+			w.Header().Set("X-Static-Served-From", "memory")
+			w.Header().Set("Content-Type", withCharset(contentType(path.Ext(r.URL.Path)), opts.charset()))
+
+			//ServeContent handles range requests, single and multiple ranges, so that
+			//large files such as videos can be seeked. The ETag allows If-Range to work.
+			if s.hash != "" {
+				w.Header().Set("ETag", etag(s))
+			}
+
+			//large files are served directly from the filesystem, see StreamMinBytes.
+			if s.streamed {
+				if c.UseEmbedded {
+					w.Header().Set("X-Static-Served-From", "embedded")
+				} else {
+					w.Header().Set("X-Static-Served-From", "fs")
+				}
+
+				content, closeFunc, err := c.openStreamed(s)
+				if err != nil {
+					log.Println("cachebusting.StaticFileHandler", "could not open streamed file", s.LocalPath, err)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				defer closeFunc()
+
+				http.ServeContent(w, r, path.Base(r.URL.Path), time.Time{}, content)
+				return
+			}
+
+			//read the copy from the memory cache, see MemoryCacheBytes.
+			data := s.fileData
+			if s.evicted {
+				var err error
+				data, err = c.cachedData(s)
+				if err != nil {
+					log.Println("cachebusting.StaticFileHandler", "could not read cached file", s.LocalPath, err)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+			}
+
+			http.ServeContent(w, r, path.Base(r.URL.Path), time.Time{}, bytes.NewReader(data))
+			return
+		} else if found {
+			//recreate the cache busting copy on disk if it has gone missing.
+			if c.SelfHeal && c.serveHealed(w, r, s) {
+				return
+			}
+
+			//an outdated cache busting file was removed from disk so serve the current
+			//copy instead.
+			if outdated {
+				w.Header().Set("Warning", outdatedWarning)
+			}
+
+			//serve the copy from memory if it has been cached, see DiskCacheBytes.
+			if diskCache != nil && c.serveDiskCached(w, r, s, diskCache, opts.charset()) {
+				return
+			}
+
+			//serve the exact cache busting copy saved to disk. This doesn't require the
+			//URL path to match the directory structure on disk.
+			w.Header().Set("X-Static-Served-From", "disk")
+			setContentType(w, path.Ext(r.URL.Path), opts.charset())
+			http.ServeFile(w, r, s.cacheBustLocalPath)
+			return
+		}
+
+		//respond to requests for old cache busting copies that no longer exist.
+		if opts.GoneForUnknownCopies && !c.Development {
+			if current, ok := c.currentCopy(r.URL.Path); ok {
+				w.Header().Set("Link", "<"+c.urlFor(current)+">; rel=\"canonical\"")
+				http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+				return
+			}
+		}
+
+		//never serve the mapping files saved alongside the copies, see MappingFile. These
+		//list the original files and are only meant for this package and your tooling.
+		if path.Base(r.URL.Path) == mappingFilename {
+			http.NotFound(w, r)
+			return
+		}
+
+		//serve files that couldn't be found by looking up the cache busting files.
+		//This is an original file or a vendor file. Get the correct list of filesystem based on if
+		//the app is using embedded files or files stored on disk.
+		var httpFS http.FileSystem
+		if opts.Roots != nil {
+			w.Header().Set("X-Static-Served-From", "fs")
+
+			//serve from the filesystem mapped to the longest matching URL prefix.
+			root, p, ok := matchRoot(opts.Roots, r.URL.Path)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			r = withPath(r, p)
+
+			httpFS = http.FS(root)
+		} else if c.UseEmbedded {
+			w.Header().Set("X-Static-Served-From", "embedded")
+
+			//dir is equivalent to "/" now. This doesn't work for us because requests
+			//are coming in for files with url paths starting at /static/.
+			//Note: See package level comment about expected directory structure.
+			rootDir := c.EmbeddedFS
+
+			//change to the /website directory. Inside this directory is the static
+			//directory where files are stored. The directory structure now matches the
+			//request path.
+			const dirName = "website"
+			websiteDir, err := fs.Sub(rootDir, dirName)
+			if err != nil {
+				log.Println("cachebusting.StaticFileHandler", "could not find "+dirName+" in embedded files.", err)
+				return
+			}
+
+			//serve the /website directory where static/... is located
+			httpFS = http.FS(websiteDir)
+		} else if c.FS != nil {
+			w.Header().Set("X-Static-Served-From", "fs")
+
+			//serve the directory the files were found in with NewFSConfig(), removing the
+			//URL prefix from the request path so that the request path matches the
+			//directory structure, however deeply nested the file is.
+			root := c.fsRoot
+			if root == "" {
+				root = "."
+			}
+			rootDir, err := fs.Sub(c.FS, root)
+			if err != nil {
+				log.Println("cachebusting.StaticFileHandler", "could not find "+root+" in filesystem.", err)
+				return
+			}
+
+			p, ok := stripURLPrefix(r.URL.Path, c.fsURLPrefix)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			r = withPath(r, p)
+
+			httpFS = http.FS(rootDir)
+		} else {
+			w.Header().Set("X-Static-Served-From", "disk")
+
+			//This was the old way of serving static files before support for embedded files existed.
+			//os.DirFS opens the "website" directory so that when a path is requested starting with
+			//"static", the directory structure will match the url path.
+			dir := os.DirFS(opts.PathToStaticFiles)
+			httpFS = http.FS(dir)
+		}
+
+		setContentType(w, path.Ext(r.URL.Path), opts.charset())
+		fileserver := http.FileServer(httpFS)
+		fileserver.ServeHTTP(w, r)
+		return
+	})
+}
+
+//currentCopy returns the static file that urlPath is named like a cache busting copy of.
+//This is used to find the current copy when an unknown copy, such as an old copy that was
+//removed, is requested.
+func (c *Config) currentCopy(urlPath string) (s StaticFile, found bool) {
+	dir, name := path.Dir(urlPath), path.Base(urlPath)
+
+	//use the matchers built by Create() if possible.
+	files := c.StaticFiles
+	var matchers []func(string) bool
+	if l := c.currentLookup(); l != nil {
+		files, matchers = l.files, l.copyMatchers
+	} else {
+		matchers = c.copyMatchers(files)
+	}
+
+	for k, v := range files {
+		if matchers[k] == nil || path.Dir(v.URLPath) != dir {
+			continue
+		}
+
+		if matchers[k](name) {
+			return v, true
+		}
+	}
+
+	return
+}
+
+//cleanRequestPath validates and normalizes the path of a request. The returned path always
+//starts with a "/" and has duplicate slashes and "." elements removed. False is returned
+//if the path is invalid: it contains invalid UTF-8, control characters, backslashes, or
+//".." elements. A trailing slash is kept.
+func cleanRequestPath(p string) (cleaned string, ok bool) {
+	if !utf8.ValidString(p) {
+		return "", false
+	}
+
+	for _, r := range p {
+		if r == '\\' || unicode.IsControl(r) {
+			return "", false
+		}
+	}
+
+	for _, elem := range strings.Split(p, "/") {
+		if elem == ".." {
+			return "", false
+		}
+	}
+
+	//keep a trailing slash since the file server uses it to tell if a directory is being
+	//requested and will redirect to the path with the trailing slash.
+	cleaned = path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+
+	return cleaned, true
+}
+
+//addURLPrefix adds a prefix removed from a URL path back to the path, keeping a trailing
+//slash.
+func addURLPrefix(p, prefix string) string {
+	prefixed := path.Join("/", prefix, p)
+	if strings.HasSuffix(p, "/") && prefixed != "/" {
+		prefixed += "/"
+	}
+
+	return prefixed
+}
+
+//lookupPath returns the path used to look up a cache busting copy for a requested path.
+//The path is cleaned, see cleanRequestPath(), and a trailing slash is removed since a
+//cache busting copy is never a directory. The path is returned as-is if it is invalid.
+func lookupPath(p string) string {
+	//most requests are for clean paths, return them without allocating.
+	if isCleanPath(p) {
+		return p
+	}
+
+	cleaned, ok := cleanRequestPath(p)
+	if !ok {
+		return p
+	}
+
+	if cleaned != "/" {
+		cleaned = strings.TrimSuffix(cleaned, "/")
+	}
+
+	return cleaned
+}
+
+//isCleanPath returns true if a path is already in the format returned by lookupPath():
+//it starts with a "/", has no empty, ".", or ".." elements, and has no trailing slash.
+//Paths with characters that cleanRequestPath() checks for, or with any non-ASCII
+//characters, return false so that they are checked in full.
+func isCleanPath(p string) bool {
+	if p == "/" {
+		return true
+	}
+	if len(p) < 2 || p[0] != '/' {
+		return false
+	}
+
+	for i := 0; i < len(p); i++ {
+		b := p[i]
+		if b < 0x20 || b >= 0x7f || b == '\\' {
+			return false
+		}
+	}
+
+	rest := p[1:]
+	for {
+		i := strings.Index(rest, "/")
+		elem := rest
+		if i >= 0 {
+			elem = rest[:i]
+		}
+		if elem == "" || elem == "." || elem == ".." {
+			return false
+		}
+		if i < 0 {
+			return true
+		}
+		rest = rest[i+1:]
+	}
+}
+
+//findRequested looks up the static file for a request's path. If not found, the
+//request's original path is checked in case a prefix was removed from the path, i.e. by
+//http.StripPrefix, without setting HandlerOptions.StripPrefix.
+func (c *Config) findRequested(r *http.Request) (s StaticFile, outdated, found bool) {
+	requested := lookupPath(r.URL.Path)
+	s, outdated, found = c.findByCacheBustURLPath(requested)
+	if found || r.RequestURI == "" {
+		return
+	}
+
+	u, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil {
+		return
+	}
+	original := c.trimBasePath(lookupPath(u.Path))
+	if original == requested {
+		return
+	}
+
+	return c.findByCacheBustURLPath(original)
+}
+
+//stripURLPrefix removes a prefix from a URL path. The prefix must match entire path
+//elements, i.e.: "/static" matches "/static/css/styles.min.css" but not "/statics/". The
+//returned path always starts with a "/". False is returned if the path does not start
+//with the prefix.
+func stripURLPrefix(p, prefix string) (stripped string, ok bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return p, true
+	}
+
+	if p != prefix && !strings.HasPrefix(p, prefix+"/") {
+		return "", false
+	}
+
+	stripped = strings.TrimPrefix(p, prefix)
+	if stripped == "" {
+		stripped = "/"
+	}
+
+	return stripped, true
+}
+
+//matchRoot returns the filesystem for the longest URL prefix in roots that matches the
+//path, and the path with the prefix removed.
+func matchRoot(roots map[string]fs.FS, p string) (root fs.FS, stripped string, ok bool) {
+	longest := -1
+	for prefix, fsys := range roots {
+		s, match := stripURLPrefix(p, prefix)
+		if !match || len(prefix) <= longest {
+			continue
+		}
+
+		root, stripped, ok = fsys, s, true
+		longest = len(prefix)
+	}
+
+	return
+}
+
+//withPath returns a shallow copy of a request with a different URL path. The request is
+//copied so that the caller's request is not modified.
+func withPath(r *http.Request, p string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+
+	u := *r.URL
+	u.Path = p
+	u.RawPath = ""
+	r2.URL = &u
+
+	return r2
+}
+
+//DefaultStaticFileHandler is an example handler for serving static files using the
+//package level saved config.
+func DefaultStaticFileHandler(cacheDays int, pathToStaticFiles string) http.Handler {
+	return config.StaticFileHandler(cacheDays, pathToStaticFiles)
+}
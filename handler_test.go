@@ -0,0 +1,571 @@
+package cachebusting
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandlerOnServe(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	var info ServeInfo
+	h := c.Handler(HandlerOptions{
+		CacheDays: 1,
+		OnServe:   func(i ServeInfo) { info = i },
+	})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Cache busting file served from memory is reported.
+	urlPath := c.StaticFiles[0].cacheBustURLPath
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, urlPath, nil))
+	if info.Path != urlPath || info.URLPath != "/static/js/script.min.js" || info.Source != "memory" || info.Status != http.StatusOK || info.Bytes != int64(len("console.log(1);")) {
+		t.Fatal("Serve info not reported as expected", info)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unknown file is reported with a 404.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/static/js/missing.js", nil))
+	if info.URLPath != "" || info.Source != "fs" || info.Status != http.StatusNotFound {
+		t.Fatal("Serve info not reported as expected", info)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+//testTracer records the spans created for testing.
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &testSpan{name: name, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	ended bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *testSpan) End()                               { s.ended = true }
+
+func TestHandlerTracer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A span is created and ended for each request with attributes set.
+	tracer := &testTracer{}
+	h := c.Handler(HandlerOptions{Tracer: tracer})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, c.StaticFiles[0].cacheBustURLPath, nil))
+
+	if len(tracer.spans) != 1 || !tracer.spans[0].ended {
+		t.Fatal("Span not created and ended", tracer.spans)
+		return
+	}
+	attrs := tracer.spans[0].attrs
+	if attrs[attrAsset] != "/static/js/script.min.js" || attrs[attrSource] != "memory" || attrs[attrSize] != int64(len("console.log(1);")) {
+		t.Fatal("Span attributes not set as expected", attrs)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerRange(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/video/preview.mp4": {Data: []byte("0123456789")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	h := c.Handler(HandlerOptions{})
+	urlPath := c.StaticFiles[0].cacheBustURLPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Single range returns just the requested bytes.
+	req := httptest.NewRequest(http.MethodGet, urlPath, nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "2345" || rec.Header().Get("Content-Range") != "bytes 2-5/10" {
+		t.Fatal("Single range not served as expected", rec.Code, rec.Header(), rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Multiple ranges return a multipart response.
+	req = httptest.NewRequest(http.MethodGet, urlPath, nil)
+	req.Header.Set("Range", "bytes=0-1,8-9")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || !strings.HasPrefix(rec.Header().Get("Content-Type"), "multipart/byteranges") {
+		t.Fatal("Multiple ranges not served as expected", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unsatisfiable range is rejected.
+	req = httptest.NewRequest(http.MethodGet, urlPath, nil)
+	req.Header.Set("Range", "bytes=20-30")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatal("Unsatisfiable range not rejected", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerDiskLookup(t *testing.T) {
+	//original files stored in a directory structure that doesn't match the URL paths.
+	dir := t.TempDir()
+	p := filepath.Join(dir, "assets", "app.js")
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/app.js"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Cache busting copy on disk is served by looking up its location.
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.StaticFiles[0].cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1);" || rec.Header().Get("X-Static-Served-From") != "disk" {
+		t.Fatal("Copy on disk not served as expected", rec.Code, rec.Header(), rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerRoots(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	h := c.Handler(HandlerOptions{
+		Roots: map[string]fs.FS{
+			"/static/":        fstest.MapFS{"vendor.js": {Data: []byte("static")}},
+			"/static/vendor/": fstest.MapFS{"vendor.js": {Data: []byte("nested")}},
+			"/assets":         fstest.MapFS{"img/logo.png": {Data: []byte("logo")}},
+		},
+	})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files are served from the filesystem with the longest matching prefix.
+	tests := map[string]string{
+		"/static/vendor.js":        "static",
+		"/static/vendor/vendor.js": "nested",
+		"/assets/img/logo.png":     "logo",
+	}
+	for urlPath, expected := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urlPath, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != expected {
+			t.Fatal("File not served from expected root", urlPath, rec.Code, rec.Body.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Cache busting files are still served and unmatched prefixes are not found.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.StaticFiles[0].cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1);" {
+		t.Fatal("Cache busting file not served", rec.Code)
+		return
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other/file.js", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatal("Unmatched prefix should not be found", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerVendor(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "jquery-3.7.1.min.js")
+	err := os.WriteFile(p, []byte("jquery"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	jquery := NewStaticFile(p, "/static/js/jquery-3.7.1.min.js")
+	jquery.Vendor = true
+	c := NewOnDiskConfig(jquery)
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Vendor file is not copied and is served as-is with caching.
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatal("Vendor file should not have been copied", entries, err)
+		return
+	}
+	if c.GetURLPairs()["jquery-3.7.1.min.js"] != jquery.URLPath {
+		t.Fatal("Vendor file should be mapped to itself", c.GetURLPairs())
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, jquery.URLPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "jquery" || !strings.Contains(rec.Header().Get("Cache-Control"), "max-age=86400") {
+		t.Fatal("Vendor file not served as expected", rec.Code, rec.Header(), rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerGoneForUnknownCopies(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	old := "/static/js/ABCDEF0123456789.script.min.js"
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//By default, an unknown copy is responded to with a 404.
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, old, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatal("Unknown copy should not have been found", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An unknown copy is gone and links to the current copy.
+	h := c.Handler(HandlerOptions{CacheDays: 1, GoneForUnknownCopies: true})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, old, nil))
+	if rec.Code != http.StatusGone || rec.Header().Get("Link") != "<"+c.StaticFiles[0].cacheBustURLPath+">; rel=\"canonical\"" {
+		t.Fatal("Unknown copy not responded to as expected", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The copy matchers are built by Create(), not per request.
+	if m := c.currentLookup().copyMatchers; len(m) != 1 || m[0] == nil || !m[0]("ABCDEF0123456789.script.min.js") {
+		t.Fatal("Copy matchers not built as expected")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files not named like a copy are still served or not found as usual.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/js/script.min.js", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatal("Original file should have been served", rec.Code)
+		return
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/js/other.js", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatal("Unknown file should not have been found", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerStripPrefix(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	u := c.StaticFiles[0].cacheBustURLPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The prefix is added back to the path when provided.
+	req := httptest.NewRequest(http.MethodGet, u, nil)
+	req.URL.Path = strings.TrimPrefix(u, "/static")
+	req.RequestURI = ""
+
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1, StripPrefix: "/static"}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Static-Served-From") != "memory" {
+		t.Fatal("File not served with prefix added", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The original request path is checked when the prefix was removed by http.StripPrefix.
+	rec = httptest.NewRecorder()
+	http.StripPrefix("/static", c.Handler(HandlerOptions{CacheDays: 1})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Static-Served-From") != "memory" {
+		t.Fatal("File not served using original request path", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerAuthorize(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+		"static/js/admin.min.js":  {Data: []byte("console.log(2);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	h := c.Handler(HandlerOptions{
+		CacheDays: 1,
+		Authorize: func(r *http.Request) bool {
+			if !strings.HasSuffix(r.URL.Path, "admin.min.js") {
+				return true
+			}
+			return r.Header.Get("X-Internal") == "true"
+		},
+	})
+
+	admin, _ := c.findByOriginalName("admin.min.js")
+	script, _ := c.findByOriginalName("script.min.js")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unrestricted files are served.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, script.cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatal("File should have been served", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Restricted files are denied, whether the copy or original is requested.
+	for _, p := range []string{admin.cacheBustURLPath, admin.URLPath} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Fatal("File should have been denied", p, rec.Code)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Restricted files are served to authorized requests.
+	req := httptest.NewRequest(http.MethodGet, admin.cacheBustURLPath, nil)
+	req.Header.Set("X-Internal", "true")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(2);" {
+		t.Fatal("File should have been served", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerHeaders(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	u := c.StaticFiles[0].cacheBustURLPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//nosniff is set by default, on files and errors.
+	for _, p := range []string{u, "/static/js/missing.js", "/static/../secret"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = p
+		c.Handler(HandlerOptions{CacheDays: 1}).ServeHTTP(rec, req)
+		if rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Fatal("nosniff not set", p, rec.Code, rec.Header())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Headers are added, and a default can be removed.
+	rec := httptest.NewRecorder()
+	h := c.Handler(HandlerOptions{
+		CacheDays: 1,
+		Headers: map[string]string{
+			"Cross-Origin-Resource-Policy": "same-site",
+			"X-Content-Type-Options":       "",
+		},
+	})
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatal("File not served", rec.Code)
+		return
+	}
+	if rec.Header().Get("Cross-Origin-Resource-Policy") != "same-site" {
+		t.Fatal("Header not set", rec.Header())
+		return
+	}
+	if _, ok := rec.Header()["X-Content-Type-Options"]; ok {
+		t.Fatal("Default header should not have been set", rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerCacheControl(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	u := c.StaticFiles[0].cacheBustURLPath
+
+	roots := map[string]fs.FS{
+		"/static/": fstest.MapFS{"vendor.js": {Data: []byte("vendor")}},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Cache busting copies are immutable, other files are revalidated.
+	h := c.Handler(HandlerOptions{CacheDays: 1, Roots: roots})
+	tests := map[string]string{
+		u:                   "no-transform,public,max-age=86400,immutable",
+		"/static/vendor.js": "no-cache",
+	}
+	for urlPath, expected := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urlPath, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != expected {
+			t.Fatal("Cache-Control not as expected", urlPath, rec.Code, rec.Header().Get("Cache-Control"))
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The fallback policy can be set, and caching can be disabled for copies.
+	h = c.Handler(HandlerOptions{Roots: roots, FallbackCacheControl: "public,max-age=60"})
+	tests = map[string]string{
+		u:                   "no-transform,public,max-age=0",
+		"/static/vendor.js": "public,max-age=60",
+	}
+	for urlPath, expected := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urlPath, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != expected {
+			t.Fatal("Cache-Control not as expected", urlPath, rec.Code, rec.Header().Get("Cache-Control"))
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies are revalidated in development since the original file is served.
+	c.Development = true
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1, Roots: roots}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+	if rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatal("Cache-Control not as expected in development", rec.Header().Get("Cache-Control"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func BenchmarkHandler(b *testing.B) {
+	files := benchmarkFiles(b, 1000)
+	c := NewOnDiskConfig(files...)
+	c.UseMemory = true
+	err := c.Create()
+	if err != nil {
+		b.Fatal(err)
+		return
+	}
+	u := c.StaticFiles[len(c.StaticFiles)-1].cacheBustURLPath
+	h := c.Handler(HandlerOptions{CacheDays: 1})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+			if rec.Code != http.StatusOK {
+				b.Fatal("File not served", rec.Code)
+				return
+			}
+		}
+	})
+}
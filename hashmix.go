@@ -0,0 +1,36 @@
+package cachebusting
+
+import (
+	"crypto/sha256"
+)
+
+//hashMix returns the data mixed into the hash of a static file along with the file's
+//contents, or a blank string if the hash is based on the contents alone. See HashSalt and
+//HashURLPath.
+func (c *Config) hashMix(s StaticFile) string {
+	if !c.HashURLPath {
+		return c.HashSalt
+	}
+	if c.HashSalt == "" {
+		return s.URLPath
+	}
+
+	return c.HashSalt + "\x00" + s.URLPath
+}
+
+//nameHash returns the hash used in the name of the cache busting copy of a static file
+//given the hash of the file's contents. The hash of the contents is used as-is unless
+//other data is mixed in, see hashMix. The integrity of a file is always the hash of the
+//contents alone since this is what the browser verifies.
+func (c *Config) nameHash(s StaticFile, h [sha256.Size]byte) string {
+	mix := c.hashMix(s)
+	if mix == "" {
+		return upperHex(h)
+	}
+
+	b := make([]byte, 0, len(mix)+1+len(h))
+	b = append(b, mix...)
+	b = append(b, 0)
+	b = append(b, h[:]...)
+	return upperHex(sha256.Sum256(b))
+}
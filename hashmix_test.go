@@ -0,0 +1,153 @@
+package cachebusting
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashURLPath(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	data := []byte("console.log(1);")
+	err := os.WriteFile(p, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//By default, the same file served from two URL paths gets the same hash.
+	c := NewConfig()
+	c.UseMemory = true
+	c.StaticFiles = []StaticFile{
+		NewStaticFile(p, "/tenant-a/script.min.js"),
+		NewStaticFile(p, "/tenant-b/script.min.js"),
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if c.StaticFiles[0].hash != c.StaticFiles[1].hash {
+		t.Fatal("Hashes should match", c.StaticFiles[0].hash, c.StaticFiles[1].hash)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//With HashURLPath, each URL path gets a different hash but the integrity is still
+	//of the file's contents.
+	c = NewConfig()
+	c.UseMemory = true
+	c.HashURLPath = true
+	c.StaticFiles = []StaticFile{
+		NewStaticFile(p, "/tenant-a/script.min.js"),
+		NewStaticFile(p, "/tenant-b/script.min.js"),
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	a, b := c.StaticFiles[0], c.StaticFiles[1]
+	if a.hash == b.hash {
+		t.Fatal("Hashes should not match", a.hash)
+		return
+	}
+	if a.hash == upperHex(sha256.Sum256(data)) {
+		t.Fatal("URL path not mixed into hash")
+		return
+	}
+
+	h := sha256.Sum256(data)
+	integrity := "sha256-" + base64.StdEncoding.EncodeToString(h[:])
+	if a.integrity != integrity || b.integrity != integrity {
+		t.Fatal("Integrity should be of the file's contents", a.integrity, b.integrity)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Verify accounts for the mixed in URL path.
+	err = c.Verify()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHashSalt(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	data := []byte("console.log(1);")
+	err := os.WriteFile(p, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	create := func(salt string) (StaticFile, error) {
+		c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+		c.MappingFile = true
+		c.HashSalt = salt
+		err := c.Create()
+		if err != nil {
+			return StaticFile{}, err
+		}
+		return c.StaticFiles[0], nil
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The salt changes the hash but not the integrity.
+	unsalted, err := create("")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	salted, err := create("release-1")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if salted.hash == unsalted.hash {
+		t.Fatal("Salt not mixed into hash", salted.hash)
+		return
+	}
+	if salted.integrity != unsalted.integrity {
+		t.Fatal("Integrity should not depend on salt", salted.integrity, unsalted.integrity)
+		return
+	}
+	if _, err := os.Stat(salted.cacheBustLocalPath); err != nil {
+		t.Fatal("Copy not saved for salted hash", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The same salt gives the same hash, and a different salt a different hash, even
+	//though the mapping file recorded the hash for the prior salt.
+	again, err := create("release-1")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if again.hash != salted.hash {
+		t.Fatal("Hash should not change for same salt", again.hash, salted.hash)
+		return
+	}
+
+	next, err := create("release-2")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if next.hash == salted.hash || next.hash == unsalted.hash {
+		t.Fatal("Hash should change for a new salt", next.hash)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,235 @@
+package cachebusting
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+)
+
+//errors
+var (
+	//ErrOriginalChanged is returned when a cache busting copy is being recreated but the
+	//original file's contents no longer match the contents the copy was created from.
+	ErrOriginalChanged = errors.New("cachebusting: original file changed since cache busting files were created")
+
+	//ErrCopyMissing is returned by Verify() when a cache busting copy does not exist.
+	ErrCopyMissing = errors.New("cachebusting: cache busting copy missing")
+
+	//ErrCopyCorrupted is returned by Verify() when the contents of a cache busting copy
+	//do not match the hash in the copy's name.
+	ErrCopyCorrupted = errors.New("cachebusting: cache busting copy corrupted")
+)
+
+//rebuildCopy returns the data of a static file's cache busting copy rebuilt from the
+//original file. Changes made to the data when the copy was created, i.e. rewriting the
+//URLs in CSS files and web app manifests or injecting the ManifestPlaceholder mapping, are
+//made again using the cache busting URLs of the files being served. ErrOriginalChanged is
+//returned if the rebuilt data doesn't match the hash calculated when Create() was called
+//since the copy's name would no longer match its contents.
+func (c *Config) rebuildCopy(s StaticFile) ([]byte, error) {
+	data, err := c.readOriginal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.modifies(s) {
+		files := c.servedFiles()
+		byURLPath := make(map[string]int, len(files))
+		for i, f := range files {
+			byURLPath[f.URLPath] = i
+		}
+		urlOf := func(i int) string {
+			return c.cacheBustURL(files[i])
+		}
+
+		k, ok := byURLPath[s.URLPath]
+		if !ok {
+			return nil, &FileError{Path: s.LocalPath, Err: ErrOriginalChanged}
+		}
+
+		if (c.RegisterFonts || c.RewriteCSSURLs) && isCSS(s) {
+			data = c.rewriteCSS(files, k, data, byURLPath, urlOf)
+		}
+		if isWebAppManifest(s) && !s.Vendor {
+			data, err = c.rewriteWebAppManifest(files, k, data, byURLPath, urlOf)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if s.ManifestPlaceholder != "" {
+			b, err := manifestMapping(files, urlOf)
+			if err != nil {
+				return nil, err
+			}
+			data = bytes.ReplaceAll(data, []byte(s.ManifestPlaceholder), b)
+		}
+	}
+
+	if c.nameHash(s, sha256.Sum256(data)) != s.hash {
+		return nil, &FileError{Path: s.LocalPath, Err: ErrOriginalChanged}
+	}
+
+	return data, nil
+}
+
+//healCopy recreates the on disk cache busting copy of a static file from the original
+//file, see rebuildCopy().
+func (c *Config) healCopy(s StaticFile) error {
+	data, err := c.rebuildCopy(s)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(s.cacheBustLocalPath, data, c.TempDir)
+}
+
+//serveHealed handles a request for a cache busting copy saved to disk that has gone
+//missing, for example because an external process cleaned up the directory. The copy is
+//recreated if possible. If the copy cannot be recreated because the original file has
+//changed, the original file is served without caching so the browser doesn't keep the
+//wrong contents for the cache busting URL path. True is returned if a response was
+//written.
+func (c *Config) serveHealed(w http.ResponseWriter, r *http.Request, s StaticFile) bool {
+	if s.cacheBustLocalPath == "" {
+		return false
+	}
+
+	_, err := os.Stat(s.cacheBustLocalPath)
+	if !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+
+	err = c.healCopy(s)
+	if err == nil {
+		log.Println("cachebusting.StaticFileHandler", "recreated missing cache busting file", s.cacheBustLocalPath)
+		return false
+	} else if !errors.Is(err, ErrOriginalChanged) {
+		log.Println("cachebusting.StaticFileHandler", "could not recreate missing cache busting file", s.cacheBustLocalPath, err)
+		return false
+	}
+
+	log.Println("cachebusting.StaticFileHandler", "cache busting file missing and original changed, serving original", s.LocalPath)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Static-Served-From", "disk (original)")
+	http.ServeFile(w, r, s.LocalPath)
+	return true
+}
+
+//hasCopy returns true if a static file has a cache busting copy that can be checked and
+//recreated. Files mapped to themselves, in DevelopmentPassthrough or NoCopy mode or vendor
+//files on disk, are served from the original file so there is no copy.
+func hasCopy(s StaticFile) bool {
+	return s.cacheBustURLPath != "" && s.hash != "" && s.cacheBustLocalPath != s.LocalPath
+}
+
+//checkCopy checks that the cache busting copy of a static file exists and that its
+//contents match the hash calculated when Create() was called.
+func (c *Config) checkCopy(s StaticFile) error {
+	data := s.fileData
+	if s.streamed || s.evicted {
+		//streamed files are served from the filesystem, and files in the memory cache
+		//are read from the original file when needed, there is no copy to check.
+		return nil
+	} else if !c.inMemory(s) {
+		var err error
+		data, err = limitedRead(os.ReadFile)(s.cacheBustLocalPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return &FileError{Path: s.cacheBustLocalPath, Err: ErrCopyMissing}
+		} else if err != nil {
+			return err
+		}
+	} else if data == nil {
+		return &FileError{Path: s.cacheBustURLPath, Err: ErrCopyMissing}
+	}
+
+	if c.nameHash(s, sha256.Sum256(data)) != s.hash {
+		return &FileError{Path: s.cacheBustURLPath, Err: ErrCopyCorrupted}
+	}
+
+	return nil
+}
+
+//Verify checks that the cache busting copy of each static file exists and has not been
+//modified since Create() was called. The first problem found is returned. Use Repair() to
+//fix any problems. Files without a copy, see hasCopy(), are not checked.
+func (c *Config) Verify() error {
+	for _, s := range c.StaticFiles {
+		if !hasCopy(s) {
+			continue
+		}
+
+		err := c.checkCopy(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Verify checks the cache busting copies for the package level config.
+func Verify() error {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return config.Verify()
+}
+
+//Repair recreates any missing or corrupted cache busting copies from the original files.
+//Valid copies are not touched. This is useful as an admin action or scheduled task to
+//recover from copies being deleted without restarting your app. ErrOriginalChanged is
+//returned if an original file has changed since Create() was called, in which case you
+//should call Create() instead. The number of copies recreated is returned.
+func (c *Config) Repair() (repaired int, err error) {
+	//serve the repaired copies stored in memory.
+	defer func() {
+		if repaired > 0 {
+			c.buildIndex()
+		}
+	}()
+
+	for k, s := range c.StaticFiles {
+		if !hasCopy(s) {
+			continue
+		}
+
+		innerErr := c.checkCopy(s)
+		if innerErr == nil {
+			continue
+		} else if !errors.Is(innerErr, ErrCopyMissing) && !errors.Is(innerErr, ErrCopyCorrupted) {
+			return repaired, innerErr
+		}
+
+		if c.inMemory(s) {
+			data, innerErr := c.rebuildCopy(s)
+			if innerErr != nil {
+				return repaired, innerErr
+			}
+
+			c.StaticFiles[k].fileData = data
+		} else {
+			innerErr = c.healCopy(s)
+			if innerErr != nil {
+				return repaired, innerErr
+			}
+		}
+
+		if c.Debug {
+			log.Println("cachebusting.Repair (debug)", "recreated cache busting file", s.cacheBustURLPath)
+		}
+		repaired++
+	}
+
+	return
+}
+
+//Repair recreates missing or corrupted cache busting copies for the package level config.
+func Repair() (repaired int, err error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	return config.Repair()
+}
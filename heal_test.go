@@ -0,0 +1,384 @@
+package cachebusting
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelfHeal(t *testing.T) {
+	dir := t.TempDir()
+	jsDir := filepath.Join(dir, "static", "js")
+	err := os.MkdirAll(jsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	p := filepath.Join(jsDir, "script.min.js")
+	err = os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	js := NewStaticFile(p, "/static/js/script.min.js")
+	c := NewOnDiskConfig(js)
+	c.SelfHeal = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	h := c.StaticFileHandler(1, dir)
+	urlPath := c.StaticFiles[0].cacheBustURLPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing copy is recreated and served.
+	err = os.Remove(c.StaticFiles[0].cacheBustLocalPath)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urlPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1);" {
+		t.Fatal("Missing copy not recreated", rec.Code, rec.Body.String())
+		return
+	}
+	if _, err := os.Stat(c.StaticFiles[0].cacheBustLocalPath); err != nil {
+		t.Fatal("Copy not saved to disk", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing copy with a changed original serves the original without caching.
+	err = os.Remove(c.StaticFiles[0].cacheBustLocalPath)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urlPath, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(2);" || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatal("Original not served as expected", rec.Code, rec.Header(), rec.Body.String())
+		return
+	}
+	if _, err := os.Stat(c.StaticFiles[0].cacheBustLocalPath); err == nil {
+		t.Fatal("Copy should not have been recreated from changed original")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestVerifyAndRepair(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	js := NewStaticFile(p, "/static/js/script.min.js")
+	c := NewOnDiskConfig(js)
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Valid copies verify and are not repaired.
+	err = c.Verify()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	n, err := c.Repair()
+	if err != nil || n != 0 {
+		t.Fatal("Nothing should have been repaired", n, err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Corrupted copy is detected and repaired.
+	err = os.WriteFile(c.StaticFiles[0].cacheBustLocalPath, []byte("bad"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.Verify()
+	if !errors.Is(err, ErrCopyCorrupted) {
+		t.Fatal("ErrCopyCorrupted should have occured but didn't", err)
+		return
+	}
+	n, err = c.Repair()
+	if err != nil || n != 1 {
+		t.Fatal("Copy should have been repaired", n, err)
+		return
+	}
+	err = c.Verify()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing copy with a changed original cannot be repaired.
+	err = os.Remove(c.StaticFiles[0].cacheBustLocalPath)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.Verify()
+	if !errors.Is(err, ErrCopyMissing) {
+		t.Fatal("ErrCopyMissing should have occured but didn't", err)
+		return
+	}
+	err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	_, err = c.Repair()
+	if !errors.Is(err, ErrOriginalChanged) {
+		t.Fatal("ErrOriginalChanged should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestVerifyWithoutCopies(t *testing.T) {
+	for _, mode := range []string{"passthrough", "nocopy"} {
+		dir := t.TempDir()
+		p := filepath.Join(dir, "script.min.js")
+		err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+
+		c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+		if mode == "passthrough" {
+			c.Development = true
+			c.DevelopmentPassthrough = true
+		} else {
+			c.NoCopy = true
+		}
+		err = c.Create()
+		if err != nil {
+			t.Fatal("Error occured but should not have", mode, err)
+			return
+		}
+
+		//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+		//Files served from the original have no copy to verify or repair, even once the
+		//original changes.
+		err = c.Verify()
+		if err != nil {
+			t.Fatal("Error occured but should not have", mode, err)
+			return
+		}
+
+		err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		err = c.Verify()
+		if err != nil {
+			t.Fatal("Error occured but should not have", mode, err)
+			return
+		}
+
+		repaired, err := c.Repair()
+		if err != nil || repaired != 0 {
+			t.Fatal("Nothing should have been repaired", mode, repaired, err)
+			return
+		}
+		b, err := os.ReadFile(p)
+		if err != nil || string(b) != "console.log(2);" {
+			t.Fatal("Original modified by Repair", mode, err, string(b))
+			return
+		}
+		//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+	}
+}
+
+func TestSelfHealRewritten(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"styles.css":           `body{background:url("bg.png")}`,
+		"bg.png":               "png",
+		"site.webmanifest":     `{"icons":[{"src":"bg.png"}]}`,
+		"app.js":               "var manifest = __MANIFEST__;",
+		"unmodified.script.js": "console.log(1);",
+	}
+	var statics []StaticFile
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		err := os.WriteFile(p, []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+
+		s := NewStaticFile(p, "/static/"+name)
+		if name == "app.js" {
+			s.ManifestPlaceholder = "__MANIFEST__"
+		}
+		statics = append(statics, s)
+	}
+
+	c := NewOnDiskConfig(statics...)
+	c.RewriteCSSURLs = true
+	c.SelfHeal = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	copies := make(map[string][]byte)
+	for _, s := range c.StaticFiles {
+		b, err := os.ReadFile(s.cacheBustLocalPath)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		copies[s.cacheBustLocalPath] = b
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Missing copies whose data was rewritten when created are recreated with the same
+	//data.
+	h := c.StaticFileHandler(1, dir)
+	for _, name := range []string{"styles.css", "site.webmanifest", "app.js"} {
+		s, _ := c.findByOriginalName(name)
+		err = os.Remove(s.cacheBustLocalPath)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, s.cacheBustURLPath, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != string(copies[s.cacheBustLocalPath]) {
+			t.Fatal("Missing copy not recreated", name, rec.Code, rec.Body.String())
+			return
+		}
+		if _, err := os.Stat(s.cacheBustLocalPath); err != nil {
+			t.Fatal("Copy not saved to disk", name, err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRepairRewritten(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"styles.css":           `body{background:url("bg.png")}`,
+		"bg.png":               "png",
+		"site.webmanifest":     `{"icons":[{"src":"bg.png"}]}`,
+		"app.js":               "var manifest = __MANIFEST__;",
+		"unmodified.script.js": "console.log(1);",
+	}
+	var statics []StaticFile
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		err := os.WriteFile(p, []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+
+		s := NewStaticFile(p, "/static/"+name)
+		if name == "app.js" {
+			s.ManifestPlaceholder = "__MANIFEST__"
+		}
+		statics = append(statics, s)
+	}
+
+	c := NewOnDiskConfig(statics...)
+	c.RewriteCSSURLs = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	copies := make(map[string][]byte)
+	for _, s := range c.StaticFiles {
+		b, err := os.ReadFile(s.cacheBustLocalPath)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		copies[s.cacheBustLocalPath] = b
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies whose data was rewritten when created are repaired with the same data.
+	for p := range copies {
+		err = os.Remove(p)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+	n, err := c.Repair()
+	if err != nil || n != len(files) {
+		t.Fatal("Copies should have been repaired", n, err)
+		return
+	}
+	for p, b := range copies {
+		repaired, err := os.ReadFile(p)
+		if err != nil || string(repaired) != string(b) {
+			t.Fatal("Copy not repaired as expected", p, string(repaired), err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies stored in memory whose data was rewritten when created are repaired with the
+	//same data.
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	data := make(map[string][]byte)
+	for k, s := range c.StaticFiles {
+		data[s.URLPath] = s.fileData
+		c.StaticFiles[k].fileData = []byte("bad")
+	}
+	n, err = c.Repair()
+	if err != nil || n != len(files) {
+		t.Fatal("Copies should have been repaired", n, err)
+		return
+	}
+	for _, s := range c.StaticFiles {
+		if string(s.fileData) != string(data[s.URLPath]) {
+			t.Fatal("Copy not repaired as expected", s.URLPath, string(s.fileData))
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
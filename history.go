@@ -0,0 +1,62 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+//outdatedWarning is the value of the Warning header set when a request for an outdated
+//cache busting URL path is served the current version of the file.
+const outdatedWarning = `299 - "cachebusting: outdated file requested, current version served"`
+
+//trimHistory removes duplicates and the current cache busting URL path from the list of
+//previous cache busting URL paths and limits the list to the given length.
+func trimHistory(previous []string, current string, length uint) (trimmed []string) {
+	seen := make(map[string]bool, len(previous))
+	for _, p := range previous {
+		if p == current || seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		trimmed = append(trimmed, p)
+		if uint(len(trimmed)) >= length {
+			break
+		}
+	}
+
+	return
+}
+
+//readHistoryFile reads the previous cache busting URL paths for each file, keyed by the
+//file's original URL path. A missing file is not an error since the file will not exist
+//the first time the app is run.
+func readHistoryFile(p string) (history map[string][]string, err error) {
+	b, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(b, &history)
+	return
+}
+
+//writeHistoryFile saves the current and previous cache busting URL paths for each file,
+//keyed by the file's original URL path, to the config's HistoryFile.
+func (c *Config) writeHistoryFile() error {
+	history := make(map[string][]string, len(c.StaticFiles))
+	for _, s := range c.StaticFiles {
+		history[s.URLPath] = append([]string{s.cacheBustURLPath}, s.previousURLPaths...)
+	}
+
+	b, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.HistoryFile, b, 0644)
+}
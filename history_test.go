@@ -0,0 +1,104 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	js := NewStaticFile(p, "/static/js/script.min.js")
+	c := NewOnDiskConfig(js)
+	c.UseMemory = true
+	c.HistoryLength = 2
+	c.HistoryFile = filepath.Join(dir, "history.json")
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	first := c.StaticFiles[0].cacheBustURLPath
+
+	//change the file and recreate.
+	err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	second := c.StaticFiles[0].cacheBustURLPath
+	if first == second {
+		t.Fatal("Cache busting URL path should have changed")
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Outdated URL path returns the current data.
+	b, err := c.FindFileDataByCacheBustURLPath(first)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if string(b) != "console.log(2);" {
+		t.Fatal("Current file data not returned for outdated URL path", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Handler sets Warning header for outdated URL path only.
+	h := c.StaticFileHandler(1, dir)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, first, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Warning") == "" || rec.Body.String() != "console.log(2);" {
+		t.Fatal("Outdated file not served as expected", rec.Code, rec.Header())
+		return
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, second, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Warning") != "" {
+		t.Fatal("Current file not served as expected", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//History is loaded from the history file, simulating an app restart.
+	err = os.WriteFile(p, []byte("console.log(3);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c = NewOnDiskConfig(js)
+	c.UseMemory = true
+	c.HistoryLength = 2
+	c.HistoryFile = filepath.Join(dir, "history.json")
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	previous := c.StaticFiles[0].previousURLPaths
+	if len(previous) != 2 || previous[0] != second || previous[1] != first {
+		t.Fatal("History not loaded and trimmed as expected", previous)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,17 @@
+package cachebusting
+
+//withoutExcluded removes the static files that are not included in this build or
+//environment, see StaticFile.Include, from a list of static files.
+func withoutExcluded(files []StaticFile) (kept, excluded []StaticFile) {
+	kept = make([]StaticFile, 0, len(files))
+	for _, s := range files {
+		if s.Include != nil && !s.Include() {
+			excluded = append(excluded, s)
+			continue
+		}
+
+		kept = append(kept, s)
+	}
+
+	return
+}
@@ -0,0 +1,57 @@
+package cachebusting
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticFileInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	analytics := false
+
+	c := NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[0].Include = func() bool { return true }
+	c.StaticFiles = append(c.StaticFiles, StaticFile{
+		LocalPath: "static/js/analytics.js",
+		URLPath:   "/static/js/analytics.js",
+		Include:   func() bool { return analytics },
+	})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files that aren't included are removed, even if they don't exist.
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(c.StaticFiles) != 1 || c.StaticFiles[0].URLPath != "/static/js/script.min.js" {
+		t.Fatal("Static files not as expected", c.StaticFiles)
+		return
+	}
+	if _, found := c.findByOriginalName("analytics.js"); found {
+		t.Fatal("File that isn't included should not be found")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files that are included are created.
+	analytics = true
+	fsys["static/js/analytics.js"] = &fstest.MapFile{Data: []byte("track();")}
+	c = NewFSConfig(fsys, "static", "/static")
+	for k := range c.StaticFiles {
+		c.StaticFiles[k].Include = func() bool { return analytics }
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if s, found := c.findByOriginalName("analytics.js"); !found || s.cacheBustURLPath == "" {
+		t.Fatal("Included file not created", s)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,132 @@
+package cachebusting
+
+import (
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+//indexTemplate is the HTML page output by IndexHandler().
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Cache busting files</title>
+<style>
+body{font-family:sans-serif;font-size:14px;margin:1em}
+table{border-collapse:collapse}
+th,td{border:1px solid #ccc;padding:4px 8px;text-align:left}
+td.num{text-align:right}
+code{font-size:12px}
+</style>
+</head>
+<body>
+<h1>Cache busting files</h1>
+<p>{{len .}} files</p>
+<table>
+<thead><tr><th>Original URL path</th><th>Cache busting URL</th><th>Size</th><th>Hash</th><th>Content type</th><th></th></tr></thead>
+<tbody>
+{{range .}}<tr>
+<td>{{.URLPath}}</td>
+<td><a href="{{.URL}}">{{.URL}}</a></td>
+<td class="num">{{.Size}}</td>
+<td><code>{{.Hash}}</code></td>
+<td>{{.ContentType}}</td>
+<td><button type="button" data-url="{{.URL}}">Copy URL</button></td>
+</tr>
+{{end}}</tbody>
+</table>
+<script>
+document.addEventListener("click", function(e) {
+	var u = e.target.getAttribute("data-url");
+	if (u) {
+		navigator.clipboard.writeText(new URL(u, location.href).href);
+	}
+});
+</script>
+</body>
+</html>
+`))
+
+//indexFile is a row in the page output by IndexHandler().
+type indexFile struct {
+	URLPath     string
+	URL         string
+	Size        int64
+	Hash        string
+	ContentType string
+}
+
+//IndexHandler returns an http.Handler that responds with an HTML page listing each static
+//file's cache busting URL, with the size, hash, and content type of the file, and a button
+//to copy the URL. This is useful for QA to verify exactly which files a build serves.
+//Serve this on a route such as /cachebust/index.html.
+//
+//If token is provided, requests must include the token in the Authorization header,
+//i.e.: Authorization: Bearer {token}. Leave token blank to not require authorization,
+//i.e. when the route is already protected by your app's authentication.
+func (c *Config) IndexHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkBearerToken(w, r, token) {
+			return
+		}
+
+		files := c.servedFiles()
+		rows := make([]indexFile, 0, len(files))
+		for _, s := range files {
+			if s.cacheBustURLPath == "" {
+				continue
+			}
+
+			rows = append(rows, indexFile{
+				URLPath:     s.URLPath,
+				URL:         c.urlFor(s),
+				Size:        c.copySize(s),
+				Hash:        s.hash,
+				ContentType: contentType(path.Ext(s.URLPath)),
+			})
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].URLPath < rows[j].URLPath
+		})
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		err := indexTemplate.Execute(w, rows)
+		if err != nil {
+			log.Println("cachebusting.IndexHandler", "could not write index", err)
+		}
+	})
+}
+
+//DefaultIndexHandler returns the index handler for the package level config.
+func DefaultIndexHandler(token string) http.Handler {
+	return config.IndexHandler(token)
+}
+
+//copySize returns the size of a static file's cache busting copy, or -1 if the size is
+//not known, i.e. the copy is missing.
+func (c *Config) copySize(s StaticFile) int64 {
+	var info fs.FileInfo
+	var err error
+	switch {
+	case s.streamed, s.evicted && c.usesFS():
+		info, err = fs.Stat(c.sourceFS(), filepath.ToSlash(s.LocalPath))
+	case s.evicted:
+		info, err = os.Stat(s.LocalPath)
+	case c.inMemory(s):
+		return int64(len(s.fileData))
+	default:
+		info, err = os.Stat(s.cacheBustLocalPath)
+	}
+	if err != nil {
+		return -1
+	}
+
+	return info.Size()
+}
@@ -0,0 +1,66 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIndexHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	script, _ := c.findByOriginalName("script.min.js")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A token is required if provided.
+	h := c.IndexHandler("secret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cachebust/index.html", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatal("Request without token should not be authorized", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Each file is listed with its details.
+	req := httptest.NewRequest(http.MethodGet, "/cachebust/index.html", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatal("Index not served", rec.Code, rec.Header())
+		return
+	}
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`<a href="` + script.cacheBustURLPath + `">`,
+		`<td class="num">` + strconv.Itoa(len("console.log(1);")) + `</td>`,
+		script.hash,
+		"javascript",
+		`data-url="` + script.cacheBustURLPath + `"`,
+		"/static/css/styles.min.css",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatal("Index missing", expected, body)
+			return
+		}
+	}
+	if strings.Index(body, "/static/css/styles.min.css") > strings.Index(body, "/static/js/script.min.js") {
+		t.Fatal("Files not sorted by URL path")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
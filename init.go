@@ -0,0 +1,73 @@
+package cachebusting
+
+//Stats is a summary of the cache busting files created.
+type Stats struct {
+	//Files is the number of static files.
+	Files int
+
+	//InMemory is the number of cache busting copies stored in memory.
+	InMemory int
+
+	//OnDisk is the number of cache busting copies saved to disk.
+	OnDisk int
+
+	//Vendor is the number of vendor files, which are served as-is.
+	Vendor int
+
+	//Streamed is the number of files served directly from an embedded or other filesystem
+	//rather than from a copy in memory. See StreamMinBytes.
+	Streamed int
+
+	//Evicted is the number of cache busting copies stored in the memory cache, whose data
+	//may be removed from memory and read again when needed, see MemoryCacheBytes. These
+	//are not included in InMemory or MemoryBytes.
+	Evicted int
+
+	//MemoryBytes is the total size of the cache busting copies stored in memory.
+	MemoryBytes int64
+}
+
+//Stats returns a summary of the cache busting files created.
+func (c *Config) Stats() (s Stats) {
+	s.Files = len(c.StaticFiles)
+
+	for _, f := range c.StaticFiles {
+		if f.Vendor {
+			s.Vendor++
+		}
+		if f.cacheBustURLPath == "" {
+			continue
+		}
+
+		if f.streamed {
+			s.Streamed++
+		} else if f.evicted {
+			s.Evicted++
+		} else if f.fileData != nil {
+			s.InMemory++
+			s.MemoryBytes += int64(len(f.fileData))
+		} else {
+			s.OnDisk++
+		}
+	}
+
+	return
+}
+
+//Init creates the cache busting files for cfg and then saves cfg as the package level
+//config. This replaces calling one of the Default...Config() funcs, Create(), and
+//GetConfig() separately. The package level config is only replaced if Create() succeeds,
+//so requests being served using the package level config are not affected by a failed
+//Init. Init is safe to call from multiple goroutines.
+func Init(cfg *Config) (s Stats, err error) {
+	err = cfg.Create()
+	if err != nil {
+		return
+	}
+
+	configMu.Lock()
+	config = cfg.copy()
+	configMu.Unlock()
+
+	return cfg.Stats(), nil
+}
@@ -0,0 +1,82 @@
+package cachebusting
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInit(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/app.js":   {Data: []byte("console.log(1);")},
+		"static/css/app.css": {Data: []byte("body{}")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Config is created and saved as the package level config.
+	stats, err := Init(NewFSConfig(fsys, "static", "/static"))
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if stats.Files != 2 || stats.InMemory != 2 || stats.MemoryBytes != int64(len("console.log(1);")+len("body{}")) {
+		t.Fatal("Stats not returned as expected", stats)
+		return
+	}
+	if len(GetURLPairs()) != 2 {
+		t.Fatal("Package level config not saved", GetURLPairs())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A failed Init doesn't replace the package level config.
+	_, err = Init(NewConfig())
+	if !errors.Is(err, ErrNoFiles) {
+		t.Fatal("ErrNoFiles should have occured but didn't", err)
+		return
+	}
+	if len(GetURLPairs()) != 2 {
+		t.Fatal("Package level config should not have been replaced", GetURLPairs())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Init is safe to call concurrently.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Init(NewFSConfig(fsys, "static", "/static"))
+			if err != nil {
+				t.Error("Error occured but should not have", err)
+			}
+		}()
+	}
+	wg.Wait()
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestCreateNotInitialized(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A zero value config returns an error saying the config wasn't set up.
+	var c Config
+	err := c.Create()
+	if !errors.Is(err, ErrConfigNotInitialized) {
+		t.Fatal("ErrConfigNotInitialized should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An initialized config without files still returns ErrNoFiles.
+	err = NewConfig().Create()
+	if !errors.Is(err, ErrNoFiles) {
+		t.Fatal("ErrNoFiles should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,40 @@
+package cachebusting
+
+import (
+	"context"
+	"net/http"
+)
+
+//assetKey is the context key the original URL path of a requested static file is stored
+//under. See LabelAssets().
+type assetKey struct{}
+
+//LabelAssets returns middleware that stores the URL path of the original static file
+//being requested, i.e. /static/js/script.min.js rather than the ever-changing cache
+//busting URL path, in the request's context. Use AssetFromContext() in your logging
+//middleware to aggregate requests by asset. The middleware must wrap your logging
+//middleware, for example LabelAssets(logger(StaticFileHandler(...))), so that the
+//logging middleware receives the labelled request.
+func (c *Config) LabelAssets(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cleaned, ok := cleanRequestPath(r.URL.Path); ok {
+			if s, _, found := c.findRequested(withPath(r, cleaned)); found {
+				r = r.WithContext(context.WithValue(r.Context(), assetKey{}, s.URLPath))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+//LabelAssets returns the asset labelling middleware for the package level config.
+func LabelAssets(next http.Handler) http.Handler {
+	return config.LabelAssets(next)
+}
+
+//AssetFromContext returns the URL path of the original static file stored in ctx by
+//LabelAssets(). False is returned if the request was not for a known static file.
+func AssetFromContext(ctx context.Context) (urlPath string, ok bool) {
+	urlPath, ok = ctx.Value(assetKey{}).(string)
+	return
+}
@@ -0,0 +1,44 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLabelAssets(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	var asset string
+	var labelled bool
+	h := c.LabelAssets(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asset, labelled = AssetFromContext(r.Context())
+	}))
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A request for a cache busting file is labelled with the original URL path.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, c.StaticFiles[0].cacheBustURLPath, nil))
+	if !labelled || asset != "/static/js/script.min.js" {
+		t.Fatal("Request not labelled as expected", asset, labelled)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A request for an unknown file is not labelled.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/static/js/other.js", nil))
+	if labelled {
+		t.Fatal("Request should not have been labelled", asset)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
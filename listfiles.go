@@ -0,0 +1,78 @@
+package cachebusting
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+//FileInfoLite is the details about a file returned by ListFiles().
+type FileInfoLite struct {
+	//Name is the path to the file in the filesystem, i.e. "static/js/script.min.js".
+	Name string
+
+	//Size is the size of the file in bytes.
+	Size int64
+
+	//ModTime is the file's modification time. This is the zero time for files in an
+	//embed.FS.
+	ModTime time.Time
+}
+
+//ListFiles returns the files in a filesystem, sorted by path, whether an embed.FS, a
+//directory via os.DirFS, or another fs.FS. Use this for diagnostics, i.e. to confirm which
+//files are embedded with your //go:embed directives, or to choose files to cache bust.
+//Symlinks to directories are followed.
+//
+//include and exclude are patterns, see path.Match, i.e. "static/js/*.js" or "*.map". A
+//pattern without a "/" is matched against each file's name, otherwise the pattern is
+//matched against each file's path. If include is provided, only files matching at least
+//one pattern are returned. Files matching an exclude pattern are never returned.
+func ListFiles(fsys fs.FS, include, exclude []string) (files []FileInfoLite, err error) {
+	//check the patterns first so a bad pattern is always returned, even if no files exist.
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		_, err = path.Match(pattern, "")
+		if err != nil {
+			return nil, &FileError{Path: pattern, Err: err}
+		}
+	}
+
+	err = walkFiles(fsys, ".", func(p string) error {
+		if len(include) > 0 && !matchAny(include, p) {
+			return nil
+		}
+		if matchAny(exclude, p) {
+			return nil
+		}
+
+		info, err := fs.Stat(fsys, p)
+		if err != nil {
+			return &FileError{Path: p, Err: err}
+		}
+
+		files = append(files, FileInfoLite{
+			Name:    p,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	return
+}
+
+//matchAny returns true if the path of a file matches one of the patterns, see ListFiles().
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		name := p
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(p)
+		}
+
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package cachebusting
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestListFiles(t *testing.T) {
+	mod := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":     {Data: []byte("console.log(1);"), ModTime: mod},
+		"static/js/script.min.js.map": {Data: []byte("{}")},
+		"static/css/styles.min.css":   {Data: []byte("body{}")},
+		"templates/index.html":        {Data: []byte("<html></html>")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//All files are listed, sorted by path, with their sizes and mod times.
+	files, err := ListFiles(fsys, nil, nil)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(files) != 4 || files[0].Name != "static/css/styles.min.css" || files[3].Name != "templates/index.html" {
+		t.Fatal("Files not listed as expected", files)
+		return
+	}
+	if files[1].Name != "static/js/script.min.js" || files[1].Size != 15 || !files[1].ModTime.Equal(mod) {
+		t.Fatal("File details not as expected", files[1])
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files are filtered by path and name.
+	files, err = ListFiles(fsys, []string{"static/*/*"}, []string{"*.map"})
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(files) != 2 {
+		t.Fatal("Files not filtered as expected", files)
+		return
+	}
+	for _, f := range files {
+		if path.Ext(f.Name) == ".map" || path.Dir(f.Name) == "templates" {
+			t.Fatal("File should have been filtered out", f.Name)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Bad patterns are returned as an error.
+	_, err = ListFiles(fsys, nil, []string{"[a-"})
+	if !errors.Is(err, path.ErrBadPattern) {
+		t.Fatal("Bad pattern should have been returned", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
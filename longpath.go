@@ -0,0 +1,61 @@
+package cachebusting
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	//maxFilenameLength is the longest name of a file most filesystems, i.e. NTFS, ext4,
+	//and APFS, allow.
+	maxFilenameLength = 255
+
+	//maxWindowsPathLength is the longest path Windows allows unless long paths are enabled
+	//or the path uses the \\?\ prefix. This is MAX_PATH less the terminating null.
+	maxWindowsPathLength = 259
+)
+
+//limitPathLength is true if the length of the full path of each cache busting copy saved
+//to disk should be limited to maxWindowsPathLength. This is a variable for testing.
+var limitPathLength = runtime.GOOS == "windows"
+
+//ErrPathTooLong is returned when the name or full path of a cache busting copy saved to
+//disk would be longer than the operating system allows. Use a shorter HashLength, shorter
+//original filenames, or store the copies in memory with UseMemory.
+var ErrPathTooLong = errors.New("cachebusting: cache busting path too long")
+
+//checkPathLengths makes sure the cache busting copy of each file saved to disk can be
+//saved given the length of the copy's name and full path. This is checked prior to saving
+//any copy so that Create() fails with a clear error rather than an error from the
+//operating system partway through saving copies.
+//
+//hashLengths is in the same order as StaticFiles.
+func (c *Config) checkPathLengths(hashLengths []uint) error {
+	for k, s := range c.StaticFiles {
+		if s.Vendor || c.inMemory(s) {
+			continue
+		}
+
+		name := c.cacheBustFilename(s.hash, hashLengths[k], filepath.Base(s.LocalPath))
+		if len(name) > maxFilenameLength {
+			return &FileError{Path: s.LocalPath, Err: fmt.Errorf("%w: name is %d bytes, %d allowed", ErrPathTooLong, len(name), maxFilenameLength)}
+		}
+
+		if !limitPathLength {
+			continue
+		}
+
+		p := filepath.Join(filepath.Dir(s.LocalPath), name)
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		if !strings.HasPrefix(p, `\\?\`) && len(p) > maxWindowsPathLength {
+			return &FileError{Path: s.LocalPath, Err: fmt.Errorf("%w: path is %d characters, %d allowed", ErrPathTooLong, len(p), maxWindowsPathLength)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cachebusting
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckPathLengths(t *testing.T) {
+	dir := t.TempDir()
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A copy whose name would be too long is reported before any copy is saved.
+	long := filepath.Join(dir, strings.Repeat("a", 250)+".js")
+	err := os.WriteFile(long, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(NewStaticFile(long, "/static/js/"+filepath.Base(long)))
+	err = c.Create()
+	if !errors.Is(err, ErrPathTooLong) {
+		t.Fatal("ErrPathTooLong should have been returned", err)
+		return
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatal("No copy should have been saved", len(entries))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Storing copies in memory avoids the limit.
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A full path longer than Windows allows is reported when limited.
+	defer func(l bool) { limitPathLength = l }(limitPathLength)
+	limitPathLength = true
+
+	short := filepath.Join(dir, strings.Repeat("b", 240)+".js")
+	err = os.WriteFile(short, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c = NewOnDiskConfig(NewStaticFile(short, "/static/js/"+filepath.Base(short)))
+	err = c.Create()
+	if !errors.Is(err, ErrPathTooLong) || !strings.Contains(err.Error(), "path is") {
+		t.Fatal("ErrPathTooLong should have been returned for the full path", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,165 @@
+package cachebusting
+
+import (
+	"path/filepath"
+)
+
+//lookup is a snapshot of the static files, and the lookups of them by URL path and name,
+//used when serving requests and rendering templates. A lookup is built at the end of
+//Create() and is never modified, a new lookup replaces it, so that requests can be served
+//without locking while Create() is modifying StaticFiles. Changes made to StaticFiles
+//after Create() is called are not used until Create() is called again.
+type lookup struct {
+	//files is a copy of StaticFiles.
+	files []StaticFile
+
+	//urlIndex maps each cache busting URL path to the index of the static file in files.
+	//This is used so that looking up a file when serving a request doesn't require
+	//checking every static file.
+	urlIndex map[string]int
+
+	//previousURLIndex maps each previous cache busting URL path to the index of the static
+	//file in files. See HistoryLength.
+	previousURLIndex map[string]int
+
+	//nameIndex maps each original file's name to the index of the static file in files.
+	//This is used by the template funcs.
+	nameIndex map[string]int
+
+	//memoryCache is the cache of the data of copies stored in memory when the data is
+	//not kept in files, see MemoryCacheBytes.
+	memoryCache *lruCache
+
+	//copyMatchers is the func, for each static file in files, that reports if a file's
+	//name is the name of a cache busting copy of the static file, see copyMatcher. These
+	//are built once, rather than on every request for an unknown copy, since building a
+	//matcher compiles a regular expression. A matcher is nil if the static file cannot
+	//have copies.
+	copyMatchers []func(string) bool
+}
+
+//newLookup builds a lookup of a copy of the static files.
+func newLookup(files []StaticFile) *lookup {
+	l := &lookup{
+		files:     append([]StaticFile(nil), files...),
+		urlIndex:  make(map[string]int, len(files)),
+		nameIndex: make(map[string]int, len(files)),
+	}
+
+	for k, s := range l.files {
+		l.urlIndex[s.cacheBustURLPath] = k
+
+		//the first file with a name is used, matching findByOriginalName.
+		name := filepath.Base(s.LocalPath)
+		if _, ok := l.nameIndex[name]; !ok {
+			l.nameIndex[name] = k
+		}
+
+		//aliases are served the same as the file's cache busting URL path.
+		for _, p := range aliasCacheBustURLPaths(s) {
+			if _, ok := l.urlIndex[p]; !ok {
+				l.urlIndex[p] = k
+			}
+		}
+
+		for _, p := range s.previousURLPaths {
+			if l.previousURLIndex == nil {
+				l.previousURLIndex = make(map[string]int)
+			}
+			l.previousURLIndex[p] = k
+		}
+	}
+
+	return l
+}
+
+//buildIndex replaces the lookup of static files used when serving requests with one built
+//from the current StaticFiles. The memory cache of the current lookup is kept.
+func (c *Config) buildIndex() {
+	var cache *lruCache
+	if l := c.currentLookup(); l != nil {
+		cache = l.memoryCache
+	}
+
+	c.storeIndex(cache)
+}
+
+//storeIndex replaces the lookup of static files used when serving requests with one built
+//from the current StaticFiles and the memory cache, see MemoryCacheBytes.
+func (c *Config) storeIndex(cache *lruCache) {
+	l := newLookup(c.StaticFiles)
+	l.memoryCache = cache
+	l.copyMatchers = c.copyMatchers(l.files)
+	c.current.Store(l)
+}
+
+//copyMatchers returns the copy matcher of each static file, see lookup.copyMatchers.
+func (c *Config) copyMatchers(files []StaticFile) (m []func(string) bool) {
+	m = make([]func(string) bool, len(files))
+	for k, s := range files {
+		if s.Vendor || s.cacheBustURLPath == "" {
+			continue
+		}
+
+		isCopy, err := c.copyMatcher(filepath.Base(s.LocalPath))
+		if err == nil {
+			m[k] = isCopy
+		}
+	}
+
+	return
+}
+
+//currentLookup returns the lookup built by Create(), or nil if Create() hasn't been
+//called.
+func (c *Config) currentLookup() *lookup {
+	l, _ := c.current.Load().(*lookup)
+	return l
+}
+
+//servedFiles returns the static files used when serving requests. This is the snapshot
+//of StaticFiles taken by Create(), or StaticFiles itself if Create() hasn't been called.
+func (c *Config) servedFiles() []StaticFile {
+	if l := c.currentLookup(); l != nil {
+		return l.files
+	}
+
+	return c.StaticFiles
+}
+
+//findByCacheBustURLPath looks up a static file by the URL path of its cache busting copy.
+//If the URL path matches a previous cache busting URL path for a file (see HistoryLength),
+//the file is returned and outdated is true.
+func (c *Config) findByCacheBustURLPath(urlPath string) (s StaticFile, outdated, found bool) {
+	l := c.currentLookup()
+	if l == nil {
+		return findByCacheBustURLPath(c.StaticFiles, urlPath)
+	}
+
+	if k, ok := l.urlIndex[urlPath]; ok {
+		return l.files[k], false, true
+	}
+	if k, ok := l.previousURLIndex[urlPath]; ok {
+		return l.files[k], true, true
+	}
+
+	return
+}
+
+//findByCacheBustURLPath looks up a static file by the URL path of its cache busting copy
+//by checking each static file. This is used when Create() hasn't been called.
+func findByCacheBustURLPath(files []StaticFile, urlPath string) (s StaticFile, outdated, found bool) {
+	for _, v := range files {
+		if v.cacheBustURLPath == urlPath || containsString(aliasCacheBustURLPaths(v), urlPath) {
+			return v, false, true
+		}
+	}
+
+	for _, v := range files {
+		if containsString(v.previousURLPaths, urlPath) {
+			return v, true, true
+		}
+	}
+
+	return
+}
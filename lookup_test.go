@@ -0,0 +1,242 @@
+package cachebusting
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestServeWhileCreating(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	h := c.Handler(HandlerOptions{CacheDays: 1})
+
+	var urls []string
+	for _, s := range c.StaticFiles {
+		urls = append(urls, s.cacheBustURLPath)
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Requests are served from the prior lookup while Create() is running.
+	done := make(chan struct{})
+	failed := make(chan string, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				for _, u := range urls {
+					rec := httptest.NewRecorder()
+					h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+					if rec.Code != http.StatusOK || rec.Header().Get("X-Static-Served-From") != "memory" {
+						select {
+						case failed <- u:
+						default:
+						}
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		err = c.Create()
+		if err != nil {
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	select {
+	case u := <-failed:
+		t.Fatal("File not served while creating", u)
+		return
+	default:
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestLookupSnapshot(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Before Create() is called, the static files are checked directly.
+	if c.currentLookup() != nil {
+		t.Fatal("Lookup should not exist before Create()")
+		return
+	}
+	c.StaticFiles[0].cacheBustURLPath = "/static/js/manual.script.min.js"
+	if _, _, found := c.findByCacheBustURLPath("/static/js/manual.script.min.js"); !found {
+		t.Fatal("File should have been found without a lookup")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//After Create(), changes to the static files aren't seen until Create() is called
+	//again.
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	u := c.StaticFiles[0].cacheBustURLPath
+	c.StaticFiles[0].cacheBustURLPath = "/static/js/changed.script.min.js"
+
+	if _, _, found := c.findByCacheBustURLPath(u); !found {
+		t.Fatal("File should have been found in lookup")
+		return
+	}
+	if _, _, found := c.findByCacheBustURLPath("/static/js/changed.script.min.js"); found {
+		t.Fatal("Change should not be seen until Create() is called")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestTemplatesWhileCreating(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[0].Critical = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	manifest := c.ManifestHandler("")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates, preloads, and the manifest are built from the prior lookup while
+	//Create() is running. Run with -race to check.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				c.AssetURL("script.min.js")
+				c.TemplateData()
+				c.PreloadLinkHeader()
+				manifest.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/manifest.json", nil))
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		err = c.Create()
+		if err != nil {
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestReadersWhileCreating(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[0].Inline = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	store := funcStore(func(a Asset) error { return nil })
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//CSP hashes, manifests, published files, and reports are from the prior Create() while
+	//Create() is running. Run with -race to check.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if len(c.CSPHashes()) != 1 {
+					t.Error("CSP hash missing")
+					return
+				}
+				if len(c.Manifest(true).Files) != 2 {
+					t.Error("Manifest files missing")
+					return
+				}
+				if err := c.Publish(context.Background(), store); err != nil {
+					t.Error("Error occured but should not have", err)
+					return
+				}
+				if len(c.LastReport().Files) != 2 {
+					t.Error("Report files missing")
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		err = c.Create()
+		if err != nil {
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
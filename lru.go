@@ -0,0 +1,85 @@
+package cachebusting
+
+import (
+	"container/list"
+	"sync"
+)
+
+//lruCache stores file data in memory up to a total size in bytes. When adding data would
+//exceed the size, the least recently used data is removed. This is safe for concurrent
+//use.
+type lruCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+//lruEntry is the data stored in an lruCache for a key.
+type lruEntry struct {
+	key  string
+	data []byte
+}
+
+//newLRUCache returns an lruCache that stores up to maxBytes of data.
+func newLRUCache(maxBytes int64) *lruCache {
+	return &lruCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+//get returns the data stored for a key and marks the data as recently used.
+func (l *lruCache) get(key string) (data []byte, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(e)
+
+	return e.Value.(*lruEntry).data, true
+}
+
+//add stores the data for a key, removing the least recently used data as needed to stay
+//within maxBytes. Data larger than maxBytes is not stored.
+func (l *lruCache) add(key string, data []byte) {
+	size := int64(len(data))
+	if size > l.maxBytes {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.items[key]; ok {
+		l.bytes -= int64(len(e.Value.(*lruEntry).data))
+		e.Value.(*lruEntry).data = data
+		l.bytes += size
+		l.order.MoveToFront(e)
+	} else {
+		l.items[key] = l.order.PushFront(&lruEntry{key: key, data: data})
+		l.bytes += size
+	}
+
+	for l.bytes > l.maxBytes {
+		e := l.order.Back()
+		l.order.Remove(e)
+
+		entry := e.Value.(*lruEntry)
+		delete(l.items, entry.key)
+		l.bytes -= int64(len(entry.data))
+	}
+}
+
+//size returns the total size, in bytes, of the data stored.
+func (l *lruCache) size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.bytes
+}
@@ -0,0 +1,46 @@
+package cachebusting
+
+import (
+	"testing"
+)
+
+func TestLRUCache(t *testing.T) {
+	l := newLRUCache(10)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Least recently used data is removed when the size is exceeded.
+	l.add("a", []byte("aaaa"))
+	l.add("b", []byte("bbbb"))
+	if _, ok := l.get("a"); !ok {
+		t.Fatal("Data should be stored")
+		return
+	}
+	l.add("c", []byte("cccc"))
+	if _, ok := l.get("b"); ok {
+		t.Fatal("Least recently used data should have been removed")
+		return
+	}
+	if _, ok := l.get("a"); !ok {
+		t.Fatal("Recently used data should not have been removed")
+		return
+	}
+	if l.size() != 8 {
+		t.Fatal("Size not as expected", l.size())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data larger than the size is not stored, replaced data is counted once.
+	l.add("d", []byte("ddddddddddd"))
+	if _, ok := l.get("d"); ok {
+		t.Fatal("Data larger than the size should not be stored")
+		return
+	}
+	l.add("a", []byte("aa"))
+	if data, _ := l.get("a"); string(data) != "aa" || l.size() != 6 {
+		t.Fatal("Data not replaced as expected", string(data), l.size())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,415 @@
+package cachebusting
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+//Manifest is the result of creating cache busting files: the cache busting URL path, hash,
+//and optionally the data, of each static file. A manifest can be exported from one
+//instance of your app, after calling Create(), and loaded by other instances of your app so
+//that all instances use the same cache busting URL paths without each instance hashing the
+//files itself.
+type Manifest struct {
+	Files []ManifestFile
+}
+
+//ManifestFile is the cache busting information for a single static file.
+type ManifestFile struct {
+	//URLPath is the URL path of the original static file. This is used to match up the
+	//manifest file with a static file when a manifest is loaded.
+	URLPath string
+
+	//CacheBustURLPath is the URL path the cache busting copy of the file is served on.
+	CacheBustURLPath string
+
+	//Hash is the full hash of the file's contents.
+	Hash string
+
+	//Integrity is the subresource integrity value of the file's contents.
+	Integrity string
+
+	//Data is the contents of the file. This is only included if requested when building
+	//the manifest. If not included, the original file is read when the manifest is
+	//loaded.
+	Data []byte `json:",omitempty"`
+}
+
+//errors
+var (
+	//ErrNotInManifest is returned when a manifest is loaded but a static file is not
+	//listed in the manifest.
+	ErrNotInManifest = errors.New("cachebusting: static file not in manifest")
+
+	//ErrManifestNotPublished is returned when a manifest is loaded from a store but a
+	//manifest has not been published to the store.
+	ErrManifestNotPublished = errors.New("cachebusting: manifest not published")
+
+	//ErrInvalidManifest is returned when a manifest is loaded but the cache busting URL
+	//path of a file in the manifest isn't named for the file and its hash.
+	ErrInvalidManifest = errors.New("cachebusting: cache busting url path in manifest does not match file")
+)
+
+//Manifest returns the manifest of cache busting files. Set includeData to true to include
+//the contents of each file, this is useful when other instances of your app cannot read
+//the original files or may have a different version of the original files during a
+//rollout. Files are sorted by URL path so the same files always result in the same
+//manifest.
+func (c *Config) Manifest(includeData bool) (m Manifest) {
+	files := c.sortedStaticFiles()
+	m.Files = make([]ManifestFile, 0, len(files))
+
+	for _, s := range files {
+		f := ManifestFile{
+			URLPath:          s.URLPath,
+			CacheBustURLPath: s.cacheBustURLPath,
+			Hash:             s.hash,
+			Integrity:        s.integrity,
+		}
+
+		if includeData {
+			f.Data = s.fileData
+			if s.streamed || s.evicted {
+				b, err := c.copyData(s)
+				if err == nil {
+					f.Data = b
+				}
+			} else if f.Data == nil && s.cacheBustLocalPath != "" && !c.inMemory(s) {
+				b, err := c.readFunc()(s.cacheBustLocalPath)
+				if err == nil {
+					f.Data = b
+				}
+			}
+		}
+
+		m.Files = append(m.Files, f)
+	}
+
+	return
+}
+
+//LoadManifest uses a manifest, rather than hashing each static file, to create the cache
+//busting files. The cache busting copies are saved to disk or memory just as Create()
+//would. Each static file in the config must be listed in the manifest.
+//
+//The data of each file, from the manifest or the original file if the manifest doesn't
+//include data, must match the hash in the manifest. ErrOriginalChanged is returned if an
+//original file differs from the one the manifest was built from, i.e. during a rollout,
+//and ErrCopyCorrupted is returned if the data in the manifest doesn't match. Like
+//Create(), the config is left as it was if an error occurs and ErrCreateInProgress is
+//returned if Create() or LoadManifest() is already running for this config.
+func (c *Config) LoadManifest(m Manifest) (err error) {
+	//make sure Create() isn't already running, see creating.
+	if !atomic.CompareAndSwapInt32(&c.creating, 0, 1) {
+		return ErrCreateInProgress
+	}
+	defer atomic.StoreInt32(&c.creating, 0)
+
+	//undo any changes if an error occurs, see Create().
+	original := append([]StaticFile(nil), c.StaticFiles...)
+	previous := c.currentLookup()
+	var written []string
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		for _, p := range written {
+			os.Remove(p)
+		}
+		c.StaticFiles = original
+		c.current.Store(previous)
+	}()
+
+	err = c.validate()
+	if err != nil {
+		return
+	}
+
+	byURLPath := make(map[string]ManifestFile, len(m.Files))
+	for _, f := range m.Files {
+		byURLPath[f.URLPath] = f
+	}
+
+	type oldFiles struct {
+		directory        string
+		originalFilename string
+		keep             string
+	}
+	var removals []oldFiles
+
+	for k, s := range c.StaticFiles {
+		f, ok := byURLPath[s.URLPath]
+		if !ok {
+			return &FileError{Path: s.URLPath, Err: ErrNotInManifest}
+		}
+
+		originalFilename := filepath.Base(s.LocalPath)
+		if c.usesFS() {
+			originalFilename = path.Base(filepath.ToSlash(s.LocalPath))
+		}
+
+		//make sure the manifest's copy is named for the file and its hash so that a
+		//manifest cannot save a copy over another file or serve a file on another URL.
+		if !c.manifestCopyName(s, f, originalFilename) {
+			return &FileError{Path: s.URLPath, Err: ErrInvalidManifest}
+		}
+
+		data := f.Data
+		mismatch := ErrCopyCorrupted
+		if data == nil {
+			data, err = c.readOriginal(s)
+			if err != nil {
+				return
+			}
+			mismatch = ErrOriginalChanged
+		}
+
+		//make sure the data is what the manifest was built from, otherwise different data
+		//would be served on the same cache busting URL.
+		h := sha256.Sum256(data)
+		if f.Hash != "" && c.nameHash(s, h) != f.Hash {
+			return &FileError{Path: s.LocalPath, Err: mismatch}
+		}
+		if f.Integrity != "" && "sha256-"+base64.StdEncoding.EncodeToString(h[:]) != f.Integrity {
+			return &FileError{Path: s.LocalPath, Err: mismatch}
+		}
+
+		c.StaticFiles[k].hash = f.Hash
+		c.StaticFiles[k].integrity = f.Integrity
+
+		//the copy is saved from the manifest's, or original file's, data so it is neither
+		//streamed nor kept in the memory cache as it may have been by a prior Create().
+		c.StaticFiles[k].streamed = false
+		c.StaticFiles[k].evicted = false
+
+		//vendor files are served as-is, no cache busting copy is made.
+		if s.Vendor {
+			c.vendor(k, originalFilename, data)
+			continue
+		}
+
+		//a copy with the same name already existing must not be removed if an error
+		//occurs.
+		originalDirectory := filepath.Dir(s.LocalPath)
+		cachebustFilename := path.Base(f.CacheBustURLPath)
+		_, statErr := os.Lstat(filepath.Join(originalDirectory, cachebustFilename))
+		existed := statErr == nil
+
+		err = c.saveCopy(k, cachebustFilename, data)
+		if err != nil {
+			return
+		}
+
+		//remove old copies only once every copy is saved, the copy may have the same name
+		//as a copy already on disk.
+		if !c.inMemory(c.StaticFiles[k]) {
+			removals = append(removals, oldFiles{originalDirectory, originalFilename, cachebustFilename})
+			if !existed {
+				written = append(written, c.StaticFiles[k].cacheBustLocalPath)
+			}
+		}
+
+		c.StaticFiles[k].cacheBustURLPath = f.CacheBustURLPath
+	}
+
+	//the memory cache of a prior Create() isn't used since no copies are evicted.
+	c.storeIndex(nil)
+
+	//remove the old cache busting files now that every copy has been saved. A failure to
+	//remove an old file is only logged since the new copies are complete and in use.
+	for _, r := range removals {
+		removeErr := c.removeOldCopies(r.directory, r.originalFilename, r.keep)
+		if removeErr != nil {
+			log.Println("cachebusting.LoadManifest", "could not remove old cache busting files", r.directory, removeErr)
+		}
+	}
+
+	return
+}
+
+//manifestCopyName returns true if the cache busting URL path of a file in a manifest is
+//the one Create() would use for the static file given the hash in the manifest. The
+//hash may have been lengthened to resolve a collision, see resolveCollisions().
+func (c *Config) manifestCopyName(s StaticFile, f ManifestFile, originalFilename string) bool {
+	if s.Vendor {
+		return f.CacheBustURLPath == s.URLPath
+	}
+
+	if f.Hash == "" || path.Dir(f.CacheBustURLPath) != path.Dir(s.URLPath) {
+		return false
+	}
+
+	name := path.Base(f.CacheBustURLPath)
+	for l := c.HashLength; l <= maxHashLength; l++ {
+		if name == c.cacheBustFilename(f.Hash, l, originalFilename) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//ManifestStore is a place a manifest can be published to and loaded from so that multiple
+//instances of your app can share a manifest. Typically one instance calls Create() and
+//then PublishManifest() and the other instances call LoadManifestFrom().
+type ManifestStore interface {
+	//Publish saves the manifest.
+	Publish(ctx context.Context, m Manifest) error
+
+	//Load retrieves the manifest. ErrManifestNotPublished should be returned if no
+	//manifest has been published.
+	Load(ctx context.Context) (Manifest, error)
+}
+
+//PublishManifest builds the manifest, see Manifest(), and publishes it to the store.
+func (c *Config) PublishManifest(ctx context.Context, store ManifestStore, includeData bool) error {
+	return store.Publish(ctx, c.Manifest(includeData))
+}
+
+//LoadManifestFrom loads the manifest from the store and uses it to create the cache
+//busting files, see LoadManifest().
+func (c *Config) LoadManifestFrom(ctx context.Context, store ManifestStore) error {
+	m, err := store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.LoadManifest(m)
+}
+
+//RedisClient is the subset of a Redis client used by RedisStore. This allows you to use
+//whichever Redis client library you already use without this package depending on it. For
+//example, with github.com/redis/go-redis:
+//
+//	type redisClient struct{ rdb *redis.Client }
+//
+//	func (r redisClient) Get(ctx context.Context, key string) ([]byte, error) {
+//		b, err := r.rdb.Get(ctx, key).Bytes()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return b, err
+//	}
+//
+//	func (r redisClient) Set(ctx context.Context, key string, value []byte) error {
+//		return r.rdb.Set(ctx, key, value, 0).Err()
+//	}
+type RedisClient interface {
+	//Get returns the value stored at key. A nil value and nil error should be returned
+	//if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	//Set stores the value at key.
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+//defaultRedisKey is the key a manifest is stored at if a key isn't provided.
+const defaultRedisKey = "cachebusting:manifest"
+
+//RedisStore is a ManifestStore that saves the manifest, as JSON, in Redis.
+type RedisStore struct {
+	//Client is the Redis client used to save and retrieve the manifest.
+	Client RedisClient
+
+	//Key is the key the manifest is saved at. If not provided, "cachebusting:manifest"
+	//is used. Use a key that includes your app's version if multiple versions of your app
+	//share the same Redis server.
+	Key string
+}
+
+//key returns the key the manifest is saved at.
+func (r RedisStore) key() string {
+	if r.Key == "" {
+		return defaultRedisKey
+	}
+
+	return r.Key
+}
+
+//Publish saves the manifest to Redis.
+func (r RedisStore) Publish(ctx context.Context, m Manifest) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return r.Client.Set(ctx, r.key(), b)
+}
+
+//Load retrieves the manifest from Redis.
+func (r RedisStore) Load(ctx context.Context) (m Manifest, err error) {
+	b, err := r.Client.Get(ctx, r.key())
+	if err != nil {
+		return
+	}
+	if b == nil {
+		err = ErrManifestNotPublished
+		return
+	}
+
+	err = json.Unmarshal(b, &m)
+	return
+}
+
+//checkBearerToken checks that a request includes the token in the Authorization header,
+//i.e.: Authorization: Bearer {token}, responding with a 401 Unauthorized if it does not.
+//The token is compared in constant time. Any request is allowed if token is blank. True
+//is returned if the request is allowed.
+func checkBearerToken(w http.ResponseWriter, r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+//ManifestHandler returns an http.Handler that responds with the mapping of each static
+//file's original URL path to its cache busting URL as JSON. This is useful for single page
+//apps that need to look up cache busting URLs at runtime, for example for dynamically
+//imported modules. Serve this on a route such as /cachebust/manifest.json.
+//
+//If token is provided, requests must include the token in the Authorization header,
+//i.e.: Authorization: Bearer {token}. Leave token blank to not require authorization.
+func (c *Config) ManifestHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkBearerToken(w, r, token) {
+			return
+		}
+
+		files := c.servedFiles()
+		pairs := make(map[string]string, len(files))
+		for _, s := range files {
+			pairs[s.URLPath] = c.urlFor(s)
+			for a, u := range c.aliasURLs(s) {
+				pairs[a] = u
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		json.NewEncoder(w).Encode(pairs)
+	})
+}
+
+//DefaultManifestHandler returns the manifest handler for the package level config.
+func DefaultManifestHandler(token string) http.Handler {
+	return config.ManifestHandler(token)
+}
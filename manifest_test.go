@@ -0,0 +1,309 @@
+package cachebusting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+//memoryRedis is a fake Redis client for testing.
+type memoryRedis struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (m *memoryRedis) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], nil
+}
+
+func (m *memoryRedis) Set(ctx context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[string][]byte)
+	}
+	m.data[key] = value
+	return nil
+}
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	js := NewStaticFile(p, "/static/js/script.min.js")
+	publisher := NewOnDiskConfig(js)
+	publisher.UseMemory = true
+	err = publisher.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	store := RedisStore{Client: &memoryRedis{}}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Loading before publishing returns an error.
+	loader := NewOnDiskConfig(js)
+	loader.UseMemory = true
+	err = loader.LoadManifestFrom(context.Background(), store)
+	if !errors.Is(err, ErrManifestNotPublished) {
+		t.Fatal("ErrManifestNotPublished should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Published manifest with data is used even if the local file differs.
+	err = publisher.PublishManifest(context.Background(), store, true)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	err = loader.LoadManifestFrom(context.Background(), store)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if loader.StaticFiles[0].cacheBustURLPath != publisher.StaticFiles[0].cacheBustURLPath {
+		t.Fatal("Cache busting URL path not loaded from manifest")
+		return
+	}
+
+	b, err := loader.FindFileDataByCacheBustURLPath(publisher.StaticFiles[0].cacheBustURLPath)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if string(b) != "console.log(1);" {
+		t.Fatal("File data not loaded from manifest", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Static files missing from the manifest return an error.
+	other := NewStaticFile(p, "/static/js/other.min.js")
+	loader = NewOnDiskConfig(js, other)
+	loader.UseMemory = true
+	err = loader.LoadManifestFrom(context.Background(), store)
+	if !errors.Is(err, ErrNotInManifest) {
+		t.Fatal("ErrNotInManifest should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestLoadManifestOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	m := c.Manifest(false)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Loading a manifest keeps the current copy and removes old copies.
+	old := filepath.Join(dir, "ABCDEF01.script.min.js")
+	err = os.WriteFile(old, []byte("old"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.LoadManifest(m)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	b, err := os.ReadFile(c.StaticFiles[0].cacheBustLocalPath)
+	if err != nil || string(b) != "console.log(1);" {
+		t.Fatal("Current copy not kept", err, string(b))
+		return
+	}
+	if _, err := os.Stat(old); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("Old copy should have been removed", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies kept in the memory cache by a prior Create() are stored in memory once a
+	//manifest is loaded.
+	c.UseMemory = true
+	c.MemoryCacheBytes = 1024
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if !c.StaticFiles[0].evicted {
+		t.Fatal("Copy should have been kept in the memory cache")
+		return
+	}
+
+	err = c.LoadManifest(c.Manifest(false))
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if s := c.StaticFiles[0]; s.evicted || s.streamed || string(s.fileData) != "console.log(1);" {
+		t.Fatal("Copy not stored in memory", s.evicted, s.streamed, string(s.fileData))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestLoadManifestChecks(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	m := c.Manifest(false)
+	before := c.StaticFiles[0]
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An original file that differs from the one the manifest was built from is not
+	//served under the manifest's hash and the config is left as it was.
+	err = os.WriteFile(p, []byte("console.log(2);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = c.LoadManifest(m)
+	if !errors.Is(err, ErrOriginalChanged) {
+		t.Fatal("ErrOriginalChanged should have occured but didn't", err)
+		return
+	}
+	if !c.StaticFiles[0].equal(before) {
+		t.Fatal("Static file modified by failed LoadManifest", c.StaticFiles[0], before)
+		return
+	}
+	b, err := os.ReadFile(before.cacheBustLocalPath)
+	if err != nil || string(b) != "console.log(1);" {
+		t.Fatal("Copy modified by failed LoadManifest", err, string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Data in the manifest must match the manifest's hash.
+	tampered := Manifest{Files: append([]ManifestFile(nil), m.Files...)}
+	tampered.Files[0].Data = []byte("console.log(3);")
+	err = c.LoadManifest(tampered)
+	if !errors.Is(err, ErrCopyCorrupted) {
+		t.Fatal("ErrCopyCorrupted should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The cache busting URL path must be named for the file and its hash.
+	for _, u := range []string{"/static/js/ABCDEF01.script.min.js", "/static/css/" + filepath.Base(before.cacheBustURLPath), "/static/js/script.min.js"} {
+		tampered = Manifest{Files: append([]ManifestFile(nil), m.Files...)}
+		tampered.Files[0].Data = []byte("console.log(1);")
+		tampered.Files[0].CacheBustURLPath = u
+		err = c.LoadManifest(tampered)
+		if !errors.Is(err, ErrInvalidManifest) {
+			t.Fatal("ErrInvalidManifest should have occured but didn't", u, err)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A manifest cannot be loaded while Create() is running.
+	c.creating = 1
+	err = c.LoadManifest(m)
+	c.creating = 0
+	if !errors.Is(err, ErrCreateInProgress) {
+		t.Fatal("ErrCreateInProgress should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestManifestHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/app.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Mapping is returned as JSON when no token is required.
+	rec := httptest.NewRecorder()
+	c.ManifestHandler("").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cachebust/manifest.json", nil))
+
+	var pairs map[string]string
+	err = json.Unmarshal(rec.Body.Bytes(), &pairs)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if pairs["/static/js/app.js"] != c.StaticFiles[0].cacheBustURLPath {
+		t.Fatal("Mapping not returned as expected", pairs)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Token is required when provided.
+	h := c.ManifestHandler("secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cachebust/manifest.json", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatal("Request without token should be unauthorized", rec.Code)
+		return
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/cachebust/manifest.json", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatal("Request with token should be authorized", rec.Code)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,132 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+//mappingFilename is the name of the mapping file saved in each directory cache busting
+//copies are saved to. See MappingFile.
+const mappingFilename = ".cachebust.json"
+
+//mappingFile is the format of the mapping file. Files are keyed by the original file's
+//name.
+type mappingFile struct {
+	Files map[string]mappingEntry `json:"files"`
+}
+
+//mappingEntry is the cache busting copy of an original file recorded in the mapping file.
+//The size and modification time of the original file are used to tell if the original
+//file has changed since the mapping file was saved.
+type mappingEntry struct {
+	CacheBustFilename string    `json:"cacheBustFilename"`
+	Hash              string    `json:"hash"`
+	Integrity         string    `json:"integrity"`
+	HashMix           string    `json:"hashMix,omitempty"`
+	Size              int64     `json:"size"`
+	ModTime           time.Time `json:"modTime"`
+}
+
+//readMappingFile reads the mapping file in a directory. An empty mapping is returned if
+//the file doesn't exist or cannot be parsed, since the files are then just hashed again.
+func readMappingFile(dir string) (m mappingFile) {
+	b, err := os.ReadFile(filepath.Join(dir, mappingFilename))
+	if err != nil {
+		return
+	}
+
+	json.Unmarshal(b, &m)
+	return
+}
+
+//trustedHash returns the hash and integrity of a static file recorded in the mapping file
+//in the file's directory, if the original file hasn't changed since the mapping file was
+//saved. This allows Create() to skip reading and hashing the file. Files whose data is
+//modified when read are never trusted since the hash depends on more than the original
+//file. The data mixed into the hash, see hashMix, must match too.
+//
+//mappings caches the mapping file of each directory.
+func (c *Config) trustedHash(s StaticFile, mappings map[string]mappingFile) (hash, integrity string, ok bool) {
+	if !c.MappingFile || s.Vendor || c.inMemory(s) || c.modifies(s) {
+		return
+	}
+
+	dir := filepath.Dir(s.LocalPath)
+	m, read := mappings[dir]
+	if !read {
+		m = readMappingFile(dir)
+		mappings[dir] = m
+	}
+
+	e, found := m.Files[filepath.Base(s.LocalPath)]
+	if !found || e.Hash == "" || e.HashMix != c.hashMix(s) {
+		return
+	}
+
+	info, err := os.Stat(s.LocalPath)
+	if err != nil || info.Size() != e.Size || !info.ModTime().Equal(e.ModTime) {
+		return
+	}
+
+	return e.Hash, e.Integrity, true
+}
+
+//writeMappingFiles saves the mapping file in each directory cache busting copies were
+//saved to, recording the cache busting copy of each original file in the directory.
+func (c *Config) writeMappingFiles() error {
+	byDir := make(map[string]mappingFile)
+	for _, s := range c.StaticFiles {
+		if s.Vendor || c.inMemory(s) || s.cacheBustLocalPath == "" {
+			continue
+		}
+
+		info, err := os.Stat(s.LocalPath)
+		if err != nil {
+			return err
+		}
+
+		dir := filepath.Dir(s.LocalPath)
+		m, ok := byDir[dir]
+		if !ok {
+			m = mappingFile{Files: make(map[string]mappingEntry)}
+			byDir[dir] = m
+		}
+		m.Files[filepath.Base(s.LocalPath)] = mappingEntry{
+			CacheBustFilename: filepath.Base(s.cacheBustLocalPath),
+			Hash:              s.hash,
+			Integrity:         s.integrity,
+			HashMix:           c.hashMix(s),
+			Size:              info.Size(),
+			ModTime:           info.ModTime(),
+		}
+	}
+
+	//write in a consistent order so the same error is returned each time.
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		b, err := json.MarshalIndent(byDir[dir], "", "  ")
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(filepath.Join(dir, mappingFilename), b, c.TempDir)
+		if err != nil {
+			return err
+		}
+
+		if c.Debug {
+			log.Println("cachebusting.Create (debug)", "saved mapping file", filepath.Join(dir, mappingFilename))
+		}
+	}
+
+	return nil
+}
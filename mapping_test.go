@@ -0,0 +1,151 @@
+package cachebusting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMappingFile(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The mapping file records each copy saved to disk.
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.MappingFile = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, mappingFilename))
+	if err != nil {
+		t.Fatal("Mapping file not saved", err)
+		return
+	}
+	var m mappingFile
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		t.Fatal("Mapping file not valid", err)
+		return
+	}
+	s := c.StaticFiles[0]
+	e := m.Files["script.min.js"]
+	if e.Hash != s.hash || e.Integrity != s.integrity || e.CacheBustFilename != filepath.Base(s.cacheBustLocalPath) || e.Size != 15 {
+		t.Fatal("Mapping not as expected", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An unchanged file uses the recorded hash rather than being hashed again.
+	e.Hash = "0123456789ABCDEF" + s.hash[16:]
+	e.CacheBustFilename = "01234567.script.min.js"
+	m.Files["script.min.js"] = e
+	b, _ = json.Marshal(m)
+	err = os.WriteFile(filepath.Join(dir, mappingFilename), b, 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c = NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.MappingFile = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if c.StaticFiles[0].hash != e.Hash {
+		t.Fatal("Recorded hash should have been used", c.StaticFiles[0].hash)
+		return
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "01234567.script.min.js")); err != nil || string(b) != "console.log(1);" {
+		t.Fatal("Copy not saved for recorded hash", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A changed file is hashed again.
+	later := time.Now().Add(time.Hour)
+	err = os.Chtimes(p, later, later)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c = NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.MappingFile = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if c.StaticFiles[0].hash != s.hash {
+		t.Fatal("File should have been hashed again", c.StaticFiles[0].hash)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The mapping file is not found as a static file.
+	c = NewFSConfig(os.DirFS(dir), ".", "/static/js")
+	for _, s := range c.StaticFiles {
+		if filepath.Base(s.LocalPath) == mappingFilename {
+			t.Fatal("Mapping file should not be a static file")
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMappingFileNotServed(t *testing.T) {
+	dir := t.TempDir()
+	jsDir := filepath.Join(dir, "static", "js")
+	err := os.MkdirAll(jsDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	p := filepath.Join(jsDir, "script.min.js")
+	err = os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.MappingFile = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The mapping file is saved alongside the copies but is not served.
+	if _, err := os.Stat(filepath.Join(jsDir, mappingFilename)); err != nil {
+		t.Fatal("Mapping file not saved", err)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	c.StaticFileHandler(1, dir).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/js/"+mappingFilename, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatal("Mapping file should not have been served", rec.Code, rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
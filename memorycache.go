@@ -0,0 +1,72 @@
+package cachebusting
+
+import (
+	"crypto/sha256"
+)
+
+//evictable returns true if the data of a static file's copy stored in memory can be
+//removed from memory and read again from the original file when needed, see
+//MemoryCacheBytes. Only copies whose data matches the original file's data can be read
+//again.
+func (c *Config) evictable(s StaticFile) bool {
+	return c.MemoryCacheBytes > 0 && c.inMemory(s) && !s.streamed && !c.modifies(s)
+}
+
+//useMemoryCache moves the data of each copy stored in memory that can be evicted, see
+//evictable, into a new cache limited to MemoryCacheBytes. The least recently used data is
+//removed from memory once the limit is reached. Copies larger than MemoryCacheBytes are
+//kept in memory since they could never be cached and would be read again, and hashed,
+//on every request. The cache is returned for use in the lookup built by Create(), or
+//nil if MemoryCacheBytes is not set.
+func (c *Config) useMemoryCache() (cache *lruCache) {
+	if c.MemoryCacheBytes > 0 {
+		cache = newLRUCache(c.MemoryCacheBytes)
+	}
+
+	for k, s := range c.StaticFiles {
+		c.StaticFiles[k].evicted = false
+		if cache == nil || s.cacheBustURLPath == "" || s.fileData == nil || !c.evictable(s) {
+			continue
+		}
+		if int64(len(s.fileData)) > c.MemoryCacheBytes {
+			continue
+		}
+
+		cache.add(s.cacheBustURLPath, s.fileData)
+		c.StaticFiles[k].fileData = nil
+		c.StaticFiles[k].evicted = true
+	}
+
+	return
+}
+
+//cachedData returns the data of a static file's copy stored in the memory cache, see
+//MemoryCacheBytes. If the data was removed from memory, the original file is read again
+//and added back to the cache. ErrOriginalChanged is returned if the original file no
+//longer matches the copy.
+func (c *Config) cachedData(s StaticFile) ([]byte, error) {
+	var cache *lruCache
+	if l := c.currentLookup(); l != nil {
+		cache = l.memoryCache
+	}
+
+	if cache != nil {
+		if data, ok := cache.get(s.cacheBustURLPath); ok {
+			return data, nil
+		}
+	}
+
+	data, err := c.readOriginal(s)
+	if err != nil {
+		return nil, err
+	}
+	if s.hash != "" && c.nameHash(s, sha256.Sum256(data)) != s.hash {
+		return nil, &FileError{Path: s.LocalPath, Err: ErrOriginalChanged}
+	}
+
+	if cache != nil {
+		cache.add(s.cacheBustURLPath, data)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,139 @@
+package cachebusting
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemoryCacheBytes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/a.js": {Data: []byte("console.log('a');")},
+		"static/js/b.js": {Data: []byte("console.log('b');")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.MemoryCacheBytes = 20
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	a, _ := c.findByOriginalName("a.js")
+	b, _ := c.findByOriginalName("b.js")
+	h := c.Handler(HandlerOptions{CacheDays: 1})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Only as much data as fits is kept in memory.
+	if !a.evicted || a.fileData != nil {
+		t.Fatal("Copy data should be kept in the memory cache")
+		return
+	}
+	if size := c.currentLookup().memoryCache.size(); size != 17 {
+		t.Fatal("Memory cache size not as expected", size)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies removed from memory are read again from the original file.
+	for _, s := range []StaticFile{a, b, a} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, s.cacheBustURLPath, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != string(fsys[s.LocalPath].Data) {
+			t.Fatal("Copy not served as expected", s.URLPath, rec.Code, rec.Body.String())
+			return
+		}
+	}
+	err = c.Verify()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A changed original file is not served as the copy.
+	fsys["static/js/b.js"] = &fstest.MapFile{Data: []byte("console.log('changed');")}
+	_, err = c.cachedData(b)
+	if !errors.Is(err, ErrOriginalChanged) {
+		t.Fatal("ErrOriginalChanged should have occured", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMemoryCacheTooLarge(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/a.js":     {Data: []byte("console.log('a');")},
+		"static/js/large.js": {Data: []byte("console.log('this copy can never fit');")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.MemoryCacheBytes = 20
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies larger than the memory cache are kept in memory.
+	large, _ := c.findByOriginalName("large.js")
+	if large.evicted || large.fileData == nil {
+		t.Fatal("Copy larger than the memory cache should be kept in memory")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Evicted copies are counted separately from copies in memory and on disk.
+	s := c.Stats()
+	if s.Evicted != 1 || s.InMemory != 1 || s.OnDisk != 0 {
+		t.Fatal("Stats not as expected", s.Evicted, s.InMemory, s.OnDisk)
+		return
+	}
+	if s.MemoryBytes != int64(len(large.fileData)) {
+		t.Fatal("Memory bytes not as expected", s.MemoryBytes)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMemoryCacheRollback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/a.js": {Data: []byte("console.log('a');")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.MemoryCacheBytes = 20
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	cache := c.currentLookup().memoryCache
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The memory cache of a failed Create() is not used.
+	c.StaticFiles = append(c.StaticFiles, StaticFile{LocalPath: "static/js/missing.js", URLPath: "/static/js/missing.js"})
+	err = c.Create()
+	if err == nil {
+		t.Fatal("Error should have occured but did not")
+		return
+	}
+	if c.currentLookup().memoryCache != cache {
+		t.Fatal("Memory cache of the failed Create should not be used")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Rebuilding the lookup keeps the memory cache.
+	c.StaticFiles = c.StaticFiles[:1]
+	c.buildIndex()
+	if c.currentLookup().memoryCache != cache {
+		t.Fatal("Memory cache should be kept")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,80 @@
+package cachebusting
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+//ServeOutcome is how a request was served by Handler(), see ServeInfo.Outcome.
+type ServeOutcome string
+
+//outcomes of serving a request
+const (
+	//OutcomeHashed is a request for a cache busting copy.
+	OutcomeHashed ServeOutcome = "hashed"
+
+	//OutcomeVendor is a request for a vendor file, see StaticFile.Vendor.
+	OutcomeVendor ServeOutcome = "vendor"
+
+	//OutcomeFallback is a request for a file served by the file server, i.e. an original
+	//file referenced without using its cache busting URL, or a file that isn't a static
+	//file. All requests are served by the file server when Development or NoCopy is true.
+	OutcomeFallback ServeOutcome = "fallback"
+
+	//OutcomeNotFound is a request for a file that doesn't exist, responded to with a 404
+	//Not Found or 410 Gone.
+	OutcomeNotFound ServeOutcome = "not-found"
+)
+
+//serveOutcome returns the outcome of a request given the response's status code and the
+//static file found for the request, if any. A blank outcome is returned if the request
+//was rejected, i.e. it wasn't authorized or the path was invalid.
+func (c *Config) serveOutcome(status int, s StaticFile, found bool) ServeOutcome {
+	switch {
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return OutcomeNotFound
+	case status >= http.StatusBadRequest:
+		return ""
+	case !found || c.Development || c.NoCopy:
+		return OutcomeFallback
+	case s.Vendor:
+		return OutcomeVendor
+	default:
+		return OutcomeHashed
+	}
+}
+
+//ServeCounters counts the requests served by Handler() by outcome, see
+//HandlerOptions.Counters. The counts must be read with atomic.LoadInt64() while requests
+//are being served.
+type ServeCounters struct {
+	//Hashed is the number of requests for cache busting copies.
+	Hashed int64
+
+	//Vendor is the number of requests for vendor files.
+	Vendor int64
+
+	//Fallback is the number of requests served by the file server.
+	Fallback int64
+
+	//NotFound is the number of requests for files that don't exist.
+	NotFound int64
+}
+
+//add increments the count for an outcome. Nothing is done if counters is nil.
+func (sc *ServeCounters) add(o ServeOutcome) {
+	if sc == nil {
+		return
+	}
+
+	switch o {
+	case OutcomeHashed:
+		atomic.AddInt64(&sc.Hashed, 1)
+	case OutcomeVendor:
+		atomic.AddInt64(&sc.Vendor, 1)
+	case OutcomeFallback:
+		atomic.AddInt64(&sc.Fallback, 1)
+	case OutcomeNotFound:
+		atomic.AddInt64(&sc.NotFound, 1)
+	}
+}
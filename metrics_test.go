@@ -0,0 +1,66 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandlerCounters(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/js/vendor/lib.js":   {Data: []byte("console.log(2);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k := range c.StaticFiles {
+		if c.StaticFiles[k].URLPath == "/static/js/vendor/lib.js" {
+			c.StaticFiles[k].Vendor = true
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	script, _ := c.findByOriginalName("script.min.js")
+
+	var counters ServeCounters
+	var outcomes []ServeOutcome
+	h := c.Handler(HandlerOptions{
+		CacheDays: 1,
+		Counters:  &counters,
+		OnServe: func(i ServeInfo) {
+			outcomes = append(outcomes, i.Outcome)
+		},
+	})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Each request is counted by outcome.
+	tests := []struct {
+		path    string
+		outcome ServeOutcome
+	}{
+		{script.cacheBustURLPath, OutcomeHashed},
+		{"/static/js/vendor/lib.js", OutcomeVendor},
+		{"/static/css/styles.min.css", OutcomeFallback},
+		{"/static/js/missing.js", OutcomeNotFound},
+	}
+	for _, tc := range tests {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+	}
+
+	for k, tc := range tests {
+		if outcomes[k] != tc.outcome {
+			t.Fatal("Outcome not as expected", tc.path, outcomes[k])
+			return
+		}
+	}
+	if atomic.LoadInt64(&counters.Hashed) != 1 || atomic.LoadInt64(&counters.Vendor) != 1 || atomic.LoadInt64(&counters.Fallback) != 1 || atomic.LoadInt64(&counters.NotFound) != 1 {
+		t.Fatal("Counts not as expected", atomic.LoadInt64(&counters.Hashed), atomic.LoadInt64(&counters.Vendor), atomic.LoadInt64(&counters.Fallback), atomic.LoadInt64(&counters.NotFound))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,61 @@
+package cachebusting
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+//builtinContentTypes are the content types of extensions that are commonly missing, or
+//wrong, in the host OS's mime database. Browsers refuse to run module scripts, and
+//WebAssembly files served without the correct content type, so these are used rather
+//than relying on mime.TypeByExtension().
+var builtinContentTypes = map[string]string{
+	".js":          "text/javascript; charset=utf-8",
+	".mjs":         "text/javascript; charset=utf-8",
+	".cjs":         "text/javascript; charset=utf-8",
+	".css":         "text/css; charset=utf-8",
+	".wasm":        "application/wasm",
+	".webmanifest": "application/manifest+json",
+}
+
+//contentType returns the content type for a file extension, i.e. ".js". The built in
+//content types are checked first, see builtinContentTypes, followed by the host OS's mime
+//database. A blank string is returned if the content type is not known.
+func contentType(ext string) string {
+	if t, ok := builtinContentTypes[strings.ToLower(ext)]; ok {
+		return t
+	}
+
+	return mime.TypeByExtension(ext)
+}
+
+//defaultCharset is the charset added to text content types, see HandlerOptions.Charset.
+const defaultCharset = "utf-8"
+
+//withCharset returns a content type with the charset parameter set to charset for text
+//content types, i.e. text/css and application/javascript. Any existing charset is
+//replaced, or removed if charset is blank. Other content types are returned as-is.
+func withCharset(ct, charset string) string {
+	mediaType, params, err := mime.ParseMediaType(ct)
+	if err != nil || (!strings.HasPrefix(mediaType, "text/") && mediaType != "application/javascript") {
+		return ct
+	}
+
+	delete(params, "charset")
+	if charset != "" {
+		params["charset"] = charset
+	}
+
+	return mime.FormatMediaType(mediaType, params)
+}
+
+//setContentType sets the Content-Type header of a response, with the charset for text
+//content types, if the content type of the extension is known. This is used before
+//serving a file with http.ServeFile() or http.FileServer(), which use the host OS's mime
+//database, and which keep a Content-Type header that is already set.
+func setContentType(w http.ResponseWriter, ext, charset string) {
+	if ct := contentType(ext); ct != "" {
+		w.Header().Set("Content-Type", withCharset(ct, charset))
+	}
+}
@@ -0,0 +1,164 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestContentType(t *testing.T) {
+	tests := map[string]string{
+		".mjs":         "text/javascript; charset=utf-8",
+		".CJS":         "text/javascript; charset=utf-8",
+		".wasm":        "application/wasm",
+		".webmanifest": "application/manifest+json",
+		".png":         "image/png",
+		".unknownext":  "",
+	}
+
+	for ext, expected := range tests {
+		if ct := contentType(ext); ct != expected {
+			t.Fatal("Content type not as expected", ext, ct)
+			return
+		}
+	}
+}
+
+func TestHandlerContentType(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/app.mjs":       {Data: []byte("export default 1;")},
+		"static/wasm/app.wasm":    {Data: []byte("\x00asm")},
+		"static/site.webmanifest": {Data: []byte("{}")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	h := c.Handler(HandlerOptions{CacheDays: 1})
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies stored in memory use the built in content types.
+	for name, expected := range map[string]string{
+		"app.mjs":          "text/javascript; charset=utf-8",
+		"app.wasm":         "application/wasm",
+		"site.webmanifest": "application/manifest+json",
+	} {
+		s, _ := c.findByOriginalName(name)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, s.cacheBustURLPath, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != expected {
+			t.Fatal("Content type not as expected", name, rec.Code, rec.Header().Get("Content-Type"))
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Original files served by the file server use the built in content types.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/js/app.mjs", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/javascript; charset=utf-8" {
+		t.Fatal("Content type not as expected", rec.Code, rec.Header().Get("Content-Type"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestHandlerContentTypeDisk(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "app.wasm")
+	err := os.WriteFile(p, []byte("\x00asm"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/wasm/app.wasm"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies saved to disk use the built in content types.
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.StaticFiles[0].cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/wasm" {
+		t.Fatal("Content type not as expected", rec.Code, rec.Header().Get("Content-Type"))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestWithCharset(t *testing.T) {
+	tests := []struct {
+		in       string
+		charset  string
+		expected string
+	}{
+		{"text/css", "utf-8", "text/css; charset=utf-8"},
+		{"application/javascript", "utf-8", "application/javascript; charset=utf-8"},
+		{"text/javascript; charset=utf-8", "iso-8859-1", "text/javascript; charset=iso-8859-1"},
+		{"text/css; charset=utf-8", "", "text/css"},
+		{"image/png", "utf-8", "image/png"},
+		{"", "utf-8", ""},
+	}
+
+	for _, tc := range tests {
+		if ct := withCharset(tc.in, tc.charset); ct != tc.expected {
+			t.Fatal("Content type not as expected", tc.in, ct)
+			return
+		}
+	}
+}
+
+func TestHandlerCharset(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/css/styles.min.css": {Data: []byte(`body:before{content:"é"}`)},
+		"static/data.csv":           {Data: []byte("a,é")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	css, _ := c.findByOriginalName("styles.min.css")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//utf-8 is added by default, whether a copy or an original file is served.
+	h := c.Handler(HandlerOptions{CacheDays: 1})
+	for _, p := range []string{css.cacheBustURLPath, "/static/data.csv"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || !strings.HasSuffix(ct, "; charset=utf-8") {
+			t.Fatal("Charset not added", p, rec.Code, ct)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The charset can be changed or omitted.
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1, Charset: "iso-8859-1"}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, css.cacheBustURLPath, nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "text/css; charset=iso-8859-1" {
+		t.Fatal("Charset not changed", ct)
+		return
+	}
+
+	rec = httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1, OmitCharset: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, css.cacheBustURLPath, nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "text/css" {
+		t.Fatal("Charset not omitted", ct)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,65 @@
+package cachebusting
+
+import (
+	"html/template"
+	"strings"
+)
+
+//moduleOrder returns the static files an entry module depends on, followed by the entry
+//module itself, such that each module is listed after the modules it imports. Each module
+//is only listed once, even if imported by more than one module, and import cycles are
+//ignored.
+func (c *Config) moduleOrder(entry string) (order []StaticFile, err error) {
+	visited := make(map[string]bool)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+
+		s, found := c.findByOriginalName(name)
+		if !found {
+			return &FileError{Path: name, Err: ErrNotFound}
+		}
+
+		for _, i := range s.Imports {
+			err := visit(i)
+			if err != nil {
+				return err
+			}
+		}
+
+		order = append(order, s)
+		return nil
+	}
+
+	err = visit(entry)
+	return
+}
+
+//ModulePreloadTags returns a <link rel="modulepreload"> element for an entry ES module and
+//each module it imports, directly or indirectly, given the original file's name of the
+//entry module. The imports of each module are listed in the module's Imports field.
+//Modules are output in dependency order, each module after the modules it imports, so the
+//browser can fetch every module at once rather than discovering imports one level at a
+//time. Place the returned elements in the <head> of your HTML.
+func (c *Config) ModulePreloadTags(entry string) (t template.HTML, err error) {
+	order, err := c.moduleOrder(entry)
+	if err != nil {
+		return
+	}
+
+	var b strings.Builder
+	for _, s := range order {
+		b.WriteString(`<link rel="modulepreload" href="` + template.HTMLEscapeString(c.urlFor(s)) + `"` + integrityAttrs(s) + ">\n")
+	}
+
+	return template.HTML(b.String()), nil
+}
+
+//ModulePreloadTags returns the modulepreload elements using the package level config.
+func ModulePreloadTags(entry string) (template.HTML, error) {
+	return config.ModulePreloadTags(entry)
+}
@@ -0,0 +1,66 @@
+package cachebusting
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestModulePreloadTags(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/app.js":   {Data: []byte("import './util.js'; import './ui.js';")},
+		"static/js/ui.js":    {Data: []byte("import './util.js';")},
+		"static/js/util.js":  {Data: []byte("export const a = 1;")},
+		"static/js/other.js": {Data: []byte("export const b = 1;")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k, s := range c.StaticFiles {
+		switch s.URLPath {
+		case "/static/js/app.js":
+			c.StaticFiles[k].Imports = []string{"ui.js", "util.js"}
+		case "/static/js/ui.js":
+			c.StaticFiles[k].Imports = []string{"util.js", "app.js"} //cycle
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Modules are output once each, after the modules they import.
+	tags, err := c.ModulePreloadTags("app.js")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	var expected string
+	for _, name := range []string{"util.js", "ui.js", "app.js"} {
+		s, _ := c.findByOriginalName(name)
+		expected += `<link rel="modulepreload" href="` + s.cacheBustURLPath + `" integrity="` + s.integrity + `" crossorigin="anonymous">` + "\n"
+	}
+	if string(tags) != expected {
+		t.Fatal("Tags not as expected", tags)
+		return
+	}
+	if strings.Contains(string(tags), "other.js") {
+		t.Fatal("Module not imported should not be preloaded")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unknown modules return an error.
+	c.StaticFiles[0].Imports = []string{"missing.js"}
+	c.buildIndex()
+	_, err = c.ModulePreloadTags(path.Base(c.StaticFiles[0].URLPath))
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("ErrNotFound should have occured but didn't", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,104 @@
+package cachebusting
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+//noCopy maps each static file to itself, without creating any copies, for NoCopy. Each
+//file's URL is the original file's URL path with a version query added, see
+//versionQuery. The original files are hashed, one at a time without keeping the data in
+//memory, unless BuildID is provided.
+func (c *Config) noCopy() error {
+	for k, s := range c.StaticFiles {
+		c.StaticFiles[k].cacheBustURLPath = s.URLPath
+		c.StaticFiles[k].cacheBustLocalPath = s.LocalPath
+		c.StaticFiles[k].fileData = nil
+		c.StaticFiles[k].hash = ""
+		c.StaticFiles[k].integrity = ""
+
+		if c.BuildID != "" || s.Vendor {
+			continue
+		}
+
+		//the original file, as-is, is what will be served so the hash and integrity
+		//are of the original file's data rather than the data returned by readOriginal.
+		p := s.LocalPath
+		if c.usesFS() {
+			p = filepath.ToSlash(s.LocalPath)
+		}
+		data := s.sourceData
+		if data == nil {
+			var err error
+			data, err = c.readFunc()(p)
+			if err != nil {
+				return err
+			}
+		}
+
+		h := sha256.Sum256(data)
+		c.StaticFiles[k].hash = c.nameHash(s, h)
+		c.StaticFiles[k].integrity = "sha256-" + base64.StdEncoding.EncodeToString(h[:])
+	}
+
+	c.buildIndex()
+
+	if c.Debug {
+		log.Println("cachebusting.Create (debug)", "no copies created, config field NoCopy is true")
+	}
+
+	return nil
+}
+
+//versionQuery returns the query, i.e. "?v=A1B2C3D4", added to the URL of a static file
+//when NoCopy is true. The version is BuildID, if provided, otherwise the hash of the file
+//truncated to HashLength. A blank string is returned if NoCopy is false or the file isn't
+//versioned, i.e. a vendor file.
+func (c *Config) versionQuery(s StaticFile) string {
+	v := c.version(s)
+	if v == "" {
+		return ""
+	}
+
+	return "?v=" + url.QueryEscape(v)
+}
+
+//versionMatches reports if a request for a static file includes the file's current
+//version, see versionQuery. Such a request can be cached as immutable since the version
+//changes whenever the file does.
+func (c *Config) versionMatches(r *http.Request, s StaticFile) bool {
+	v := c.version(s)
+	return v != "" && r.URL.Query().Get("v") == v
+}
+
+//version returns the version of a static file used in the query added to the file's URL,
+//see versionQuery.
+func (c *Config) version(s StaticFile) string {
+	if !c.NoCopy || s.Vendor {
+		return ""
+	}
+
+	if c.BuildID != "" {
+		return c.BuildID
+	}
+	if s.hash == "" {
+		return ""
+	}
+
+	hashLength := c.HashLength
+	if hashLength == 0 || hashLength > uint(len(s.hash)) {
+		hashLength = defaultHashLength
+	}
+
+	v := s.hash[:hashLength]
+	if c.LowercaseHash {
+		v = strings.ToLower(v)
+	}
+
+	return v
+}
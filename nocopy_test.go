@@ -0,0 +1,127 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNoCopy(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "static", "js", "script.min.js")
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No copies are saved and the URL uses the hash as the version.
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.NoCopy = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(p))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(entries) != 1 {
+		t.Fatal("Copies should not have been saved", len(entries))
+		return
+	}
+
+	s := c.StaticFiles[0]
+	u := c.AssetURL("script.min.js")
+	if u != "/static/js/script.min.js?v="+s.hash[:defaultHashLength] {
+		t.Fatal("URL not versioned as expected", u)
+		return
+	}
+	if c.GetURLPairs()["script.min.js"] != u {
+		t.Fatal("URL pairs not versioned as expected", c.GetURLPairs())
+		return
+	}
+	if s.integrity == "" || s.fileData != nil {
+		t.Fatal("Integrity should be set and data not stored", s.integrity, len(s.fileData))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The build ID is used as the version when provided.
+	c = NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	c.NoCopy = true
+	c.BuildID = "v1.2.3 rc"
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if u := c.AssetURL("script.min.js"); u != "/static/js/script.min.js?v=v1.2.3+rc" {
+		t.Fatal("URL not versioned with build ID", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestNoCopyHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.NoCopy = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The original file is served from the filesystem, ignoring the version query.
+	u := c.AssetURL("script.min.js")
+	if !strings.HasPrefix(u, "/static/js/script.min.js?v=") {
+		t.Fatal("URL not versioned", u)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	c.Handler(HandlerOptions{CacheDays: 1}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1);" || rec.Header().Get("X-Static-Served-From") != "fs" {
+		t.Fatal("Original file not served", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The file is cached as immutable only when requested with the current version.
+	tests := []struct {
+		url       string
+		immutable bool
+	}{
+		{u, true},
+		{"/static/js/script.min.js?v=OLDVERSION", false},
+		{"/static/js/script.min.js", false},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		c.Handler(HandlerOptions{CacheDays: 1}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if strings.HasSuffix(rec.Header().Get("Cache-Control"), ",immutable") != tt.immutable {
+			t.Fatal("Cache-Control not as expected", tt.url, rec.Header().Get("Cache-Control"))
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
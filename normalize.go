@@ -0,0 +1,69 @@
+package cachebusting
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//textExtensions is the list of file extensions treated as text when normalizing files.
+var textExtensions = map[string]bool{
+	".js":   true,
+	".mjs":  true,
+	".cjs":  true,
+	".css":  true,
+	".html": true,
+	".htm":  true,
+	".svg":  true,
+	".json": true,
+	".map":  true,
+	".txt":  true,
+	".xml":  true,
+}
+
+//utf8BOM is the byte order mark some editors on Windows add to the start of text files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+//normalize returns a text file's data with the UTF-8 byte order mark removed and CRLF line
+//endings converted to LF so that the same file results in the same hash whether it was
+//checked out or built on Windows or Linux. Gzipped files have the modification time and
+//operating system in the gzip header cleared for the same reason. Other files are returned
+//as-is.
+func (c *Config) normalize(s StaticFile, data []byte) []byte {
+	if !c.Normalize || s.Vendor {
+		return data
+	}
+
+	ext := strings.ToLower(path.Ext(filepath.ToSlash(s.LocalPath)))
+	if ext == ".gz" {
+		return normalizeGzipHeader(data)
+	}
+	if !textExtensions[ext] {
+		return data
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if bytes.Contains(data, []byte("\r\n")) {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+
+	return data
+}
+
+//normalizeGzipHeader clears the modification time and operating system fields of a gzip
+//header. These fields differ per build machine but do not affect the decompressed data.
+//Data that isn't gzipped is returned as-is.
+func normalizeGzipHeader(data []byte) []byte {
+	//header is: magic (2 bytes), compression method, flags, mtime (4 bytes), extra
+	//flags, os.
+	if len(data) < 10 || data[0] != 0x1f || data[1] != 0x8b {
+		return data
+	}
+
+	out := make([]byte, len(data))
+	copy(out, data)
+	copy(out[4:8], []byte{0, 0, 0, 0})
+	out[9] = 0xff //unknown
+	return out
+}
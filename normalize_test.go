@@ -0,0 +1,66 @@
+package cachebusting
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestNormalize(t *testing.T) {
+	windows := fstest.MapFS{
+		"static/js/app.js":    {Data: []byte("\xEF\xBB\xBFconsole.log(1);\r\nconsole.log(2);\r\n")},
+		"static/img/logo.png": {Data: []byte("png\r\n")},
+	}
+	linux := fstest.MapFS{
+		"static/js/app.js":    {Data: []byte("console.log(1);\nconsole.log(2);\n")},
+		"static/img/logo.png": {Data: []byte("png\r\n")},
+	}
+
+	hashes := make([]map[string]string, 0, 2)
+	for _, fsys := range []fstest.MapFS{windows, linux} {
+		c := NewFSConfig(fsys, "static", "/static")
+		c.Normalize = true
+		err := c.Create()
+		if err != nil {
+			t.Fatal("Error occured but should not have", err)
+			return
+		}
+
+		h := make(map[string]string)
+		for _, s := range c.StaticFiles {
+			h[s.URLPath] = s.hash
+		}
+		hashes = append(hashes, h)
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Text files result in the same hash, binary files are not modified.
+	if hashes[0]["/static/js/app.js"] != hashes[1]["/static/js/app.js"] {
+		t.Fatal("Text file hashes should match", hashes)
+		return
+	}
+
+	c := NewFSConfig(windows, "static", "/static")
+	c.Normalize = true
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	for _, s := range c.StaticFiles {
+		if s.URLPath == "/static/img/logo.png" && string(s.fileData) != "png\r\n" {
+			t.Fatal("Binary file should not have been normalized", s.fileData)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Gzip header modification time and OS are cleared.
+	a := normalizeGzipHeader([]byte{0x1f, 0x8b, 8, 0, 1, 2, 3, 4, 0, 3, 'x'})
+	b := normalizeGzipHeader([]byte{0x1f, 0x8b, 8, 0, 5, 6, 7, 8, 0, 0, 'x'})
+	if string(a) != string(b) {
+		t.Fatal("Gzip headers should match", a, b)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
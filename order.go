@@ -0,0 +1,41 @@
+package cachebusting
+
+import "path/filepath"
+
+//orderByDependencies returns the static files ordered such that each file is listed after
+//the files it depends on, see DependsOn. Files are otherwise kept in the order provided.
+//Dependencies that are not in the list of files are ignored, as are dependency cycles.
+func orderByDependencies(files []StaticFile) []StaticFile {
+	byName := make(map[string]int, len(files))
+	for i, s := range files {
+		name := filepath.Base(s.LocalPath)
+		if _, ok := byName[name]; !ok {
+			byName[name] = i
+		}
+	}
+
+	ordered := make([]StaticFile, 0, len(files))
+	visited := make([]bool, len(files))
+
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+
+		for _, d := range files[i].DependsOn {
+			if j, ok := byName[d]; ok {
+				visit(j)
+			}
+		}
+
+		ordered = append(ordered, files[i])
+	}
+
+	for i := range files {
+		visit(i)
+	}
+
+	return ordered
+}
@@ -0,0 +1,133 @@
+package cachebusting
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//preloadTypes maps file extensions to the value used in the "as" attribute of a preload
+//link. Files with an extension not listed here are not preloaded since the browser will
+//ignore a preload without a valid "as" value.
+var preloadTypes = map[string]string{
+	".woff2": "font",
+	".woff":  "font",
+	".ttf":   "font",
+	".otf":   "font",
+	".eot":   "font",
+	".png":   "image",
+	".jpg":   "image",
+	".jpeg":  "image",
+	".gif":   "image",
+	".webp":  "image",
+	".avif":  "image",
+	".svg":   "image",
+	".ico":   "image",
+	".css":   "style",
+	".js":    "script",
+	".mjs":   "script",
+}
+
+//preload is the information needed to preload a single file.
+type preload struct {
+	url         string
+	as          string
+	crossorigin bool
+}
+
+//preloads returns the preload information for each static file marked as Critical, with
+//each file after the files it depends on. Fonts are always preloaded with crossorigin
+//since browsers fetch fonts in anonymous mode and would otherwise not use the preloaded
+//file.
+func (c *Config) preloads() (p []preload) {
+	var critical []StaticFile
+	for _, v := range c.servedFiles() {
+		if v.Critical {
+			critical = append(critical, v)
+		}
+	}
+
+	for _, v := range orderByDependencies(critical) {
+		as, ok := preloadTypes[strings.ToLower(path.Ext(v.URLPath))]
+		if !ok {
+			continue
+		}
+
+		p = append(p, preload{
+			url:         c.urlFor(v),
+			as:          as,
+			crossorigin: as == "font",
+		})
+	}
+
+	return
+}
+
+//PreloadTags returns a <link rel="preload"> element for each static file marked as
+//Critical. Place the returned elements in the <head> of your HTML.
+func (c *Config) PreloadTags() template.HTML {
+	var b strings.Builder
+	for _, p := range c.preloads() {
+		b.WriteString(`<link rel="preload" href="` + template.HTMLEscapeString(p.url) + `" as="` + p.as + `"`)
+		if p.crossorigin {
+			b.WriteString(" crossorigin")
+		}
+		b.WriteString(">\n")
+	}
+
+	return template.HTML(b.String())
+}
+
+//PreloadTags returns the preload elements for the package level config.
+func PreloadTags() template.HTML {
+	return config.PreloadTags()
+}
+
+//PreloadLinkHeader returns the value for a Link HTTP header that preloads each static
+//file marked as Critical. A blank string is returned if no files are marked as Critical.
+//
+//Ex.: w.Header().Set("Link", c.PreloadLinkHeader())
+func (c *Config) PreloadLinkHeader() string {
+	var links []string
+	for _, p := range c.preloads() {
+		l := "<" + p.url + ">; rel=preload; as=" + p.as
+		if p.crossorigin {
+			l += "; crossorigin"
+		}
+		links = append(links, l)
+	}
+
+	return strings.Join(links, ", ")
+}
+
+//PreloadLinkHeader returns the Link header value for the package level config.
+func PreloadLinkHeader() string {
+	return config.PreloadLinkHeader()
+}
+
+//EarlyHints is middleware that sends a 103 Early Hints response, with a Link header
+//preloading each static file marked as Critical, prior to calling the next handler. This
+//lets the browser start fetching critical files while your handler is still building
+//the HTML response. Use this to wrap the handlers for your HTML pages, not for the
+//static file handler.
+//
+//The Link header is left in place so it is also sent with the final response for clients
+//or proxies that do not support 103 responses. The 103 response is only sent when built
+//with Go 1.19 or later, older versions send the Link header with the final response only.
+func (c *Config) EarlyHints(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		link := c.PreloadLinkHeader()
+		if link != "" {
+			w.Header().Add("Link", link)
+			writeEarlyHints(w)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+//EarlyHints wraps a handler with EarlyHints using the package level config.
+func EarlyHints(next http.Handler) http.Handler {
+	return config.EarlyHints(next)
+}
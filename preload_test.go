@@ -0,0 +1,117 @@
+package cachebusting
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreload(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	css.Critical = true
+	js := NewStaticFile(filepath.Join(dir, "_testdata", "static", "js", "script.min.js"), path.Join("/", "static", "js", "script.min.js"))
+	font := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "fonts", "font.woff2"))
+	font.Critical = true
+	c := NewOnDiskConfig(css, js, font)
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Only critical files are preloaded, fonts are preloaded with crossorigin.
+	tags := string(c.PreloadTags())
+	if strings.Count(tags, "<link") != 2 {
+		t.Fatal("Only critical files should be preloaded", tags)
+		return
+	}
+	if !strings.Contains(tags, `href="`+c.StaticFiles[0].cacheBustURLPath+`" as="style">`) {
+		t.Fatal("Style preload not built correctly", tags)
+		return
+	}
+	if !strings.Contains(tags, `as="font" crossorigin>`) {
+		t.Fatal("Font preload not built correctly", tags)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Link header.
+	h := c.PreloadLinkHeader()
+	if !strings.HasPrefix(h, "<"+c.StaticFiles[0].cacheBustURLPath+">; rel=preload; as=style, <") {
+		t.Fatal("Link header not built correctly", h)
+		return
+	}
+	if !strings.HasSuffix(h, "; rel=preload; as=font; crossorigin") {
+		t.Fatal("Link header not built correctly", h)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestEarlyHintsFinalResponse(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	css.Critical = true
+	c := NewOnDiskConfig(css)
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html></html>"))
+	})
+	srv := httptest.NewServer(c.EarlyHints(page))
+	defer srv.Close()
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The page's status, headers, and body are sent as the final response, with the Link
+	//header, regardless of the version of Go.
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if res.StatusCode != http.StatusOK || string(b) != "<html></html>" {
+		t.Fatal("Final response not as expected", res.StatusCode, string(b))
+		return
+	}
+	if res.Header.Get("Content-Type") != "text/html" {
+		t.Fatal("Page's headers not sent with final response", res.Header)
+		return
+	}
+	if !strings.Contains(res.Header.Get("Link"), c.StaticFiles[0].cacheBustURLPath) {
+		t.Fatal("Link header not sent with final response", res.Header)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
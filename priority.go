@@ -0,0 +1,80 @@
+package cachebusting
+
+import (
+	"log"
+	"path"
+	"path/filepath"
+)
+
+//priorityOrder returns the indexes of the static files in the order the files are handled
+//by Create(), with the files marked Priority first. Files are otherwise kept in the order
+//provided. The static files themselves are not reordered. The number of files marked
+//Priority is also returned.
+func priorityOrder(files []StaticFile) (order []int, priority int) {
+	order = make([]int, 0, len(files))
+	for k, s := range files {
+		if s.Priority {
+			order = append(order, k)
+		}
+	}
+	priority = len(order)
+
+	for k, s := range files {
+		if !s.Priority {
+			order = append(order, k)
+		}
+	}
+
+	return
+}
+
+//warmUp starts serving the copies of the files marked Priority, the static files at the
+//indexes provided, once the files have been hashed and while the other files are still
+//being hashed. This is only done the first time Create() is called, when nothing is being
+//served yet, since otherwise the files from the prior call to Create() are still being
+//served. Only copies stored in memory whose data is not changed by Create(), see
+//modifies(), can be served early. In the rare case that a copy's hash collides with a
+//file hashed later, the copy's URL changes when Create() completes.
+//
+//fileData and hashLengths are in the same order as StaticFiles.
+func (c *Config) warmUp(indexes []int, fileData [][]byte, hashLengths []uint) {
+	if c.currentLookup() != nil {
+		return
+	}
+
+	warm := make([]StaticFile, 0, len(indexes))
+	served := make(map[string]string, len(indexes))
+	for _, k := range indexes {
+		s := c.StaticFiles[k]
+		if !c.inMemory(s) || c.modifies(s) || (fileData[k] == nil && !s.streamed) {
+			continue
+		}
+
+		s.cacheBustURLPath = s.URLPath
+		if !s.Vendor {
+			name := c.cacheBustFilename(s.hash, hashLengths[k], path.Base(filepath.ToSlash(s.LocalPath)))
+			s.cacheBustURLPath = path.Join(path.Dir(s.URLPath), name)
+		}
+		s.fileData = fileData[k]
+
+		//don't serve anything early if two files would be served on the same URL path,
+		//collisions are resolved once every file is hashed.
+		if hash, ok := served[s.cacheBustURLPath]; ok && hash != s.hash {
+			return
+		}
+		served[s.cacheBustURLPath] = s.hash
+
+		warm = append(warm, s)
+	}
+	if len(warm) == 0 {
+		return
+	}
+
+	l := newLookup(warm)
+	l.copyMatchers = c.copyMatchers(l.files)
+	c.current.Store(l)
+
+	if c.Debug {
+		log.Println("cachebusting.Create (debug)", "serving priority files while other files are hashed", len(warm))
+	}
+}
@@ -0,0 +1,137 @@
+package cachebusting
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+//blockingFS blocks reading a file until released so that the state of Create() can be
+//checked while the file is being read.
+type blockingFS struct {
+	fstest.MapFS
+	block    string
+	reading  chan struct{}
+	released chan struct{}
+}
+
+//ReadFile blocks reading the blocked file until released.
+func (b blockingFS) ReadFile(name string) ([]byte, error) {
+	if name == b.block {
+		close(b.reading)
+		<-b.released
+	}
+
+	return fs.ReadFile(b.MapFS, name)
+}
+
+func TestPriority(t *testing.T) {
+	fsys := blockingFS{
+		MapFS: fstest.MapFS{
+			"static/css/styles.min.css": {Data: []byte("body{}")},
+			"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		},
+		block:    "static/css/styles.min.css",
+		reading:  make(chan struct{}),
+		released: make(chan struct{}),
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	for k, s := range c.StaticFiles {
+		if s.URLPath == "/static/js/script.min.js" {
+			c.StaticFiles[k].Priority = true
+		}
+	}
+	h := c.Handler(HandlerOptions{CacheDays: 1})
+
+	done := make(chan error)
+	go func() {
+		done <- c.Create()
+	}()
+	<-fsys.reading
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Priority files are served while other files are hashed.
+	l := c.currentLookup()
+	if l == nil || len(l.files) != 1 || l.files[0].URLPath != "/static/js/script.min.js" {
+		t.Fatal("Only priority files should be served", l)
+		return
+	}
+	warm := l.files[0].cacheBustURLPath
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, warm, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1);" {
+		t.Fatal("Priority file not served early", rec.Code, rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	close(fsys.released)
+	err := <-done
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Priority files keep the URL they were served on early, and the static files are not
+	//reordered.
+	if c.StaticFiles[0].URLPath != "/static/css/styles.min.css" || c.StaticFiles[1].cacheBustURLPath != warm {
+		t.Fatal("Static files not as expected", c.StaticFiles)
+		return
+	}
+	if len(c.currentLookup().files) != 2 {
+		t.Fatal("All files should be served once Create() is done")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+//failingFS returns an error reading a file, after the file was found, so that Create()
+//fails after the priority files are served.
+type failingFS struct {
+	fstest.MapFS
+	fail string
+}
+
+//ReadFile returns an error reading the failing file.
+func (f failingFS) ReadFile(name string) ([]byte, error) {
+	if name == f.fail {
+		return nil, fs.ErrPermission
+	}
+
+	return fs.ReadFile(f.MapFS, name)
+}
+
+func TestPriorityRollback(t *testing.T) {
+	fsys := failingFS{
+		MapFS: fstest.MapFS{
+			"static/js/a.js": {Data: []byte("console.log(1);")},
+			"static/js/b.js": {Data: []byte("console.log(2);")},
+		},
+		fail: "static/js/a.js",
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[1].Priority = true
+	original := append([]StaticFile(nil), c.StaticFiles...)
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A failed Create() stops serving the priority files and leaves the static files as
+	//they were.
+	err := c.Create()
+	if err == nil {
+		t.Fatal("Error about missing file should have occured")
+		return
+	}
+	if c.currentLookup() != nil {
+		t.Fatal("Priority files should not be served after a failed Create()")
+		return
+	}
+	if len(c.StaticFiles) != len(original) || !c.StaticFiles[0].equal(original[0]) || !c.StaticFiles[1].equal(original[1]) {
+		t.Fatal("Static files not restored", c.StaticFiles)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,152 @@
+package cachebusting
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+//Asset is a cache busting copy, or a vendor file, being published to an AssetStore.
+type Asset struct {
+	//Key is the cache busting URL path of the file without the leading "/", i.e.
+	//static/js/A1B2C3D4.script.min.js. Serving each asset at its key from the root of a
+	//CDN origin results in the same URLs as serving the files from your app.
+	Key string
+
+	//Data is the contents of the file.
+	Data []byte
+
+	//ContentType is the MIME type of the file based on the file's extension.
+	ContentType string
+
+	//CacheControl is the value for the Cache-Control header the file should be served
+	//with, see PublishCacheControl.
+	CacheControl string
+
+	//Hash is the full hash of the file's contents.
+	Hash string
+}
+
+//AssetStore is a place cache busting copies are published to, for example a bucket used
+//as the origin of a CDN.
+type AssetStore interface {
+	//Put saves an asset. Saving an asset that already exists should overwrite the asset.
+	Put(ctx context.Context, a Asset) error
+}
+
+//defaultPublishCacheControl is the Cache-Control header value assets are published with
+//if PublishCacheControl isn't provided. Cache busting copies never change, so they can be
+//cached for as long as possible.
+const defaultPublishCacheControl = "public,max-age=31536000,immutable"
+
+//assetData returns the data of a static file's cache busting copy, from memory or disk.
+func (c *Config) assetData(s StaticFile) ([]byte, error) {
+	if c.inMemory(s) {
+		return c.copyData(s)
+	}
+
+	return limitedRead(os.ReadFile)(s.cacheBustLocalPath)
+}
+
+//published returns the static files that have been cache busted and are published.
+func (c *Config) published() (files []StaticFile) {
+	for _, s := range c.sortedStaticFiles() {
+		if s.cacheBustURLPath == "" {
+			continue
+		}
+
+		files = append(files, s)
+	}
+
+	return
+}
+
+//asset returns the asset for a static file that has been cache busted. The file's data is
+//read when the asset is built, so only the assets being published are held in memory.
+func (c *Config) asset(s StaticFile) (a Asset, err error) {
+	data, err := c.assetData(s)
+	if err != nil {
+		return
+	}
+
+	cacheControl := c.PublishCacheControl
+	if cacheControl == "" {
+		cacheControl = defaultPublishCacheControl
+	}
+
+	contentType := contentType(path.Ext(s.cacheBustURLPath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	a = Asset{
+		Key:          strings.TrimPrefix(s.cacheBustURLPath, "/"),
+		Data:         data,
+		ContentType:  contentType,
+		CacheControl: cacheControl,
+		Hash:         s.hash,
+	}
+	return
+}
+
+//Publish saves each cache busting copy, and each vendor file, to the store. Create() must
+//have been called. This is done automatically by Create() if Store is provided. Up to
+//PublishConcurrency files are published at once, each file is read just before it is
+//published so that only the files being published are held in memory. The first error
+//encountered is returned and no more files are published.
+func (c *Config) Publish(ctx context.Context, store AssetStore) error {
+	concurrency := c.PublishConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+	for _, s := range c.published() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(s StaticFile) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			a, err := c.asset(s)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+
+			err = store.Put(ctx, a)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = &FileError{Path: a.Key, Err: err}
+					cancel()
+				})
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		return parent.Err()
+	}
+	return firstErr
+}
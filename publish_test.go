@@ -0,0 +1,263 @@
+package cachebusting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+//memoryStore is an AssetStore that keeps assets in memory for testing.
+type memoryStore struct {
+	mu     sync.Mutex
+	assets map[string]Asset
+	err    error
+}
+
+func (m *memoryStore) Put(ctx context.Context, a Asset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	if m.assets == nil {
+		m.assets = make(map[string]Asset)
+	}
+	m.assets[a.Key] = a
+	return nil
+}
+
+//azureClient is an AzureBlobClient that records uploads for testing.
+type azureClient struct {
+	uploads map[string]string
+}
+
+func (a *azureClient) UploadBlob(ctx context.Context, container, name string, data []byte, contentType, cacheControl string) error {
+	a.uploads[container+"/"+name] = contentType + ";" + cacheControl + ";" + string(data)
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Create() publishes each copy to the store.
+	store := &memoryStore{}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.Store = store
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	if len(store.assets) != 2 {
+		t.Fatal("Assets not published", len(store.assets))
+		return
+	}
+	for _, s := range c.StaticFiles {
+		a, ok := store.assets[s.cacheBustURLPath[1:]]
+		if !ok || string(a.Data) != string(s.fileData) || a.Hash != s.hash || a.CacheControl != defaultPublishCacheControl {
+			t.Fatal("Asset not published correctly", s.cacheBustURLPath, a)
+			return
+		}
+	}
+	if a := store.assets[c.StaticFiles[0].cacheBustURLPath[1:]]; a.ContentType != "text/css; charset=utf-8" {
+		t.Fatal("Content type not set", a.ContentType)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A failure to publish fails Create().
+	errPut := errors.New("put failed")
+	c = NewFSConfig(fsys, "static", "/static")
+	c.Store = &memoryStore{err: errPut}
+	err = c.Create()
+	if !errors.Is(err, errPut) {
+		t.Fatal("Publish error should have been returned", err)
+		return
+	}
+	if c.StaticFiles[0].cacheBustURLPath != "" {
+		t.Fatal("Static files should have been restored")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+//funcStore is an AssetStore that calls a func for each asset for testing.
+type funcStore func(a Asset) error
+
+func (f funcStore) Put(ctx context.Context, a Asset) error {
+	return f(a)
+}
+
+func TestPublishOneAtATime(t *testing.T) {
+	dir := t.TempDir()
+	var files []StaticFile
+	for _, name := range []string{"a.js", "b.js", "c.js"} {
+		p := filepath.Join(dir, name)
+		err := os.WriteFile(p, []byte("console.log('"+name+"');"), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		files = append(files, NewStaticFile(p, "/static/js/"+name))
+	}
+	c := NewOnDiskConfig(files...)
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Each copy is read just before it is published, not all at once beforehand, so a
+	//copy removed while another is being published is not published.
+	var published []string
+	err = c.Publish(context.Background(), funcStore(func(a Asset) error {
+		if len(published) == 0 {
+			for _, s := range c.StaticFiles {
+				if s.cacheBustURLPath[1:] != a.Key {
+					os.Remove(s.cacheBustLocalPath)
+				}
+			}
+		}
+
+		published = append(published, a.Key)
+		return nil
+	}))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("Removed copy should not have been read before publishing", err)
+		return
+	}
+	if len(published) != 1 {
+		t.Fatal("Only the first copy should have been published", published)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestAzureBlobStore(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	client := &azureClient{uploads: make(map[string]string)}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Blobs are uploaded to the container, under the prefix, with the content type and
+	//cache control.
+	c := NewFSConfig(fsys, "static", "/static")
+	c.PublishCacheControl = "public,max-age=60"
+	c.Store = AzureBlobStore{Client: client, Container: "cdn", Prefix: "assets"}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	s := c.StaticFiles[0]
+	got, ok := client.uploads["cdn/assets"+s.cacheBustURLPath]
+	if !ok || got != "text/javascript; charset=utf-8;public,max-age=60;console.log(1);" {
+		t.Fatal("Blob not uploaded correctly", client.uploads)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+//uploader is an Uploader that records uploads and the most uploads running at once for
+//testing.
+type uploader struct {
+	mu      sync.Mutex
+	running int
+	most    int
+	paths   []string
+}
+
+func (u *uploader) Upload(ctx context.Context, remotePath string, data []byte) error {
+	u.mu.Lock()
+	u.running++
+	if u.running > u.most {
+		u.most = u.running
+	}
+	u.paths = append(u.paths, remotePath)
+	u.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	u.mu.Lock()
+	u.running--
+	u.mu.Unlock()
+	return nil
+}
+
+func TestRemoteStore(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 8; i++ {
+		fsys["static/js/file"+strconv.Itoa(i)+".js"] = &fstest.MapFile{Data: []byte(strconv.Itoa(i))}
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files are uploaded under the root, up to PublishConcurrency at once.
+	u := &uploader{}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.PublishConcurrency = 3
+	c.Store = RemoteStore{Uploader: u, Root: "/var/www/html"}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	if len(u.paths) != 8 || u.most > 3 {
+		t.Fatal("Files not uploaded as expected", len(u.paths), u.most)
+		return
+	}
+	for _, p := range u.paths {
+		if !strings.HasPrefix(p, "/var/www/html/static/js/") {
+			t.Fatal("File not uploaded under root", p)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A relative root is kept relative.
+	u = &uploader{}
+	err = RemoteStore{Uploader: u, Root: "public"}.Put(context.Background(), Asset{Key: "static/js/A1B2C3D4.file0.js"})
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(u.paths) != 1 || u.paths[0] != "public/static/js/A1B2C3D4.file0.js" {
+		t.Fatal("File not uploaded under relative root", u.paths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A dry run lists each file without uploading.
+	var b bytes.Buffer
+	err = c.Publish(context.Background(), &DryRunStore{Writer: &b})
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if strings.Count(b.String(), "\n") != 8 || !strings.Contains(b.String(), c.StaticFiles[0].cacheBustURLPath[1:]+" 1 text/javascript") {
+		t.Fatal("Dry run listing not as expected", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
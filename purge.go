@@ -0,0 +1,228 @@
+package cachebusting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//Purger removes URLs from a CDN's cache. See Config.Purger.
+type Purger interface {
+	//Purge removes each URL path, i.e. /static/js/script.min.js, from the CDN's cache.
+	//The URL paths are as requested by browsers: the BasePath is included and the paths
+	//are percent-encoded.
+	Purge(ctx context.Context, urlPaths []string) error
+}
+
+//ErrPurgeFailed is returned when a CDN responds to a purge request with an error.
+var ErrPurgeFailed = errors.New("cachebusting: purge failed")
+
+//changedURLPaths returns the URL paths to purge for each static file whose hash changed
+//since the prior call to Create(): the original file's URL path and the prior cache
+//busting URL path. Files that were not cache busted prior are ignored since a CDN cannot
+//have cached an outdated version. The URL paths are public URL paths, see publicURLPath.
+//
+//prior is the static files prior to calling Create(). history is the history of cache
+//busting URL paths read from the HistoryFile, if any, and is used for files that weren't
+//cache busted by a prior call to Create() in this process, i.e. after your app restarts.
+func (c *Config) changedURLPaths(prior []StaticFile, history map[string][]string) (urlPaths []string) {
+	priorByURLPath := make(map[string]StaticFile, len(prior))
+	for _, s := range prior {
+		priorByURLPath[s.URLPath] = s
+	}
+
+	seen := make(map[string]bool)
+	add := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urlPaths = append(urlPaths, u)
+		}
+	}
+
+	for _, s := range c.sortedStaticFiles() {
+		var previous string
+		if p, ok := priorByURLPath[s.URLPath]; ok && p.hash != "" {
+			if p.hash == s.hash {
+				continue
+			}
+			previous = p.cacheBustURLPath
+		} else if h := history[s.URLPath]; len(h) > 0 && h[0] != s.cacheBustURLPath {
+			//the first URL path in the history is the cache busting URL path saved by the
+			//prior run of the app, see writeHistoryFile.
+			previous = h[0]
+		} else {
+			continue
+		}
+
+		add(c.publicURLPath(s.URLPath))
+		add(c.publicURLPath(previous))
+	}
+
+	return
+}
+
+//CloudflarePurger purges URLs from Cloudflare's cache using the Cloudflare API.
+type CloudflarePurger struct {
+	//ZoneID is the ID of the zone, i.e. domain, to purge URLs from.
+	ZoneID string
+
+	//APIToken is an API token with the Cache Purge permission for the zone.
+	APIToken string
+
+	//Host is the scheme and host the URL paths are served on, i.e.
+	//https://www.example.com. Cloudflare requires full URLs.
+	Host string
+
+	//Client is the HTTP client used to call the API. If not provided,
+	//http.DefaultClient is used.
+	Client *http.Client
+
+	//APIURL is the base URL of the API. If not provided, the Cloudflare API is used. This
+	//is useful for testing.
+	APIURL string
+}
+
+//cloudflareMaxURLs is the maximum number of URLs Cloudflare allows to be purged per
+//request.
+const cloudflareMaxURLs = 30
+
+//Purge removes the URL paths from Cloudflare's cache.
+func (p CloudflarePurger) Purge(ctx context.Context, urlPaths []string) error {
+	apiURL := p.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.cloudflare.com/client/v4"
+	}
+	endpoint := strings.TrimSuffix(apiURL, "/") + "/zones/" + p.ZoneID + "/purge_cache"
+
+	for start := 0; start < len(urlPaths); start += cloudflareMaxURLs {
+		end := start + cloudflareMaxURLs
+		if end > len(urlPaths) {
+			end = len(urlPaths)
+		}
+
+		files := make([]string, 0, end-start)
+		for _, u := range urlPaths[start:end] {
+			files = append(files, strings.TrimSuffix(p.Host, "/")+u)
+		}
+
+		body, err := json.Marshal(map[string][]string{"files": files})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+p.APIToken)
+		req.Header.Set("Content-Type", "application/json")
+
+		err = doPurgeRequest(p.Client, req)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//FastlyPurger purges URLs from Fastly's cache using the Fastly API.
+type FastlyPurger struct {
+	//APIToken is an API token with the purge_select scope.
+	APIToken string
+
+	//Host is the host the URL paths are served on, i.e. www.example.com.
+	Host string
+
+	//Client is the HTTP client used to call the API. If not provided,
+	//http.DefaultClient is used.
+	Client *http.Client
+
+	//APIURL is the base URL of the API. If not provided, the Fastly API is used. This is
+	//useful for testing.
+	APIURL string
+}
+
+//Purge removes the URL paths from Fastly's cache, one URL per request as required by
+//Fastly.
+func (p FastlyPurger) Purge(ctx context.Context, urlPaths []string) error {
+	apiURL := p.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.fastly.com"
+	}
+
+	for _, u := range urlPaths {
+		endpoint := strings.TrimSuffix(apiURL, "/") + "/purge/" + p.Host + u
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Fastly-Key", p.APIToken)
+
+		err = doPurgeRequest(p.Client, req)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//doPurgeRequest sends a purge request to a CDN's API, returning ErrPurgeFailed if the
+//API does not respond with a 2xx status.
+func doPurgeRequest(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &FileError{Path: req.URL.String(), Err: fmt.Errorf("%w: status %d", ErrPurgeFailed, resp.StatusCode)}
+	}
+
+	return nil
+}
+
+//CloudFrontClient is the subset of an AWS CloudFront client used by CloudFrontPurger.
+//This allows you to use the AWS SDK without this package depending on it. For example,
+//with github.com/aws/aws-sdk-go-v2/service/cloudfront:
+//
+//	type cloudFrontClient struct{ c *cloudfront.Client }
+//
+//	func (c cloudFrontClient) CreateInvalidation(ctx context.Context, distributionID string, paths []string) error {
+//		_, err := c.c.CreateInvalidation(ctx, &cloudfront.CreateInvalidationInput{
+//			DistributionId: aws.String(distributionID),
+//			InvalidationBatch: &types.InvalidationBatch{
+//				CallerReference: aws.String(strconv.FormatInt(time.Now().UnixNano(), 10)),
+//				Paths:           &types.Paths{Items: paths, Quantity: aws.Int32(int32(len(paths)))},
+//			},
+//		})
+//		return err
+//	}
+type CloudFrontClient interface {
+	//CreateInvalidation invalidates the paths in the distribution.
+	CreateInvalidation(ctx context.Context, distributionID string, paths []string) error
+}
+
+//CloudFrontPurger purges URLs from an AWS CloudFront distribution's cache.
+type CloudFrontPurger struct {
+	//Client is the CloudFront client used to create the invalidation.
+	Client CloudFrontClient
+
+	//DistributionID is the ID of the distribution to invalidate paths in.
+	DistributionID string
+}
+
+//Purge invalidates the URL paths in the distribution.
+func (p CloudFrontPurger) Purge(ctx context.Context, urlPaths []string) error {
+	return p.Client.CreateInvalidation(ctx, p.DistributionID, urlPaths)
+}
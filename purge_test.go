@@ -0,0 +1,217 @@
+package cachebusting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/fstest"
+)
+
+//purger is a Purger that records the URL paths purged for testing.
+type purger struct {
+	urlPaths []string
+}
+
+func (p *purger) Purge(ctx context.Context, urlPaths []string) error {
+	p.urlPaths = append(p.urlPaths, urlPaths...)
+	return nil
+}
+
+//cloudFrontClient is a CloudFrontClient that records invalidations for testing.
+type cloudFrontClient struct {
+	distributionID string
+	paths          []string
+}
+
+func (c *cloudFrontClient) CreateInvalidation(ctx context.Context, distributionID string, paths []string) error {
+	c.distributionID = distributionID
+	c.paths = paths
+	return nil
+}
+
+func TestPurge(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Nothing is purged the first time Create() is called.
+	p := &purger{}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.Purger = p
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(p.urlPaths) != 0 {
+		t.Fatal("Nothing should have been purged", p.urlPaths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Only the URLs of changed files are purged.
+	var prior string
+	for _, s := range c.StaticFiles {
+		if s.URLPath == "/static/js/script.min.js" {
+			prior = s.cacheBustURLPath
+		}
+	}
+	fsys["static/js/script.min.js"] = &fstest.MapFile{Data: []byte("console.log(2);")}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(p.urlPaths) != 2 || p.urlPaths[0] != "/static/js/script.min.js" || p.urlPaths[1] != prior {
+		t.Fatal("Changed URLs not purged", p.urlPaths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestPurgeAfterRestart(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/my file.js": {Data: []byte("console.log(1);")},
+	}
+	historyFile := filepath.Join(t.TempDir(), "history.json")
+	newConfig := func(p Purger) *Config {
+		c := NewFSConfig(fsys, "static", "/static")
+		c.BasePath = "/app"
+		c.HistoryLength = 1
+		c.HistoryFile = historyFile
+		c.Purger = p
+		return c
+	}
+
+	c := newConfig(nil)
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	prior := c.StaticFiles[0].cacheBustURLPath
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unchanged files are not purged after a restart.
+	p := &purger{}
+	err = newConfig(p).Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(p.urlPaths) != 0 {
+		t.Fatal("Nothing should have been purged", p.urlPaths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files changed since the app last ran are purged using the URLs browsers request,
+	//with the base path and percent-encoding.
+	fsys["static/js/my file.js"] = &fstest.MapFile{Data: []byte("console.log(2);")}
+	p = &purger{}
+	err = newConfig(p).Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(p.urlPaths) != 2 || p.urlPaths[0] != "/app/static/js/my%20file.js" || p.urlPaths[1] != "/app"+escapeURLPath(prior) {
+		t.Fatal("Changed URLs not purged", p.urlPaths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestCloudflarePurger(t *testing.T) {
+	var requests []map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zones/zone/purge_cache" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body map[string][]string
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+	}))
+	defer srv.Close()
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//URLs are purged in batches using the full URL.
+	var urlPaths []string
+	for i := 0; i < cloudflareMaxURLs+1; i++ {
+		urlPaths = append(urlPaths, "/static/"+strconv.Itoa(i)+".js")
+	}
+	p := CloudflarePurger{ZoneID: "zone", APIToken: "token", Host: "https://www.example.com/", APIURL: srv.URL}
+	err := p.Purge(context.Background(), urlPaths)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(requests) != 2 || len(requests[0]["files"]) != cloudflareMaxURLs || requests[1]["files"][0] != "https://www.example.com/static/30.js" {
+		t.Fatal("URLs not purged as expected", requests)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An error response is returned.
+	p.APIToken = "bad"
+	err = p.Purge(context.Background(), urlPaths)
+	if !errors.Is(err, ErrPurgeFailed) {
+		t.Fatal("Error should have occured")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestFastlyPurger(t *testing.T) {
+	var purged []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Fastly-Key") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		purged = append(purged, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Each URL is purged separately.
+	p := FastlyPurger{APIToken: "token", Host: "www.example.com", APIURL: srv.URL}
+	err := p.Purge(context.Background(), []string{"/static/a.js", "/static/b.js"})
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if len(purged) != 2 || purged[0] != "/purge/www.example.com/static/a.js" {
+		t.Fatal("URLs not purged as expected", purged)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestCloudFrontPurger(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The URL paths are invalidated in the distribution.
+	client := &cloudFrontClient{}
+	p := CloudFrontPurger{Client: client, DistributionID: "dist"}
+	err := p.Purge(context.Background(), []string{"/static/a.js"})
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if client.distributionID != "dist" || len(client.paths) != 1 || client.paths[0] != "/static/a.js" {
+		t.Fatal("Paths not invalidated as expected", client)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
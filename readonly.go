@@ -0,0 +1,17 @@
+package cachebusting
+
+import (
+	"errors"
+	"io/fs"
+)
+
+//ErrReadOnly is returned when a cache busting copy cannot be saved to disk because the
+//filesystem is read-only or the app doesn't have permission to write to the directory.
+//Set UseMemory to store copies in memory, or MemoryFallback to do so only when needed.
+var ErrReadOnly = errors.New("cachebusting: cannot write cache busting copy to disk, set UseMemory or MemoryFallback")
+
+//isReadOnlyErr returns true if an error from writing a file was caused by the filesystem
+//being read-only or lacking permission to write.
+func isReadOnlyErr(err error) bool {
+	return isReadOnlyFilesystemErr(err) || errors.Is(err, fs.ErrPermission)
+}
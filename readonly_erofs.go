@@ -0,0 +1,14 @@
+//go:build !plan9
+
+package cachebusting
+
+import (
+	"errors"
+	"syscall"
+)
+
+//isReadOnlyFilesystemErr returns true if an error was caused by the filesystem being
+//read-only.
+func isReadOnlyFilesystemErr(err error) bool {
+	return errors.Is(err, syscall.EROFS)
+}
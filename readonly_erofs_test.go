@@ -0,0 +1,18 @@
+//go:build !plan9
+
+package cachebusting
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestIsReadOnlyFilesystemErr(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	if !isReadOnlyErr(&fs.PathError{Op: "open", Path: "a.js", Err: syscall.EROFS}) {
+		t.Fatal("Read-only filesystem error not detected")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
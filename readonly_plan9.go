@@ -0,0 +1,10 @@
+//go:build plan9
+
+package cachebusting
+
+//isReadOnlyFilesystemErr returns true if an error was caused by the filesystem being
+//read-only. Plan 9 has no EROFS error, writing to a read-only filesystem returns a
+//permission error instead.
+func isReadOnlyFilesystemErr(err error) bool {
+	return false
+}
@@ -0,0 +1,70 @@
+package cachebusting
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsReadOnlyErr(t *testing.T) {
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	if !isReadOnlyErr(&fs.PathError{Op: "open", Path: "a.js", Err: fs.ErrPermission}) {
+		t.Fatal("Permission error not detected")
+		return
+	}
+	if isReadOnlyErr(&fs.PathError{Op: "open", Path: "a.js", Err: fs.ErrNotExist}) {
+		t.Fatal("Other error should not have been detected")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestMemoryFallback(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "script.min.js")
+	err := os.WriteFile(p, []byte("console.log(1);"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	err = os.Chmod(dir, 0555)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.Chmod(dir, 0755)
+
+	//permissions aren't enforced for root.
+	probe := filepath.Join(dir, "probe")
+	if os.WriteFile(probe, nil, 0644) == nil {
+		os.Remove(probe)
+		t.Skip("directory is writable despite permissions")
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//ErrReadOnly is returned when the copy cannot be written.
+	c := NewOnDiskConfig(NewStaticFile(p, "/static/js/script.min.js"))
+	err = c.Create()
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatal("ErrReadOnly should have been returned", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The copy is stored in memory instead with MemoryFallback.
+	c.MemoryFallback = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if s := c.StaticFiles[0]; string(s.fileData) != "console.log(1);" || !c.inMemory(s) {
+		t.Fatal("Copy should have been stored in memory")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,78 @@
+package cachebusting
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+)
+
+//Uploader saves a file to a remote server, for example over SFTP to the document root of
+//an nginx server. This allows you to use whichever SFTP, or other, client library you
+//already use without this package depending on it. For example, with
+//github.com/pkg/sftp:
+//
+//	type sftpUploader struct{ c *sftp.Client }
+//
+//	func (u sftpUploader) Upload(ctx context.Context, remotePath string, data []byte) error {
+//		err := u.c.MkdirAll(path.Dir(remotePath))
+//		if err != nil {
+//			return err
+//		}
+//
+//		f, err := u.c.Create(remotePath)
+//		if err != nil {
+//			return err
+//		}
+//
+//		_, err = f.Write(data)
+//		if err != nil {
+//			f.Close()
+//			return err
+//		}
+//		return f.Close()
+//	}
+type Uploader interface {
+	//Upload saves data to the remote path, creating any missing directories and
+	//overwriting the file if it exists. Upload may be called from multiple goroutines at
+	//once, see PublishConcurrency.
+	Upload(ctx context.Context, remotePath string, data []byte) error
+}
+
+//RemoteStore is an AssetStore that publishes cache busting copies to a remote server
+//using an Uploader.
+type RemoteStore struct {
+	//Uploader is used to upload each file.
+	Uploader Uploader
+
+	//Root is the directory on the remote server files are uploaded to, i.e. the document
+	//root, /var/www/html. Each file is uploaded to its cache busting URL path under Root.
+	//A relative Root, or no Root, is relative to the Uploader's working directory, i.e.
+	//the SFTP user's home directory.
+	Root string
+}
+
+//Put uploads an asset to the remote server.
+func (r RemoteStore) Put(ctx context.Context, a Asset) error {
+	return r.Uploader.Upload(ctx, path.Join(r.Root, a.Key), a.Data)
+}
+
+//DryRunStore is an AssetStore that lists the assets that would be published, rather than
+//publishing them. Use this to check what Create() or Publish() would upload prior to
+//using a real store.
+type DryRunStore struct {
+	//Writer is where each asset is listed, one per line.
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+//Put lists an asset.
+func (d *DryRunStore) Put(ctx context.Context, a Asset) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, err := fmt.Fprintln(d.Writer, a.Key, len(a.Data), a.ContentType, a.CacheControl)
+	return err
+}
@@ -0,0 +1,78 @@
+package cachebusting
+
+import "time"
+
+//Report is a summary of the last successful call to Create(). Use this for startup logs or
+//an admin page. See LastReport().
+type Report struct {
+	//Started is when Create() was called.
+	Started time.Time
+
+	//Duration is how long Create() took.
+	Duration time.Duration
+
+	//BytesRead is the total size of the files read.
+	BytesRead int64
+
+	//Files is the details of each file, in the same order as StaticFiles.
+	Files []ReportFile
+}
+
+//ReportFile is the details of a single file in a Report.
+type ReportFile struct {
+	CreatedFile
+
+	//Location is where the copy is served from: memory, disk, streamed, or vendor.
+	Location string
+
+	//Duration is how long it took to read and hash the file.
+	Duration time.Duration
+}
+
+//newReport builds the report of a call to Create() from the files created.
+//
+//durations is how long each file took to read and hash, in the same order as
+//StaticFiles.
+func (c *Config) newReport(started time.Time, files []CreatedFile, durations []time.Duration) (r Report) {
+	r.Started = started
+	r.Files = make([]ReportFile, 0, len(files))
+
+	for k, f := range files {
+		rf := ReportFile{
+			CreatedFile: f,
+			Location:    "disk",
+			Duration:    durations[k],
+		}
+		switch {
+		case f.Vendor:
+			rf.Location = "vendor"
+		case c.StaticFiles[k].streamed:
+			rf.Location = "streamed"
+		case f.InMemory:
+			rf.Location = "memory"
+		}
+
+		r.BytesRead += f.Size
+		r.Files = append(r.Files, rf)
+	}
+
+	r.Duration = time.Since(started)
+	return
+}
+
+//LastReport returns the summary of the last successful call to Create(). A Report with no
+//files is returned if Create() hasn't succeeded yet. This can be called while Create() is
+//running, the report of the prior call to Create() is returned.
+func (c *Config) LastReport() Report {
+	r, _ := c.report.Load().(Report)
+	return r
+}
+
+//LastReport returns the summary of the last successful call to Create() for the package
+//level config.
+func LastReport() Report {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return config.LastReport()
+}
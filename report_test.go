@@ -0,0 +1,59 @@
+package cachebusting
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLastReport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js":   {Data: []byte("console.log(1);")},
+		"static/css/styles.min.css": {Data: []byte("body{}")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//No report is available prior to Create().
+	if r := c.LastReport(); len(r.Files) != 0 || !r.Started.IsZero() {
+		t.Fatal("Report should be empty", r)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The report lists each file created.
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	r := c.LastReport()
+	if len(r.Files) != 2 || r.BytesRead != 21 || r.Started.IsZero() || r.Duration <= 0 {
+		t.Fatal("Report not as expected", r)
+		return
+	}
+	for k, f := range r.Files {
+		s := c.StaticFiles[k]
+		if f.URLPath != s.URLPath || f.CacheBustURLPath != s.cacheBustURLPath || f.Hash != s.hash || f.Location != "memory" {
+			t.Fatal("Report file not as expected", f)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A failed Create() keeps the prior report.
+	fsys["static/js/script.min.js"] = &fstest.MapFile{Data: []byte("console.log(2);")}
+	c.AfterCreate = func([]CreatedFile) error { return ErrNotFound }
+	err = c.Create()
+	if err == nil {
+		t.Fatal("Error should have occured")
+		return
+	}
+	if c.LastReport().Started != r.Started {
+		t.Fatal("Prior report should have been kept")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,90 @@
+package cachebusting
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//RewriteTemplates copies each file in srcDir to dstDir, replacing each reference to a
+//static file's URL path in templates and HTML files with the URL of the cache busting
+//copy. This is useful for static site exports, or serving HTML without html/template,
+//where looking up the cache busting URL when the page is rendered isn't possible. Files
+//that aren't templates are copied as-is. Create() must be called first.
+//
+//Ex.: href="/static/css/styles.min.css" becomes href="/static/css/A1B2C3D4.styles.min.css".
+func (c *Config) RewriteTemplates(srcDir, dstDir string) error {
+	replace := c.rewriteFunc()
+
+	return filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dstDir, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(dst, 0755)
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		if templateExtensions[strings.ToLower(filepath.Ext(p))] {
+			b = []byte(replace(string(b)))
+		}
+
+		return os.WriteFile(dst, b, 0644)
+	})
+}
+
+//rewriteFunc returns a func that replaces each static file's URL path in a string with
+//the URL of the cache busting copy. URL paths are only replaced when the entire path
+//matches, so /static/js/script.js is not replaced within /static/js/script.js.map.
+func (c *Config) rewriteFunc() func(string) string {
+	urls := make(map[string]string, len(c.StaticFiles))
+	urlPaths := make([]string, 0, len(c.StaticFiles))
+	for _, s := range c.StaticFiles {
+		if s.cacheBustURLPath == "" {
+			continue
+		}
+
+		urls[s.URLPath] = c.urlFor(s)
+		urlPaths = append(urlPaths, s.URLPath)
+
+		//match the percent-encoded URL path as well, i.e. for names with spaces.
+		if e := escapeURLPath(s.URLPath); e != s.URLPath {
+			urls[e] = c.urlFor(s)
+			urlPaths = append(urlPaths, e)
+		}
+	}
+
+	if len(urlPaths) == 0 {
+		return func(s string) string { return s }
+	}
+
+	//match longest paths first so a path that is a prefix of another doesn't match first.
+	sort.Slice(urlPaths, func(i, j int) bool {
+		return len(urlPaths[i]) > len(urlPaths[j])
+	})
+	for k, u := range urlPaths {
+		urlPaths[k] = regexp.QuoteMeta(u)
+	}
+	re := regexp.MustCompile(`(` + strings.Join(urlPaths, "|") + `)([^A-Za-z0-9._~/@-]|$)`)
+
+	return func(s string) string {
+		return re.ReplaceAllStringFunc(s, func(m string) string {
+			sub := re.FindStringSubmatch(m)
+			return urls[sub[1]] + sub[2]
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package cachebusting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRewriteTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "out")
+	html := `<script src="/static/js/script.js"></script><a href="/static/js/script.js.map">map</a>`
+	err = os.MkdirAll(filepath.Join(src, "pages"), 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(src, "pages", "index.html"), []byte(html), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(src, "robots.txt"), []byte("/static/js/script.js"), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Templates are rewritten, other files are copied as-is.
+	err = c.RewriteTemplates(src, dst)
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	b, err := os.ReadFile(filepath.Join(dst, "pages", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := `<script src="` + c.StaticFiles[0].cacheBustURLPath + `"></script><a href="/static/js/script.js.map">map</a>`
+	if string(b) != expected {
+		t.Fatal("Template not rewritten as expected", string(b))
+		return
+	}
+
+	b, err = os.ReadFile(filepath.Join(dst, "robots.txt"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if string(b) != "/static/js/script.js" {
+		t.Fatal("Non-template file should not have been rewritten", string(b))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
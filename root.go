@@ -0,0 +1,78 @@
+package cachebusting
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//iconTags is the list of well known icon and manifest filenames and the element used to
+//reference each file from the <head> of your HTML. The order is the order the elements
+//are output in. The "%s" is replaced with the file's URL.
+var iconTags = []struct {
+	name string
+	tag  string
+}{
+	{"favicon.ico", `<link rel="icon" href="%s" sizes="any">`},
+	{"favicon.svg", `<link rel="icon" href="%s" type="image/svg+xml">`},
+	{"favicon.png", `<link rel="icon" href="%s" type="image/png">`},
+	{"apple-touch-icon.png", `<link rel="apple-touch-icon" href="%s">`},
+	{"manifest.webmanifest", `<link rel="manifest" href="%s">`},
+	{"site.webmanifest", `<link rel="manifest" href="%s">`},
+}
+
+//FaviconTags returns the <link> elements for the favicon, Apple touch icon, and web app
+//manifest static files, using the cache busting URL of each file. Files are matched by
+//their well known names (favicon.ico, favicon.svg, favicon.png, apple-touch-icon.png,
+//manifest.webmanifest, and site.webmanifest). Place the returned elements in the <head>
+//of your HTML. Since browsers request /favicon.ico if no icon is referenced, these files
+//are typically served from the root of your domain, see RootHandler().
+func (c *Config) FaviconTags() template.HTML {
+	var b strings.Builder
+	for _, t := range iconTags {
+		s, found := c.findByOriginalName(t.name)
+		if !found {
+			continue
+		}
+
+		b.WriteString(strings.Replace(t.tag, "%s", template.HTMLEscapeString(c.urlFor(s)), 1))
+		b.WriteString("\n")
+	}
+
+	return template.HTML(b.String())
+}
+
+//FaviconTags returns the favicon elements for the package level config.
+func FaviconTags() template.HTML {
+	return config.FaviconTags()
+}
+
+//RootHandler serves the cache busting copies of static files served from the root of your
+//domain, i.e. a static file with the URL path /favicon.ico is served on
+///A1B2C3D4.favicon.ico. All other requests are passed to next. This allows root level
+//files to be cache busted without routing every request through the static file handler.
+//
+//Files that must keep their name, such as /robots.txt, should be marked as Vendor so they
+//are served as-is on their original URL path.
+func (c *Config) RootHandler(cacheDays int, next http.Handler) http.Handler {
+	h := c.Handler(HandlerOptions{CacheDays: cacheDays})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cleaned, ok := cleanRequestPath(r.URL.Path)
+		cleaned = c.trimBasePath(cleaned)
+		if ok && !c.Development && path.Dir(cleaned) == "/" {
+			if _, _, found := c.findByCacheBustURLPath(cleaned); found {
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+//DefaultRootHandler returns the root level file handler for the package level config.
+func DefaultRootHandler(cacheDays int, next http.Handler) http.Handler {
+	return config.RootHandler(cacheDays, next)
+}
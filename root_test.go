@@ -0,0 +1,98 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFaviconTags(t *testing.T) {
+	fsys := fstest.MapFS{
+		"favicon.ico":          {Data: []byte("ico")},
+		"favicon.svg":          {Data: []byte("<svg></svg>")},
+		"manifest.webmanifest": {Data: []byte("{}")},
+	}
+	c := NewFSConfig(fsys, ".", "/")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//An element is returned for each icon and manifest file, in order.
+	tags := string(c.FaviconTags())
+	ico, _ := c.findByOriginalName("favicon.ico")
+	svg, _ := c.findByOriginalName("favicon.svg")
+	manifest, _ := c.findByOriginalName("manifest.webmanifest")
+	expected := `<link rel="icon" href="` + ico.cacheBustURLPath + `" sizes="any">` + "\n" +
+		`<link rel="icon" href="` + svg.cacheBustURLPath + `" type="image/svg+xml">` + "\n" +
+		`<link rel="manifest" href="` + manifest.cacheBustURLPath + `">` + "\n"
+	if tags != expected {
+		t.Fatal("Tags not as expected", tags)
+		return
+	}
+	if !strings.HasPrefix(ico.cacheBustURLPath, "/") || strings.Count(ico.cacheBustURLPath, "/") != 1 {
+		t.Fatal("Root level file should have a root level cache busting URL path", ico.cacheBustURLPath)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestRootHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"favicon.ico": {Data: []byte("ico")},
+		"robots.txt":  {Data: []byte("User-agent: *")},
+	}
+	c := NewFSConfig(fsys, ".", "/")
+	for k, s := range c.StaticFiles {
+		if s.URLPath == "/robots.txt" {
+			c.StaticFiles[k].Vendor = true
+		}
+	}
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("next"))
+	})
+	h := c.RootHandler(1, next)
+	ico, _ := c.findByOriginalName("favicon.ico")
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Root level cache busting files are served.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ico.cacheBustURLPath, nil))
+	if rec.Body.String() != "ico" || rec.Header().Get("Cache-Control") == "" {
+		t.Fatal("Root level file not served", rec.Code, rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Vendor files are served on their original URL path.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/robots.txt", nil))
+	if rec.Body.String() != "User-agent: *" {
+		t.Fatal("Vendor root level file not served", rec.Code, rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Other requests are passed to the next handler.
+	for _, p := range []string{"/", "/about", "/static" + ico.cacheBustURLPath} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		if rec.Body.String() != "next" {
+			t.Fatal("Request should have been passed to next handler", p, rec.Body.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
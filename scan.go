@@ -0,0 +1,139 @@
+package cachebusting
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//templateExtensions is the list of file extensions treated as templates when scanning a
+//directory for referenced static files.
+var templateExtensions = map[string]bool{
+	".html":   true,
+	".htm":    true,
+	".tmpl":   true,
+	".gohtml": true,
+}
+
+//templateRefPrefix returns the URL path prefix, cleaned and ending in "/", that references
+//to static files in templates start with, i.e.: /static/.
+func templateRefPrefix(urlPrefix string) string {
+	urlPrefix = path.Clean(path.Join("/", urlPrefix))
+	if urlPrefix != "/" {
+		urlPrefix += "/"
+	}
+
+	return urlPrefix
+}
+
+//templateRefRegex returns the regex matching references in templates to URL paths that
+//start with prefix, see templateRefPrefix. URL paths end at a quote, whitespace, template
+//action, query string, or fragment.
+func templateRefRegex(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(prefix) + `[^"'\s{}()<>?#]+`)
+}
+
+//walkTemplates calls fn with the path and contents of each template in dir, see
+//templateExtensions.
+func walkTemplates(dir string, fn func(p, content string) error) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !templateExtensions[strings.ToLower(filepath.Ext(p))] {
+			return nil
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		return fn(p, string(b))
+	})
+}
+
+//ScanResult is the list of static files referenced in a directory of templates.
+type ScanResult struct {
+	//Files is the list of referenced static files that exist on disk.
+	Files []StaticFile
+
+	//Missing is the list of referenced URL paths for which a file does not exist on disk.
+	//This usually means a template references a file that was renamed or removed.
+	Missing []string
+}
+
+//ScanTemplates finds the static files referenced in the templates in templateDir, for
+//example in src or href attributes, so that the list of static files you cache bust does
+//not drift from what your templates actually use. References are found by looking for URL
+//paths starting with urlPrefix, i.e.: /static/. Each referenced URL path is matched to a
+//file in staticDir, the directory served at urlPrefix.
+//
+//References built dynamically in a template, i.e.: "/static/{{.Name}}", cannot be found.
+func ScanTemplates(templateDir, urlPrefix, staticDir string) (r ScanResult, err error) {
+	urlPrefix = templateRefPrefix(urlPrefix)
+	refRegex := templateRefRegex(urlPrefix)
+
+	refs := make(map[string]bool)
+	err = walkTemplates(templateDir, func(p, content string) error {
+		for _, ref := range refRegex.FindAllString(content, -1) {
+			//skip directories and references built dynamically.
+			if strings.HasSuffix(ref, "/") {
+				continue
+			}
+
+			refs[path.Clean(ref)] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	urlPaths := make([]string, 0, len(refs))
+	for u := range refs {
+		urlPaths = append(urlPaths, u)
+	}
+	sort.Strings(urlPaths)
+
+	for _, u := range urlPaths {
+		localPath := filepath.Join(staticDir, filepath.FromSlash(strings.TrimPrefix(u, urlPrefix)))
+
+		info, statErr := os.Stat(localPath)
+		if errors.Is(statErr, os.ErrNotExist) || (statErr == nil && info.IsDir()) {
+			r.Missing = append(r.Missing, u)
+			continue
+		} else if statErr != nil {
+			err = statErr
+			return
+		}
+
+		r.Files = append(r.Files, NewStaticFile(localPath, u))
+	}
+
+	return
+}
+
+//AddScanned adds the static files found by ScanTemplates() to the config. Files that are
+//already in the config, matched by URL path, are not added again.
+func (c *Config) AddScanned(r ScanResult) {
+	existing := make(map[string]bool, len(c.StaticFiles))
+	for _, s := range c.StaticFiles {
+		existing[path.Clean(path.Join("/", filepath.ToSlash(s.URLPath)))] = true
+	}
+
+	for _, s := range r.Files {
+		if existing[s.URLPath] {
+			continue
+		}
+
+		c.StaticFiles = append(c.StaticFiles, s)
+		existing[s.URLPath] = true
+	}
+}
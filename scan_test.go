@@ -0,0 +1,61 @@
+package cachebusting
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanTemplates(t *testing.T) {
+	dir := t.TempDir()
+	templateDir := filepath.Join(dir, "templates")
+	err := os.MkdirAll(templateDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	tmpl := `<link rel="stylesheet" href="/static/css/styles.min.css">
+<script src='/static/js/script.min.js?v=1'></script>
+<img src="/static/img/missing.png">
+<img src="/static/img/{{.Name}}">
+<a href="/about">About</a>`
+	err = os.WriteFile(filepath.Join(templateDir, "index.html"), []byte(tmpl), 0644)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Referenced files are found and missing files are flagged.
+	r, err := ScanTemplates(templateDir, "/static", filepath.Join("_testdata", "static"))
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	var urlPaths []string
+	for _, s := range r.Files {
+		urlPaths = append(urlPaths, s.URLPath)
+	}
+	if !reflect.DeepEqual(urlPaths, []string{"/static/css/styles.min.css", "/static/js/script.min.js"}) {
+		t.Fatal("Referenced files not found as expected", urlPaths)
+		return
+	}
+	if !reflect.DeepEqual(r.Missing, []string{"/static/img/missing.png"}) {
+		t.Fatal("Missing files not flagged as expected", r.Missing)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Scanned files are added to a config without duplicates.
+	c := NewOnDiskConfig(r.Files[0])
+	c.AddScanned(r)
+	if len(c.StaticFiles) != 2 {
+		t.Fatal("Scanned files not added as expected", c.StaticFiles)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
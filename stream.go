@@ -0,0 +1,128 @@
+package cachebusting
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//sourceFS returns the filesystem original files are read from. Nil is returned if the
+//original files are stored on disk.
+func (c *Config) sourceFS() fs.FS {
+	if c.UseEmbedded {
+		return c.EmbeddedFS
+	}
+
+	return c.FS
+}
+
+//modifies returns true if the file's data is changed from the original file's data when
+//read, see Normalize, BuildInfo, ManifestPlaceholder, and WebAppManifest. Modified files cannot be
+//streamed since the copy doesn't match the original file.
+func (c *Config) modifies(s StaticFile) bool {
+	if s.sourceData != nil || s.ManifestPlaceholder != "" {
+		return true
+	}
+	if s.Vendor {
+		return false
+	}
+	if isWebAppManifest(s) {
+		return true
+	}
+	if (c.RegisterFonts || c.RewriteCSSURLs) && isCSS(s) {
+		return true
+	}
+
+	ext := strings.ToLower(path.Ext(filepath.ToSlash(s.LocalPath)))
+	if c.Normalize && (ext == ".gz" || textExtensions[ext]) {
+		return true
+	}
+
+	if c.BuildInfo != "" {
+		extensions := c.BuildInfoExtensions
+		if len(extensions) == 0 {
+			extensions = defaultBuildInfoExtensions
+		}
+		if _, ok := commentSyntax[ext]; ok && containsString(extensions, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//streamHash calculates the hash of a file read from a filesystem by streaming the file,
+//rather than reading the entire file into memory, if the file is at least StreamMinBytes
+//in size or ServeFromEmbedded is true. False is returned if the file should be read into
+//memory instead.
+func (c *Config) streamHash(s StaticFile) (h [sha256.Size]byte, streamed bool, err error) {
+	direct := c.ServeFromEmbedded && c.UseEmbedded
+	if (c.StreamMinBytes <= 0 && !direct) || !c.usesFS() || c.modifies(s) {
+		return
+	}
+
+	release := acquireFile()
+	defer release()
+
+	f, err := c.sourceFS().Open(filepath.ToSlash(s.LocalPath))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	if !direct && info.Size() < c.StreamMinBytes {
+		return
+	}
+
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, f)
+	if err != nil {
+		return
+	}
+
+	copy(h[:], hasher.Sum(nil))
+	return h, true, nil
+}
+
+//openStreamed opens a streamed file from the filesystem for serving. Files from an
+//embed.FS support seeking. The data of files from filesystems that do not support
+//seeking is read into memory for just this request.
+func (c *Config) openStreamed(s StaticFile) (content io.ReadSeeker, closeFunc func() error, err error) {
+	f, err := c.sourceFS().Open(filepath.ToSlash(s.LocalPath))
+	if err != nil {
+		return
+	}
+
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, f.Close, nil
+	}
+
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return
+	}
+
+	return bytes.NewReader(b), func() error { return nil }, nil
+}
+
+//copyData returns the data of a static file's cache busting copy stored in memory, reading
+//the original file for streamed files and files removed from the memory cache.
+func (c *Config) copyData(s StaticFile) ([]byte, error) {
+	if s.evicted {
+		return c.cachedData(s)
+	}
+	if !s.streamed {
+		return s.fileData, nil
+	}
+
+	return c.readFunc()(filepath.ToSlash(s.LocalPath))
+}
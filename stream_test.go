@@ -0,0 +1,139 @@
+package cachebusting
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStreamMinBytes(t *testing.T) {
+	data := []byte("0123456789")
+	fsys := fstest.MapFS{
+		"static/video/intro.js": {Data: data},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.StreamMinBytes = 1
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Large files are hashed by streaming and not copied into memory.
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	s := c.StaticFiles[0]
+	if !s.streamed || s.fileData != nil {
+		t.Fatal("File should have been streamed", s.streamed, len(s.fileData))
+		return
+	}
+	if s.hash != upperHex(sha256.Sum256(data)) {
+		t.Fatal("Streamed hash does not match", s.hash)
+		return
+	}
+	if st := c.Stats(); st.Streamed != 1 || st.InMemory != 0 || st.MemoryBytes != 0 {
+		t.Fatal("Stats not as expected", st)
+		return
+	}
+
+	b, err := c.FindFileDataByCacheBustURLPath(s.cacheBustURLPath)
+	if err != nil || string(b) != string(data) {
+		t.Fatal("Streamed file data not returned", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Streamed files are served from the filesystem, including ranges.
+	h := c.Handler(HandlerOptions{})
+	req := httptest.NewRequest(http.MethodGet, s.cacheBustURLPath, nil)
+	req.Header.Set("Range", "bytes=0-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != string(data[:2]) || rec.Header().Get("X-Static-Served-From") != "fs" {
+		t.Fatal("Streamed file not served as expected", rec.Code, rec.Header(), rec.Body.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files smaller than StreamMinBytes are copied into memory.
+	c.StreamMinBytes = int64(len(data)) + 1
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if c.StaticFiles[0].streamed || string(c.StaticFiles[0].fileData) != string(data) {
+		t.Fatal("File should have been copied into memory")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files modified when read are never streamed.
+	c.StreamMinBytes = 1
+	c.BuildInfo = "v1.0.0"
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if c.StaticFiles[0].streamed {
+		t.Fatal("Modified file should not have been streamed")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestServeFromEmbedded(t *testing.T) {
+	js := NewStaticFile("_testdata/static/js/script.min.js", "/static/js/script.min.js")
+	c := NewEmbeddedConfig(embeddedFiles, js)
+	c.ServeFromEmbedded = true
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Embedded files are not copied into memory, regardless of size.
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	s := c.StaticFiles[0]
+	if !s.streamed || s.fileData != nil || s.cacheBustURLPath == "" {
+		t.Fatal("File should have been served from embedded files", s.streamed, s.cacheBustURLPath)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The file is served from the embedded files on its cache busting URL path.
+	h := c.Handler(HandlerOptions{})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, s.cacheBustURLPath, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Static-Served-From") != "embedded" || rec.Header().Get("ETag") != `"`+s.hash+`"` {
+		t.Fatal("File not served from embedded files", rec.Code, rec.Header())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files read from other filesystems are still copied into memory.
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	fc := NewFSConfig(fsys, "static", "/static")
+	fc.ServeFromEmbedded = true
+	err = fc.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if fc.StaticFiles[0].streamed {
+		t.Fatal("File from non-embedded filesystem should not have been streamed")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
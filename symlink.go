@@ -0,0 +1,69 @@
+package cachebusting
+
+import (
+	"io/fs"
+	"os"
+)
+
+//walkFiles calls fn for each file in the root directory, and nested directories, of a
+//filesystem. Unlike fs.WalkDir, symlinks to directories are followed so that a static
+//directory can link to shared assets stored elsewhere. Each file's path is the path via
+//the symlink, not the path of the symlink's target. A symlink to a directory that was
+//already walked, i.e. a link to a parent directory, is skipped so that walking a loop of
+//symlinks ends. Mapping files, see MappingFile, are skipped.
+//
+//Loops can only be detected for filesystems that return the same fs.FileInfo as the os
+//package, i.e. os.DirFS, since other filesystems don't support symlinks.
+func walkFiles(fsys fs.FS, root string, fn func(p string) error) error {
+	var walked []fs.FileInfo
+	seen := func(info fs.FileInfo) bool {
+		for _, w := range walked {
+			if os.SameFile(w, info) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		return fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				info, err := fs.Stat(fsys, p)
+				if err != nil {
+					return err
+				}
+				if p != dir && seen(info) {
+					return fs.SkipDir
+				}
+				walked = append(walked, info)
+				return nil
+			}
+
+			if d.Type()&fs.ModeSymlink != 0 {
+				//the target of the symlink, a broken link is returned as an error when
+				//the file is read.
+				info, err := fs.Stat(fsys, p)
+				if err == nil && info.IsDir() {
+					if seen(info) {
+						return nil
+					}
+					return walk(p)
+				}
+			}
+
+			//the mapping file isn't a static file, see MappingFile.
+			if d.Name() == mappingFilename {
+				return nil
+			}
+
+			return fn(p)
+		})
+	}
+
+	return walk(root)
+}
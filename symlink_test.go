@@ -0,0 +1,87 @@
+package cachebusting
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	write := func(p, data string) {
+		err := os.MkdirAll(filepath.Dir(p), 0755)
+		if err == nil {
+			err = os.WriteFile(p, []byte(data), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := func(target, p string) {
+		err := os.Symlink(target, p)
+		if err != nil {
+			t.Skip("symlinks not supported", err)
+		}
+	}
+
+	write(filepath.Join(dir, "static", "js", "script.min.js"), "console.log(1);")
+	write(filepath.Join(dir, "shared", "css", "styles.min.css"), "body{}")
+	link(filepath.Join(dir, "shared"), filepath.Join(dir, "static", "shared"))
+	link(filepath.Join(dir, "static"), filepath.Join(dir, "static", "js", "loop"))
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Symlinked directories are followed, loops are not.
+	c := NewFSConfig(os.DirFS(dir), "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	var urlPaths []string
+	for _, s := range c.StaticFiles {
+		urlPaths = append(urlPaths, s.URLPath)
+	}
+	sort.Strings(urlPaths)
+	if len(urlPaths) != 2 || urlPaths[0] != "/static/js/script.min.js" || urlPaths[1] != "/static/shared/css/styles.min.css" {
+		t.Fatal("Files not found as expected", urlPaths)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Chunks in symlinked directories are found.
+	c = NewOnDiskConfig()
+	err = c.AddChunks(filepath.Join(dir, "static"), "/static")
+	if err != nil || len(c.StaticFiles) != 2 {
+		t.Fatal("Chunks not found as expected", err, len(c.StaticFiles))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A symlink named like an old copy is never removed or followed.
+	outside := filepath.Join(dir, "outside.js")
+	write(outside, "outside")
+	named := filepath.Join(dir, "static", "js", "ABCDEF01.script.min.js")
+	link(outside, named)
+
+	original := filepath.Join(dir, "static", "js", "script.min.js")
+	c = NewOnDiskConfig(NewStaticFile(original, "/static/js/script.min.js"))
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	if _, err := os.Lstat(named); err != nil {
+		t.Fatal("Symlink should not have been removed", err)
+		return
+	}
+	if b, err := os.ReadFile(outside); err != nil || string(b) != "outside" {
+		t.Fatal("Symlink target should not have been changed", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
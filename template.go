@@ -0,0 +1,351 @@
+package cachebusting
+
+import (
+	"html/template"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//FuncMap returns functions for use in html/template templates. Add the returned funcs to
+//your templates via template.New("").Funcs(c.FuncMap()) prior to parsing your templates.
+//
+//Functions:
+// - cacheBustURL: returns the URL to the cache busting copy of a file given the original
+//   file's name. Ex.: {{cacheBustURL "styles.min.css"}}.
+// - assetURL: same as cacheBustURL but always returns the original file's URL path when
+//   Development is true. See AssetURL().
+// - scriptTag: returns a complete <script> element for a file. See ScriptTag().
+// - styleTag: returns a complete <link rel="stylesheet"> element for a file. See StyleTag().
+// - preloadTags: returns <link rel="preload"> elements for Critical files. See PreloadTags().
+// - pictureTag: returns a <picture> element for an image and its variants. See PictureTag().
+// - srcset: returns the srcset attribute value for an image and its resolution variants. See
+//   SrcSet().
+// - faviconTags: returns <link> elements for the favicon and web app manifest. See
+//   FaviconTags().
+// - modulePreloadTags: returns <link rel="modulepreload"> elements for an entry module and
+//   its imports. See ModulePreloadTags().
+// - groupTags: returns the <script> and <link rel="stylesheet"> elements for a group of
+//   files. See GroupTags().
+// - spriteURL: same as assetURL but keeps the fragment of an icon in an SVG sprite. See
+//   SpriteURL().
+func (c *Config) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"cacheBustURL":      c.originalOrCacheBustURL,
+		"assetURL":          c.AssetURL,
+		"scriptTag":         c.ScriptTag,
+		"styleTag":          c.StyleTag,
+		"preloadTags":       c.PreloadTags,
+		"pictureTag":        c.PictureTag,
+		"srcset":            c.SrcSet,
+		"faviconTags":       c.FaviconTags,
+		"modulePreloadTags": c.ModulePreloadTags,
+		"groupTags":         c.GroupTags,
+		"spriteURL":         c.SpriteURL,
+	}
+}
+
+//FuncMap returns the template funcs for the package level config.
+func FuncMap() template.FuncMap {
+	return config.FuncMap()
+}
+
+//findByOriginalName looks up a static file by the original file's name. This matches
+//the keys returned by GetFilenamePairs.
+func (c *Config) findByOriginalName(original string) (s StaticFile, found bool) {
+	//use the lookup built by Create(), the same snapshot requests are served from, so
+	//that templates never read StaticFiles while Create() is modifying it.
+	if l := c.currentLookup(); l != nil {
+		k, ok := l.nameIndex[original]
+		if !ok {
+			return
+		}
+
+		return l.files[k], true
+	}
+
+	for _, v := range c.StaticFiles {
+		if filepath.Base(v.LocalPath) == original {
+			return v, true
+		}
+	}
+
+	return
+}
+
+//originalOrCacheBustURL returns the cache busting URL for a file given the original file's
+//name. If cache busting files have not been created, the original file's URL path is
+//returned instead. If the file isn't known at all, the provided name is returned as-is
+//so that the template still renders something diagnosable.
+func (c *Config) originalOrCacheBustURL(original string) string {
+	s, found := c.findByOriginalName(original)
+	if !found {
+		return original
+	}
+
+	return c.urlFor(s)
+}
+
+//AssetURL returns the URL to use for a file given the original file's name. When
+//Development is true, the original file's URL path is returned so that the file is
+//served as-is. Otherwise, the cache busting URL is returned. This allows templates to
+//use the same code in each environment without needing fallbacks for when a cache
+//busting file doesn't exist.
+//
+//Ex.: <script src="{{assetURL "script.min.js"}}"></script>
+func (c *Config) AssetURL(original string) string {
+	if c.Development {
+		s, found := c.findByOriginalName(original)
+		if !found {
+			return original
+		}
+
+		return c.publicURLPath(s.URLPath)
+	}
+
+	return c.originalOrCacheBustURL(original)
+}
+
+//AssetURL returns the URL to use for a file using the package level config.
+func AssetURL(original string) string {
+	return config.AssetURL(original)
+}
+
+//SpriteURL returns the URL to use for an icon in an SVG sprite given the original file's
+//name followed by the icon's fragment. The fragment is kept so that the URL refers to the
+//same icon in the cache busting copy of the sprite. See AssetURL().
+//
+//Ex.: <svg><use href="{{spriteURL "sprite.svg#icon-user"}}"></use></svg>
+func (c *Config) SpriteURL(originalWithFragment string) string {
+	i := strings.Index(originalWithFragment, "#")
+	if i < 0 {
+		return c.AssetURL(originalWithFragment)
+	}
+
+	return c.AssetURL(originalWithFragment[:i]) + originalWithFragment[i:]
+}
+
+//SpriteURL returns the URL to use for an icon in an SVG sprite using the package level
+//config.
+func SpriteURL(originalWithFragment string) string {
+	return config.SpriteURL(originalWithFragment)
+}
+
+//urlFor returns the cache busting URL for a static file, or the original file's URL path
+//if cache busting files have not been created.
+func (c *Config) urlFor(s StaticFile) string {
+	if s.cacheBustURLPath == "" {
+		return c.publicURLPath(s.URLPath)
+	}
+
+	return c.cacheBustURL(s)
+}
+
+//ScriptTag returns a <script> element for the cache busting copy of a file given the
+//original file's name. The element includes the integrity and crossorigin attributes
+//so the browser can verify the file's contents. If cache busting files have not been
+//created, the element uses the original file's URL path and no integrity attribute.
+func (c *Config) ScriptTag(original string) (t template.HTML, err error) {
+	s, found := c.findByOriginalName(original)
+	if !found {
+		err = &FileError{Path: original, Err: ErrNotFound}
+		return
+	}
+
+	t = template.HTML(`<script src="` + template.HTMLEscapeString(c.originalOrCacheBustURL(original)) + `"` + integrityAttrs(s) + `></script>`)
+	return
+}
+
+//ScriptTag returns a <script> element using the package level config.
+func ScriptTag(original string) (template.HTML, error) {
+	return config.ScriptTag(original)
+}
+
+//StyleTag returns a <link rel="stylesheet"> element for the cache busting copy of a file
+//given the original file's name. The element includes the integrity and crossorigin
+//attributes so the browser can verify the file's contents. If cache busting files have
+//not been created, the element uses the original file's URL path and no integrity
+//attribute.
+func (c *Config) StyleTag(original string) (t template.HTML, err error) {
+	s, found := c.findByOriginalName(original)
+	if !found {
+		err = &FileError{Path: original, Err: ErrNotFound}
+		return
+	}
+
+	t = template.HTML(`<link rel="stylesheet" href="` + template.HTMLEscapeString(c.originalOrCacheBustURL(original)) + `"` + integrityAttrs(s) + `>`)
+	return
+}
+
+//StyleTag returns a <link rel="stylesheet"> element using the package level config.
+func StyleTag(original string) (template.HTML, error) {
+	return config.StyleTag(original)
+}
+
+//integrityAttrs returns the integrity and crossorigin attributes for a static file, with
+//a leading space, for use when building an element. A blank string is returned if the
+//integrity has not been calculated yet since an incorrect integrity value would cause
+//the browser to refuse to use the file.
+func integrityAttrs(s StaticFile) string {
+	if s.integrity == "" || s.cacheBustURLPath == "" {
+		return ""
+	}
+
+	return ` integrity="` + s.integrity + `" crossorigin="anonymous"`
+}
+
+//TemplateData is the cache busting data commonly needed when rendering templates. Build this
+//once, after calling Create(), and add it to the data you pass to your templates rather
+//than building each map on each request.
+type TemplateData struct {
+	//FilenamePairs is the original to cache busting filename pairs. See GetFilenamePairs().
+	FilenamePairs map[string]string
+
+	//URLPairs is the original filename to cache busting URL pairs. See GetURLPairs().
+	URLPairs map[string]string
+
+	//IntegrityPairs is the original filename to subresource integrity value pairs. See
+	//GetIntegrityPairs().
+	IntegrityPairs map[string]string
+
+	//URLPrefix is the URL path static files are served under. This is the urlPrefix
+	//provided to NewFSConfig() or, if NewFSConfig() wasn't used, the longest directory
+	//path shared by the URL paths of all static files. The config's BasePath is included.
+	URLPrefix string
+}
+
+//TemplateData returns the cache busting data for use in templates.
+//
+//Ex.: data.CacheBust = c.TemplateData()
+//Then, in your template: <script src="{{index .CacheBust.URLPairs "script.min.js"}}"></script>
+func (c *Config) TemplateData() TemplateData {
+	return TemplateData{
+		FilenamePairs:  c.GetFilenamePairs(),
+		URLPairs:       c.GetURLPairs(),
+		IntegrityPairs: c.GetIntegrityPairs(),
+		URLPrefix:      c.templateURLPrefix(),
+	}
+}
+
+//GetTemplateData returns the cache busting data for templates for the package level config.
+func GetTemplateData() TemplateData {
+	return config.TemplateData()
+}
+
+//GetIntegrityPairs returns the original filename to subresource integrity value pairs. The
+//values are used in the integrity attribute of <script> and <link> elements.
+func (c *Config) GetIntegrityPairs() (pairs map[string]string) {
+	pairs = make(map[string]string)
+
+	for _, v := range c.servedFiles() {
+		if v.integrity == "" {
+			continue
+		}
+
+		pairs[filepath.Base(v.LocalPath)] = v.integrity
+	}
+
+	return
+}
+
+//GetIntegrityPairs returns the integrity pairs for the package level config.
+func GetIntegrityPairs() (pairs map[string]string) {
+	return config.GetIntegrityPairs()
+}
+
+//urlPrefix returns the URL path static files are served under. See TemplateData.URLPrefix.
+func (c *Config) urlPrefix() string {
+	if c.fsURLPrefix != "" {
+		return c.fsURLPrefix
+	}
+
+	files := c.servedFiles()
+	if len(files) == 0 {
+		return ""
+	}
+
+	prefix := path.Dir(files[0].URLPath)
+	for _, s := range files[1:] {
+		dir := path.Dir(s.URLPath)
+		for prefix != "/" && dir != prefix && !strings.HasPrefix(dir, prefix+"/") {
+			prefix = path.Dir(prefix)
+		}
+	}
+
+	return prefix
+}
+
+//densityRegex matches the resolution suffix of an image's name, without the extension,
+//i.e.: the "@2x" in logo@2x.png.
+var densityRegex = regexp.MustCompile(`^(.+)@([0-9]+(?:\.[0-9]+)?)x$`)
+
+//densityFile is an image at a specific pixel density.
+type densityFile struct {
+	density float64
+	file    StaticFile
+}
+
+//densities returns the resolution variants of an image, i.e.: logo.png, logo@2x.png, and
+//logo@3x.png, sorted by pixel density. The image itself is treated as 1x. Resolution
+//variants must be served from the same directory as the image.
+func (c *Config) densities(s StaticFile) (d []densityFile) {
+	ext := path.Ext(s.URLPath)
+	dir := path.Dir(s.URLPath)
+	base := strings.TrimSuffix(path.Base(s.URLPath), ext)
+
+	d = append(d, densityFile{density: 1, file: s})
+	for _, f := range c.servedFiles() {
+		if path.Dir(f.URLPath) != dir || path.Ext(f.URLPath) != ext {
+			continue
+		}
+
+		m := densityRegex.FindStringSubmatch(strings.TrimSuffix(path.Base(f.URLPath), ext))
+		if m == nil || m[1] != base {
+			continue
+		}
+
+		density, err := strconv.ParseFloat(m[2], 64)
+		if err != nil || density == 1 {
+			continue
+		}
+
+		d = append(d, densityFile{density: density, file: f})
+	}
+
+	sort.SliceStable(d, func(i, j int) bool {
+		return d[i].density < d[j].density
+	})
+
+	return
+}
+
+//SrcSet returns the value for the srcset attribute of an <img> element given the original
+//image's name. The value lists the cache busting URL of the image and each of its
+//resolution variants (i.e.: logo@2x.png) with their pixel density descriptor so retina
+//images are cache busted along with the image itself.
+//
+//Ex.: <img src="{{cacheBustURL "logo.png"}}" srcset="{{srcset "logo.png"}}">
+func (c *Config) SrcSet(original string) (set template.Srcset, err error) {
+	s, found := c.findByOriginalName(original)
+	if !found {
+		err = &FileError{Path: original, Err: ErrNotFound}
+		return
+	}
+
+	d := c.densities(s)
+	candidates := make([]string, 0, len(d))
+	for _, f := range d {
+		candidates = append(candidates, c.urlFor(f.file)+" "+strconv.FormatFloat(f.density, 'f', -1, 64)+"x")
+	}
+
+	set = template.Srcset(strings.Join(candidates, ", "))
+	return
+}
+
+//SrcSet returns the srcset attribute value using the package level config.
+func SrcSet(original string) (template.Srcset, error) {
+	return config.SrcSet(original)
+}
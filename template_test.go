@@ -0,0 +1,318 @@
+package cachebusting
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFuncMap(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	c := NewOnDiskConfig(css)
+	c.UseMemory = true
+	c.AssetHosts = []string{"https://cdn.example.com"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Before Create() is called the original URL path is used.
+	tmpl, err := template.New("").Funcs(c.FuncMap()).Parse(`{{cacheBustURL "styles.min.css"}}`)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if b.String() != css.URLPath {
+		t.Fatal("Original URL path not returned", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//After Create() the sharded cache busting URL is used.
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	b.Reset()
+	err = tmpl.Execute(&b, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !strings.HasPrefix(b.String(), "https://cdn.example.com/static/css/") || b.String() == "https://cdn.example.com"+css.URLPath {
+		t.Fatal("Cache busting URL not returned", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestAssetURL(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/script.min.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Cache busting URL is returned outside of development.
+	if u := c.AssetURL("script.min.js"); u != c.StaticFiles[0].cacheBustURLPath {
+		t.Fatal("Cache busting URL not returned", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Original URL path is returned in development.
+	c.Development = true
+	if u := c.AssetURL("script.min.js"); u != "/static/js/script.min.js" {
+		t.Fatal("Original URL path not returned", u)
+		return
+	}
+	if u := c.AssetURL("missing.js"); u != "missing.js" {
+		t.Fatal("Unknown file name should be returned as-is", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestSpriteURL(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/img/sprite.svg": {Data: []byte("<svg></svg>")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//The fragment is kept on the cache busting URL.
+	if u := c.SpriteURL("sprite.svg#icon-user"); u != c.StaticFiles[0].cacheBustURLPath+"#icon-user" {
+		t.Fatal("Fragment not kept", u)
+		return
+	}
+	if u := c.SpriteURL("sprite.svg"); u != c.StaticFiles[0].cacheBustURLPath {
+		t.Fatal("Cache busting URL not returned", u)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestScriptAndStyleTag(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	js := NewStaticFile(filepath.Join(dir, "_testdata", "static", "js", "script.min.js"), path.Join("/", "static", "js", "script.min.js"))
+	c := NewOnDiskConfig(css, js)
+	c.UseMemory = true
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Before Create() tags use the original URL without integrity.
+	tag, err := c.ScriptTag("script.min.js")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if string(tag) != `<script src="/static/js/script.min.js"></script>` {
+		t.Fatal("Script tag not built correctly", tag)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//After Create() tags use the cache busting URL and integrity.
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	tag, err = c.ScriptTag("script.min.js")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := `<script src="` + c.StaticFiles[1].cacheBustURLPath + `" integrity="` + c.StaticFiles[1].integrity + `" crossorigin="anonymous"></script>`
+	if string(tag) != expected {
+		t.Fatal("Script tag not built correctly", tag)
+		return
+	}
+	if !strings.Contains(string(tag), "sha256-") {
+		t.Fatal("Integrity not in expected format", tag)
+		return
+	}
+
+	tag, err = c.StyleTag("styles.min.css")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !strings.HasPrefix(string(tag), `<link rel="stylesheet" href="`+c.StaticFiles[0].cacheBustURLPath+`" integrity="sha256-`) {
+		t.Fatal("Style tag not built correctly", tag)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Unknown files return an error.
+	_, err = c.StyleTag("missing.css")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("ErrNotFound should have occured but didn't")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Tags can be used in templates.
+	tmpl, err := template.New("").Funcs(c.FuncMap()).Parse(`{{scriptTag "script.min.js"}}{{styleTag "styles.min.css"}}`)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !strings.Contains(b.String(), "<script src=") || !strings.Contains(b.String(), "<link rel=\"stylesheet\"") {
+		t.Fatal("Tags not rendered in template", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestTemplateData(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	css := NewStaticFile(filepath.Join(dir, "_testdata", "static", "css", "styles.min.css"), path.Join("/", "static", "css", "styles.min.css"))
+	js := NewStaticFile(filepath.Join(dir, "_testdata", "static", "js", "script.min.js"), path.Join("/", "static", "js", "script.min.js"))
+	c := NewOnDiskConfig(css, js)
+	c.UseMemory = true
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	d := c.TemplateData()
+	if len(d.FilenamePairs) != 2 || len(d.URLPairs) != 2 || len(d.IntegrityPairs) != 2 {
+		t.Fatal("Template data pairs not built correctly", d)
+		return
+	}
+	if d.URLPairs["script.min.js"] != c.StaticFiles[1].cacheBustURLPath {
+		t.Fatal("URL pair not set correctly", d.URLPairs)
+		return
+	}
+	if d.IntegrityPairs["script.min.js"] != c.StaticFiles[1].integrity {
+		t.Fatal("Integrity pair not set correctly", d.IntegrityPairs)
+		return
+	}
+	if d.URLPrefix != "/static" {
+		t.Fatal("URL prefix not determined correctly", d.URLPrefix)
+		return
+	}
+
+	tmpl, err := template.New("").Parse(`<script src="{{index .URLPairs "script.min.js"}}" integrity="{{index .IntegrityPairs "script.min.js"}}"></script>`)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, d)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !strings.Contains(b.String(), c.StaticFiles[1].cacheBustURLPath) {
+		t.Fatal("Template data not usable in template", b.String())
+		return
+	}
+}
+
+func TestSrcSet(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/img/logo.png":     {Data: []byte("1x")},
+		"static/img/logo@2x.png":  {Data: []byte("2x")},
+		"static/img/logo@3x.png":  {Data: []byte("3x")},
+		"static/img/other@2x.png": {Data: []byte("other")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	urls := make(map[string]string)
+	for _, s := range c.StaticFiles {
+		urls[s.URLPath] = s.cacheBustURLPath
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Resolution variants are grouped and sorted by density.
+	set, err := c.SrcSet("logo.png")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	expected := urls["/static/img/logo.png"] + " 1x, " + urls["/static/img/logo@2x.png"] + " 2x, " + urls["/static/img/logo@3x.png"] + " 3x"
+	if string(set) != expected {
+		t.Fatal("Srcset not built correctly", set)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Srcset is usable in templates.
+	tmpl, err := template.New("").Funcs(c.FuncMap()).Parse(`<img srcset="{{srcset "logo.png"}}">`)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if b.String() != `<img srcset="`+expected+`">` {
+		t.Fatal("Srcset not output correctly", b.String())
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
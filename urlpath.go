@@ -0,0 +1,65 @@
+package cachebusting
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//decodeURLPath returns a URL path with any percent-encoding decoded, i.e. /static/my%20file.js
+//becomes /static/my file.js. This matches the decoded path of a request, r.URL.Path, that
+//files are looked up by. The path is returned as-is if it isn't validly encoded, i.e. a file
+//named 100%.png.
+func decodeURLPath(p string) string {
+	decoded, err := url.PathUnescape(p)
+	if err != nil {
+		return p
+	}
+
+	return decoded
+}
+
+//escapeURLPath returns a URL path with each character that isn't allowed in a URL path
+//percent-encoded, i.e. spaces and non-ASCII characters. This is used for the URLs output
+//in templates, CSS files, and manifests. A path without such characters is unchanged.
+func escapeURLPath(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+//cleanURLPath returns a URL path that uses a "/" separator and starts with a "/". Join
+//adds the "/" in case the user forgot it, Clean removes any double "//" in cases where
+//user did add "/" and we just added another.
+func cleanURLPath(p string) string {
+	return path.Clean(path.Join("/", filepath.ToSlash(strings.TrimSpace(p))))
+}
+
+//normalizeURLPaths decodes the URL path of each static file so that files registered
+//with a percent-encoded URL path, i.e. /static/my%20file.js, match requests and the same
+//file isn't registered twice using differently encoded paths.
+//
+//Each path is only decoded once, not each time Create() is called, since decoding an
+//already decoded path would change a path with an encoded "%", i.e. a%2520b would become
+//"a b" rather than "a%20b". The decoded path, as cleaned by validate(), is remembered so
+//that a path is decoded again only if it is changed.
+func (c *Config) normalizeURLPaths() {
+	for k, s := range c.StaticFiles {
+		if s.decodedURLPath == "" || s.URLPath != s.decodedURLPath {
+			u := decodeURLPath(s.URLPath)
+			c.StaticFiles[k].URLPath = u
+			c.StaticFiles[k].decodedURLPath = cleanURLPath(u)
+		}
+
+		//copy the aliases so the caller's slice, or a prior lookup, isn't modified.
+		aliases := append([]string(nil), s.Aliases...)
+		if len(aliases) > 0 && !equalStrings(s.Aliases, s.decodedAliases) {
+			decoded := make([]string, len(aliases))
+			for i, a := range aliases {
+				aliases[i] = decodeURLPath(a)
+				decoded[i] = cleanURLPath(aliases[i])
+			}
+			c.StaticFiles[k].decodedAliases = decoded
+		}
+		c.StaticFiles[k].Aliases = aliases
+	}
+}
@@ -0,0 +1,117 @@
+package cachebusting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUnicodeAndSpacedNames(t *testing.T) {
+	names := []string{"my file.js", "a+b.js", "café.js", "日本.js"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Copies stored in memory are served when requested via the encoded URL.
+	fsys := fstest.MapFS{}
+	for _, n := range names {
+		fsys["static/js/"+n] = &fstest.MapFile{Data: []byte(n)}
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	h := c.Handler(HandlerOptions{CacheDays: 1})
+	for _, n := range names {
+		u := c.AssetURL(n)
+		if strings.ContainsAny(u, " é日") {
+			t.Fatal("URL should have been percent-encoded", u)
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != n || rec.Header().Get("X-Static-Served-From") != "memory" {
+			t.Fatal("File not served as expected", n, u, rec.Code, rec.Body.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files registered with an encoded URL path match requests and are served from disk.
+	dir := t.TempDir()
+	var files []StaticFile
+	for _, n := range names {
+		p := filepath.Join(dir, n)
+		err := os.WriteFile(p, []byte(n), 0644)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+
+		files = append(files, NewStaticFile(p, escapeURLPath("/static/js/"+n)))
+	}
+	c = NewOnDiskConfig(files...)
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	h = c.Handler(HandlerOptions{CacheDays: 1})
+	for _, n := range names {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.AssetURL(n), nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != n || rec.Header().Get("X-Static-Served-From") != "disk" {
+			t.Fatal("File not served as expected", n, rec.Code, rec.Body.String())
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
+
+func TestURLPathDecodedOnce(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/js/a%20b.js": {Data: []byte("console.log(1);")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[0].URLPath = "/static/js/a%2520b.js"
+	c.StaticFiles[0].Aliases = []string{"static/js/alias%2520b.js"}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Calling Create() again doesn't decode the URL path, or aliases, again.
+	for i := 0; i < 3; i++ {
+		err := c.Create()
+		if err != nil {
+			t.Fatal("Error occured but should not have", err)
+			return
+		}
+
+		s := c.StaticFiles[0]
+		if s.URLPath != "/static/js/a%20b.js" || s.Aliases[0] != "/static/js/alias%20b.js" {
+			t.Fatal("URL path decoded more than once", i, s.URLPath, s.Aliases)
+			return
+		}
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//A changed URL path is decoded.
+	c.StaticFiles[0].URLPath = "/static/js/new%2520b.js"
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	if c.StaticFiles[0].URLPath != "/static/js/new%20b.js" {
+		t.Fatal("Changed URL path not decoded", c.StaticFiles[0].URLPath)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,130 @@
+package cachebusting
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//Variant defines a file derived from a static file, for example a WebP or AVIF version of
+//a PNG image. The derived file is generated when Create() is called and is cache busted
+//like any other static file.
+type Variant struct {
+	//Ext is the extension of the derived file, including the leading ".". The derived
+	//file's local and URL paths are the static file's paths with the extension replaced.
+	//
+	//Ex.: .webp
+	Ext string
+
+	//Transform generates the derived file's contents from the static file's contents. Use
+	//this to call your image encoder of choice.
+	Transform func(data []byte) ([]byte, error)
+}
+
+//ErrInvalidVariant is returned when a variant is missing its extension or transform func.
+var ErrInvalidVariant = errors.New("cachebusting: variant must have an extension and transform func")
+
+//generateVariants generates the files derived from each static file's Variants and adds
+//them to StaticFiles. Files generated by a prior call to Create() are replaced, keeping
+//their history of cache busting URL paths.
+func (c *Config) generateVariants() error {
+	//remove files generated previously so they aren't duplicated.
+	prior := make(map[string]StaticFile)
+	files := make([]StaticFile, 0, len(c.StaticFiles))
+	for _, s := range c.StaticFiles {
+		if s.variantOf != "" {
+			prior[s.URLPath] = s
+			continue
+		}
+
+		files = append(files, s)
+	}
+	c.StaticFiles = files
+
+	for _, s := range files {
+		if len(s.Variants) == 0 {
+			continue
+		}
+
+		data, err := c.readOriginal(s)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range s.Variants {
+			if v.Ext == "" || v.Transform == nil {
+				return &FileError{Path: s.LocalPath, Err: ErrInvalidVariant}
+			}
+
+			ext := v.Ext
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+
+			generated, err := v.Transform(data)
+			if err != nil {
+				return fmt.Errorf("cachebusting: generating %s variant of %s: %w", ext, s.LocalPath, err)
+			}
+
+			urlPath := strings.TrimSuffix(s.URLPath, path.Ext(s.URLPath)) + ext
+			f := prior[urlPath]
+			f.LocalPath = strings.TrimSuffix(s.LocalPath, filepath.Ext(s.LocalPath)) + ext
+			f.URLPath = urlPath
+			f.Storage = s.Storage
+			f.variantOf = s.URLPath
+			f.sourceData = generated
+
+			c.StaticFiles = append(c.StaticFiles, f)
+		}
+	}
+
+	return nil
+}
+
+//variants returns the files generated from a static file's Variants.
+func (c *Config) variants(s StaticFile) (v []StaticFile) {
+	for _, f := range c.servedFiles() {
+		if f.variantOf != "" && f.variantOf == s.URLPath {
+			v = append(v, f)
+		}
+	}
+
+	return
+}
+
+//PictureTag returns a <picture> element for an image given the original file's name. A
+//<source> element is included for each of the image's variants, in the order the variants
+//were defined, followed by an <img> element for the image itself. This lets the browser
+//pick the first format it supports.
+//
+//Ex.: {{pictureTag "logo.png" "Company logo"}}
+func (c *Config) PictureTag(original, alt string) (t template.HTML, err error) {
+	s, found := c.findByOriginalName(original)
+	if !found {
+		err = &FileError{Path: original, Err: ErrNotFound}
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("<picture>")
+	for _, v := range c.variants(s) {
+		b.WriteString(`<source srcset="` + template.HTMLEscapeString(c.urlFor(v)) + `"`)
+		if typ := contentType(path.Ext(v.URLPath)); typ != "" {
+			b.WriteString(` type="` + template.HTMLEscapeString(typ) + `"`)
+		}
+		b.WriteString(">")
+	}
+	b.WriteString(`<img src="` + template.HTMLEscapeString(c.urlFor(s)) + `" alt="` + template.HTMLEscapeString(alt) + `">`)
+	b.WriteString("</picture>")
+
+	t = template.HTML(b.String())
+	return
+}
+
+//PictureTag returns a <picture> element using the package level config.
+func PictureTag(original, alt string) (template.HTML, error) {
+	return config.PictureTag(original, alt)
+}
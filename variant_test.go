@@ -0,0 +1,81 @@
+package cachebusting
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestVariants(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/img/logo.png": {Data: []byte("png")},
+	}
+
+	toWebP := func(data []byte) ([]byte, error) {
+		return append([]byte("webp:"), data...), nil
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Variants are generated, cache busted, and not duplicated on subsequent calls to
+	//Create().
+	c := NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[0].Variants = []Variant{{Ext: ".webp", Transform: toWebP}}
+	for i := 0; i < 2; i++ {
+		err := c.Create()
+		if err != nil {
+			t.Fatal("Error occured but should not have", err)
+			return
+		}
+	}
+	if len(c.StaticFiles) != 2 {
+		t.Fatal("Variant not generated as expected", len(c.StaticFiles))
+		return
+	}
+
+	webp := c.StaticFiles[1]
+	if webp.URLPath != "/static/img/logo.webp" || !strings.HasSuffix(webp.cacheBustURLPath, ".logo.webp") {
+		t.Fatal("Variant paths not built correctly", webp.URLPath, webp.cacheBustURLPath)
+		return
+	}
+	b, err := c.FindFileDataByCacheBustURLPath(webp.cacheBustURLPath)
+	if err != nil || !bytes.Equal(b, []byte("webp:png")) {
+		t.Fatal("Variant data not stored correctly", string(b), err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Picture element lists the variant before the image.
+	tag, err := c.PictureTag("logo.png", "Logo")
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+	expected := `<picture><source srcset="` + webp.cacheBustURLPath + `" type="image/webp"><img src="` + c.StaticFiles[0].cacheBustURLPath + `" alt="Logo"></picture>`
+	if string(tag) != expected {
+		t.Fatal("Picture element not built correctly", tag)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Invalid variant and transform errors are returned.
+	c = NewFSConfig(fsys, "static", "/static")
+	c.StaticFiles[0].Variants = []Variant{{Ext: ".webp"}}
+	err = c.Create()
+	if !errors.Is(err, ErrInvalidVariant) {
+		t.Fatal("ErrInvalidVariant should have occured but didn't", err)
+		return
+	}
+
+	errEncode := errors.New("encode failed")
+	c.StaticFiles[0].Variants = []Variant{{Ext: ".avif", Transform: func([]byte) ([]byte, error) { return nil, errEncode }}}
+	err = c.Create()
+	if !errors.Is(err, errEncode) {
+		t.Fatal("Transform error should have been returned", err)
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}
@@ -0,0 +1,126 @@
+package cachebusting
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//webAppManifest is the part of a web app manifest that references other static files.
+type webAppManifest struct {
+	Icons []struct {
+		Src string `json:"src"`
+	} `json:"icons"`
+	Screenshots []struct {
+		Src string `json:"src"`
+	} `json:"screenshots"`
+}
+
+//isWebAppManifest returns true if the static file is a web app manifest whose icon and
+//screenshot URLs should be rewritten.
+func isWebAppManifest(s StaticFile) bool {
+	return s.WebAppManifest || strings.ToLower(path.Ext(filepath.ToSlash(s.LocalPath))) == ".webmanifest"
+}
+
+//rewriteWebAppManifests replaces the URL of each icon and screenshot listed in each web
+//app manifest's data with the cache busting URL of the file. URLs are resolved relative to
+//the manifest's URL path, as a browser would. URLs that don't match a static file are left
+//as-is. Since this changes the manifest's hash, collisions are checked again and the URLs
+//rewritten again if a collision changed any URL. ErrURLsNotStable is returned if the URLs
+//are still changing after maxManifestPasses.
+//
+//fileData and hashLengths are in the same order as StaticFiles and are updated in place.
+func (c *Config) rewriteWebAppManifests(fileData [][]byte, hashLengths []uint) error {
+	byURLPath := make(map[string]int, len(c.StaticFiles))
+	original := make(map[int][]byte)
+	for k, s := range c.StaticFiles {
+		byURLPath[s.URLPath] = k
+		if isWebAppManifest(s) && !s.Vendor {
+			original[k] = fileData[k]
+		}
+	}
+	if len(original) == 0 {
+		return nil
+	}
+
+	previous := make(map[int][]byte, len(original))
+	for pass := 0; ; pass++ {
+		changed := false
+		for k, data := range original {
+			rewritten, err := c.rewriteWebAppManifest(c.StaticFiles, k, data, byURLPath, func(i int) string {
+				return c.plannedURL(c.StaticFiles[i], hashLengths[i])
+			})
+			if err != nil {
+				return err
+			}
+
+			if bytes.Equal(rewritten, previous[k]) {
+				continue
+			}
+			previous[k] = rewritten
+			changed = true
+
+			fileData[k] = rewritten
+			h := sha256.Sum256(rewritten)
+			c.StaticFiles[k].hash = c.nameHash(c.StaticFiles[k], h)
+			c.StaticFiles[k].integrity = "sha256-" + base64.StdEncoding.EncodeToString(h[:])
+		}
+		if !changed {
+			return nil
+		}
+		if pass == maxManifestPasses {
+			return ErrURLsNotStable
+		}
+
+		err := c.resolveCollisions(hashLengths)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//rewriteWebAppManifest returns the data of the web app manifest at index k in files with
+//the URL of each icon and screenshot that refers to a static file replaced with the URL
+//returned by urlOf for the file, see rewriteWebAppManifests(). byURLPath maps each file's
+//URL path to its index in files.
+func (c *Config) rewriteWebAppManifest(files []StaticFile, k int, data []byte, byURLPath map[string]int, urlOf func(i int) string) ([]byte, error) {
+	var m webAppManifest
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, &FileError{Path: files[k].LocalPath, Err: err}
+	}
+
+	var srcs []string
+	for _, i := range m.Icons {
+		srcs = append(srcs, i.Src)
+	}
+	for _, i := range m.Screenshots {
+		srcs = append(srcs, i.Src)
+	}
+
+	rewritten := data
+	for _, src := range srcs {
+		if src == "" || strings.Contains(src, "://") || strings.HasPrefix(src, "//") || strings.HasPrefix(src, "data:") {
+			continue
+		}
+
+		u := src
+		if !strings.HasPrefix(u, "/") {
+			u = path.Join(path.Dir(files[k].URLPath), u)
+		}
+		i, ok := byURLPath[c.trimBasePath(decodeURLPath(path.Clean(u)))]
+		if !ok || i == k {
+			continue
+		}
+
+		from, _ := json.Marshal(src)
+		to, _ := json.Marshal(urlOf(i))
+		rewritten = bytes.ReplaceAll(rewritten, from, to)
+	}
+
+	return rewritten, nil
+}
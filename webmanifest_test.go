@@ -0,0 +1,80 @@
+package cachebusting
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRewriteWebAppManifests(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/manifest.webmanifest": {Data: []byte(`{
+  "name": "App",
+  "icons": [
+    {"src": "/static/img/icon-192.png", "sizes": "192x192"},
+    {"src": "img/icon-512.png", "sizes": "512x512"},
+    {"src": "https://cdn.example.com/icon.png"}
+  ],
+  "screenshots": [{"src": "img/wide.png"}]
+}`)},
+		"static/img/icon-192.png": {Data: []byte("192")},
+		"static/img/icon-512.png": {Data: []byte("512")},
+		"static/img/wide.png":     {Data: []byte("wide")},
+	}
+	c := NewFSConfig(fsys, "static", "/static")
+	err := c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Icon and screenshot URLs are replaced with cache busting URLs, absolute and relative
+	//URLs alike, and the manifest is hashed after being rewritten.
+	m, _ := c.findByOriginalName("manifest.webmanifest")
+	data := string(m.fileData)
+	for _, name := range []string{"icon-192.png", "icon-512.png", "wide.png"} {
+		s, _ := c.findByOriginalName(name)
+		if !strings.Contains(data, `"src": "`+s.cacheBustURLPath+`"`) {
+			t.Fatal("URL not rewritten", name, data)
+			return
+		}
+	}
+	if !strings.Contains(data, `"src": "https://cdn.example.com/icon.png"`) {
+		t.Fatal("External URL should not have been rewritten", data)
+		return
+	}
+	if m.hash != upperHex(sha256.Sum256(m.fileData)) {
+		t.Fatal("Manifest hash does not match rewritten contents")
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+
+	//Test Start>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>>
+	//Files marked as a web app manifest are rewritten even without the .webmanifest
+	//extension.
+	fsys = fstest.MapFS{
+		"static/manifest.json": {Data: []byte(`{"icons": [{"src": "icon.png"}]}`)},
+		"static/icon.png":      {Data: []byte("icon")},
+	}
+	c = NewFSConfig(fsys, "static", "/static")
+	for k, s := range c.StaticFiles {
+		if s.URLPath == "/static/manifest.json" {
+			c.StaticFiles[k].WebAppManifest = true
+		}
+	}
+	err = c.Create()
+	if err != nil {
+		t.Fatal("Error occured but should not have", err)
+		return
+	}
+
+	m, _ = c.findByOriginalName("manifest.json")
+	icon, _ := c.findByOriginalName("icon.png")
+	if string(m.fileData) != `{"icons": [{"src": "`+icon.cacheBustURLPath+`"}]}` {
+		t.Fatal("Manifest not rewritten", string(m.fileData))
+		return
+	}
+	//Test End<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
+}